@@ -0,0 +1,306 @@
+// Package astedit provides small helpers for editing Go source files through
+// go/ast and go/printer, so that code changes survive reformatting of the
+// target file.
+package astedit
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// File is a parsed Go source file that can be modified and printed back
+type File struct {
+	Path     string
+	Original []byte
+	Fset     *token.FileSet
+	AST      *ast.File
+}
+
+// ParseFile reads and parses the Go file at path
+func ParseFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return ParseSource(path, src)
+}
+
+// ParseSource parses Go source that was read from path
+func ParseSource(path string, src []byte) (*File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &File{Path: path, Original: src, Fset: fset, AST: file}, nil
+}
+
+// Render prints the modified AST and formats it with gofmt rules
+func (f *File) Render() ([]byte, error) {
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, f.Fset, f.AST); err != nil {
+		return nil, fmt.Errorf("printing %s: %w", f.Path, err)
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", f.Path, err)
+	}
+	return formatted, nil
+}
+
+// ImportName returns the name the file uses to refer to importPath, or an
+// empty string if the package is not imported
+func (f *File) ImportName(importPath string) string {
+	for _, spec := range f.AST.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == importPath {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return PackageName(importPath)
+		}
+	}
+	return ""
+}
+
+// PackageName guesses the package name of an import path, skipping major
+// version suffixes such as /v5 and .v3
+func PackageName(importPath string) string {
+	base := path.Base(importPath)
+	if isVersion(base) {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.LastIndex(base, ".v"); i > 0 && isVersion(base[i+1:]) {
+		base = base[:i]
+	}
+	return base
+}
+
+// isVersion reports whether s looks like a major version element such as v2
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// EnsureImport adds importPath to the file if it is missing and returns the
+// name to use when referring to it. If name is already taken by another
+// import, alias is used instead. The second return value reports whether an
+// import was added.
+func (f *File) EnsureImport(importPath, alias string) (string, bool) {
+	if name := f.ImportName(importPath); name != "" {
+		return name, false
+	}
+
+	name := PackageName(importPath)
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)}}
+	if f.importNameTaken(name) {
+		name = alias
+		spec.Name = ast.NewIdent(alias)
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range f.AST.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+
+	if decl == nil {
+		decl = &ast.GenDecl{Tok: token.IMPORT}
+		f.AST.Decls = append([]ast.Decl{decl}, f.AST.Decls...)
+	} else if !decl.Lparen.IsValid() && len(decl.Specs) > 0 {
+		// Turn a single-line import into a block so the new spec fits
+		decl.Lparen = decl.Specs[0].Pos()
+		decl.Rparen = decl.End()
+	}
+
+	// Insert the spec in sorted order among the imports of the same kind
+	// (standard library or not). Positioning it next to its neighbours keeps
+	// the printer from moving comments or merging import groups.
+	std := isStdlib(importPath)
+	at, prev, last := -1, -1, -1
+	for i, s := range decl.Specs {
+		p, _ := strconv.Unquote(s.(*ast.ImportSpec).Path.Value)
+		if isStdlib(p) != std {
+			continue
+		}
+		if at < 0 && p > importPath {
+			at = i
+		}
+		if at < 0 {
+			prev = i
+		}
+		last = i
+	}
+
+	switch {
+	case at >= 0:
+		if prev >= 0 && prev == at-1 {
+			spec.Path.ValuePos = decl.Specs[at-1].End()
+		} else {
+			spec.Path.ValuePos = decl.Specs[at].Pos() - 1
+		}
+	case last >= 0:
+		at = last + 1
+		spec.Path.ValuePos = decl.Specs[last].End()
+	case std && len(decl.Specs) > 0:
+		at = 0
+		spec.Path.ValuePos = decl.Specs[0].Pos() - 1
+	default:
+		at = len(decl.Specs)
+		if decl.Rparen.IsValid() {
+			spec.Path.ValuePos = decl.Rparen - 1
+		}
+	}
+
+	if spec.Name != nil {
+		spec.Name.NamePos = spec.Path.ValuePos
+	}
+
+	specs := append([]ast.Spec{}, decl.Specs[:at]...)
+	specs = append(specs, spec)
+	decl.Specs = append(specs, decl.Specs[at:]...)
+	f.AST.Imports = append(f.AST.Imports, spec)
+	return name, true
+}
+
+// isStdlib reports whether importPath belongs to the standard library by
+// looking for the package in GOROOT. Module paths need not contain a dot
+// (module api is valid), so the shape of the path alone cannot tell.
+func isStdlib(importPath string) bool {
+	if build.Default.GOROOT == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(build.Default.GOROOT, "src", filepath.FromSlash(importPath)))
+	return err == nil && info.IsDir()
+}
+
+// importNameTaken reports whether name is already used by an import
+func (f *File) importNameTaken(name string) bool {
+	for _, spec := range f.AST.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name {
+			return true
+		}
+		if spec.Name == nil && PackageName(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseStmts parses Go statements written as source code. Every node is
+// placed at pos, which should be where the statements are inserted (usually
+// the closing brace of the target block) so that existing comments stay
+// ahead of the new code.
+func ParseStmts(src string, pos token.Pos) ([]ast.Stmt, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing statements: %w", err)
+	}
+	stmts := file.Decls[0].(*ast.FuncDecl).Body.List
+	for _, stmt := range stmts {
+		setPositions(stmt, pos)
+	}
+	return stmts, nil
+}
+
+// ParseExpr parses a Go expression and places every node at pos
+func ParseExpr(src string, pos token.Pos) (ast.Expr, error) {
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("parsing expression: %w", err)
+	}
+	setPositions(expr, pos)
+	return expr, nil
+}
+
+// setPositions moves nodes parsed from a separate file set to pos so the
+// printer lays them out relative to their new neighbours
+func setPositions(node ast.Node, pos token.Pos) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			x.NamePos = pos
+		case *ast.BasicLit:
+			x.ValuePos = pos
+		case *ast.CallExpr:
+			x.Lparen, x.Rparen = pos, pos
+		case *ast.AssignStmt:
+			x.TokPos = pos
+		case *ast.BinaryExpr:
+			x.OpPos = pos
+		case *ast.UnaryExpr:
+			x.OpPos = pos
+		case *ast.CompositeLit:
+			x.Lbrace, x.Rbrace = pos, pos
+		case *ast.FuncLit:
+			x.Type.Func = pos
+		case *ast.BlockStmt:
+			x.Lbrace, x.Rbrace = pos, pos
+		case *ast.FieldList:
+			x.Opening, x.Closing = pos, pos
+		case *ast.ReturnStmt:
+			x.Return = pos
+		case *ast.StarExpr:
+			x.Star = pos
+		}
+		return true
+	})
+}
+
+// LineEnd returns the position of the last character on the line holding
+// pos, which is a good anchor for statements inserted after it
+func (f *File) LineEnd(pos token.Pos) token.Pos {
+	tf := f.Fset.File(pos)
+	line := tf.Line(pos)
+	if line >= tf.LineCount() {
+		return token.Pos(tf.Base() + tf.Size())
+	}
+	return tf.LineStart(line+1) - 1
+}
+
+// InsertStmts inserts stmts into list at index i
+func InsertStmts(list []ast.Stmt, i int, stmts ...ast.Stmt) []ast.Stmt {
+	out := make([]ast.Stmt, 0, len(list)+len(stmts))
+	out = append(out, list[:i]...)
+	out = append(out, stmts...)
+	return append(out, list[i:]...)
+}
+
+// IsSelector reports whether expr is the selector pkg.name
+func IsSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg && sel.Sel.Name == name
+}
+
+// References reports whether node mentions an identifier called name
+func References(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
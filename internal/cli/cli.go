@@ -3,9 +3,12 @@ package cli
 import (
 	"fmt"
 	"github.com/shapestone/foundry/internal/cli/commands"
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/logging"
 	"io"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -22,6 +25,7 @@ type CLI struct {
 
 	// Command tree
 	rootCmd *cobra.Command
+	adapter *commands.CLIAdapter
 
 	// Version information
 	version VersionInfo
@@ -29,10 +33,26 @@ type CLI struct {
 
 // Config holds all configuration options
 type Config struct {
-	Verbose    bool   `yaml:"verbose"`
 	ConfigFile string `yaml:"config_file"`
 	Author     string `yaml:"author"`
 	GitHub     string `yaml:"github"`
+
+	// Verbose is the number of -v flags: 1 logs debug details and 2 traces
+	// them too. Quiet logs warnings and errors only.
+	Verbose int  `yaml:"verbose"`
+	Quiet   bool `yaml:"quiet"`
+	// LogFormat is text, or json for one object per log record, and NoEmoji
+	// prints messages without emoji
+	LogFormat string `yaml:"log_format"`
+	NoEmoji   bool   `yaml:"no_emoji"`
+
+	// DiffContext is the number of unchanged lines shown around changes
+	// in diffs, and NoColor prints diffs without colors
+	DiffContext int  `yaml:"diff_context"`
+	NoColor     bool `yaml:"no_color"`
+
+	// Output is text, or json or yaml for one document tools can read
+	Output string `yaml:"output"`
 }
 
 // VersionInfo holds version-related information
@@ -106,8 +126,10 @@ func (c *CLI) Execute(args []string) error {
 	// Set arguments for this execution (no global state)
 	c.rootCmd.SetArgs(args)
 
-	// Execute with isolated context
-	return c.rootCmd.Execute()
+	// Execute with isolated context; with --output json or yaml the
+	// command's report is printed once it returns
+	cmd, err := c.rootCmd.ExecuteC()
+	return c.adapter.FinishReport(cmd, err)
 }
 
 // buildCommands constructs the command tree with CLI context
@@ -132,15 +154,22 @@ func (c *CLI) buildCommands() {
 
 	// Add persistent flags (bound to config, not globals)
 	c.addPersistentFlags()
+	c.rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 
 	// Add subcommands using the new command builders
 	adapter := commands.NewCLIAdapter(c)
+	c.adapter = adapter
 	c.rootCmd.AddCommand(c.buildVersionCommand())
 	c.rootCmd.AddCommand(commands.BuildInitCommand(adapter))
 	c.rootCmd.AddCommand(commands.BuildNewCommand(adapter))
 	c.rootCmd.AddCommand(commands.BuildAddCommand(adapter))
 	c.rootCmd.AddCommand(commands.BuildLayoutCommand(adapter))
 	c.rootCmd.AddCommand(commands.BuildWireCommand(adapter))
+	c.rootCmd.AddCommand(commands.BuildOpenAPICommand(adapter))
+	c.rootCmd.AddCommand(commands.BuildUpgradeCommand(adapter))
+	c.rootCmd.AddCommand(commands.BuildDiffCommand(adapter))
+	c.rootCmd.AddCommand(commands.BuildUndoCommand(adapter))
+	c.rootCmd.AddCommand(commands.BuildHistoryCommand(adapter))
 }
 
 // addPersistentFlags adds flags that are available to all commands
@@ -148,10 +177,16 @@ func (c *CLI) addPersistentFlags() {
 	flags := c.rootCmd.PersistentFlags()
 
 	// Bind flags to config struct, not global variables
-	flags.BoolVarP(&c.config.Verbose, "verbose", "v", false, "Enable verbose output")
+	flags.CountVarP(&c.config.Verbose, "verbose", "v", "Log more details (-v for debug, -vv for trace)")
+	flags.BoolVarP(&c.config.Quiet, "quiet", "q", false, "Log warnings and errors only")
+	flags.StringVar(&c.config.LogFormat, "log-format", string(logging.FormatText), "Log format: text or json")
+	flags.BoolVar(&c.config.NoEmoji, "no-emoji", false, "Print messages without emoji")
 	flags.StringVarP(&c.config.ConfigFile, "config", "c", "", "Config file (default: $HOME/.foundry/config.yaml)")
 	flags.StringVar(&c.config.Author, "author", "", "Author name for generated code")
 	flags.StringVar(&c.config.GitHub, "github", "", "GitHub username")
+	flags.IntVar(&c.config.DiffContext, "diff-context", diff.DefaultContext, "Unchanged lines shown around changes in diffs")
+	flags.BoolVar(&c.config.NoColor, "no-color", false, "Print diffs and previews without colors")
+	flags.StringVar(&c.config.Output, "output", string(commands.OutputText), "Output format: text, json or yaml")
 }
 
 // initializeConfig is called before each command execution
@@ -163,8 +198,19 @@ func (c *CLI) initializeConfig(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Additional initialization can be added here
-	return nil
+	// Logging and machine-readable output start before the command runs
+	format, err := logging.ParseFormat(c.config.LogFormat)
+	if err != nil {
+		return err
+	}
+	c.adapter.StartLogging(logging.Options{
+		Level:  logging.Level(c.config.Verbose, c.config.Quiet),
+		Format: format,
+		Plain:  c.config.NoEmoji,
+	})
+	c.adapter.Logger().Debug("running command", "command", cmd.CommandPath(), "args", strings.Join(args, " "))
+
+	return c.adapter.StartReport(cmd, args)
 }
 
 // loadConfigFile loads configuration from a file
@@ -206,6 +252,11 @@ func (c *CLI) GetStderr() io.Writer {
 	return c.stderr
 }
 
+// GetStdin returns the stdin reader
+func (c *CLI) GetStdin() io.Reader {
+	return c.stdin
+}
+
 // GetVersionInfo returns the current version information
 func (c *CLI) GetVersionInfo() VersionInfo {
 	return c.version
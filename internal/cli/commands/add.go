@@ -2,7 +2,9 @@ package commands
 
 import (
 	"io"
+	"log/slog"
 
+	"github.com/shapestone/foundry/internal/conflict"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +25,6 @@ middleware, services, and more. The available component types depend on the proj
 	}
 
 	// Component configuration flags
-	cmd.Flags().StringP("output", "o", "", "Custom output directory")
 	cmd.Flags().BoolP("force", "f", false, "Overwrite existing files")
 	cmd.Flags().Bool("dry-run", false, "Show what would be generated without creating files")
 
@@ -33,14 +34,28 @@ middleware, services, and more. The available component types depend on the proj
 	cmd.AddCommand(BuildAddHandlerCommand(c))
 	cmd.AddCommand(BuildAddModelCommand(c))
 
+	// Every subcommand resolves existing files with the same policy,
+	// records what it generates in the project's lock file, journals its
+	// changes, lock file included, and can report them as a document
+	for _, sub := range cmd.Commands() {
+		addConflictFlag(sub)
+		recordGenerated(c, sub)
+		journaled(c, sub)
+		reportable(sub, reportFiles)
+	}
+
 	return cmd
 }
 
 // CLI interface - defines what we need from the main CLI
 type CLI interface {
 	GetStdout() io.Writer
+	GetOutput() io.Writer
 	GetStderr() io.Writer
+	GetStdin() io.Reader
 	GetConfig() *Config
+	Logger() *slog.Logger
+	ReportDecisions(resolver *conflict.Resolver)
 }
 
 type Config struct {
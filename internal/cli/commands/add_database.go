@@ -7,6 +7,7 @@ import (
 
 	"github.com/shapestone/foundry/internal/cli/generators"
 	"github.com/shapestone/foundry/internal/cli/templates"
+	"github.com/shapestone/foundry/internal/conflict"
 	"github.com/spf13/cobra"
 )
 
@@ -60,18 +61,18 @@ func runAddDatabase(c CLI, cmd *cobra.Command, args []string) error {
 	dbDir := filepath.Join("internal", "database")
 	dbPath := filepath.Join(dbDir, "database.go")
 
-	// Check if database config already exists
-	if _, err := os.Stat(dbPath); err == nil {
-		return fmt.Errorf("database configuration already exists at %s", dbPath)
+	conflicts, err := conflictResolver(cmd, c)
+	if err != nil {
+		return err
 	}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
+	// Check if database config already exists
+	if _, err := os.Stat(dbPath); err == nil && conflicts.Policy() == conflict.Fail {
+		return fmt.Errorf("database configuration already exists at %s (use --on-conflict to choose what happens)", dbPath)
 	}
 
 	// Create database generator
-	generator := generators.NewDatabaseGenerator(c.GetStdout(), c.GetStderr())
+	generator := generators.NewDatabaseGenerator(c.GetStdout(), c.Logger())
 
 	// Generate database files
 	options := generators.DatabaseOptions{
@@ -79,11 +80,13 @@ func runAddDatabase(c CLI, cmd *cobra.Command, args []string) error {
 		WithMigrations: withMigrations,
 		WithDocker:     withDocker,
 		OutputDir:      dbDir,
+		Conflicts:      conflicts,
 	}
 
 	if err := generator.Generate(options); err != nil {
 		return fmt.Errorf("failed to generate database files: %w", err)
 	}
+	printDecisions(c.GetStdout(), conflicts)
 
 	return nil
 }
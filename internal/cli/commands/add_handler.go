@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/shapestone/foundry/internal/cli/generators"
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +16,31 @@ func BuildAddHandlerCommand(c CLI) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "handler [name]",
 		Short: "Add a new REST handler",
-		Args:  cobra.ExactArgs(1),
+		Long: `Add a new REST handler.
+
+With --from-openapi, handlers are generated from an OpenAPI 3 document
+instead: one handler per tag, with request/response structs, parameter
+binding and validation, and routes for every operation. The document
+is read locally; remote references are not followed.
+
+Routes are registered with the router the project already uses: chi, gin,
+echo, gorilla/mux or the net/http ServeMux (Go 1.22 method patterns). The
+router is detected from internal/routes/routes.go, main.go or go.mod.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if spec, _ := cmd.Flags().GetString("from-openapi"); spec != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("a handler name cannot be combined with --from-openapi; use --tag to select operations")
+				}
+				return nil
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		Example: `  foundry add handler user
   foundry add handler product
   foundry add handler order --dry-run
-  foundry add handler user --auto-wire`,
+  foundry add handler user --auto-wire
+  foundry add handler --from-openapi api.yaml
+  foundry add handler --from-openapi api.yaml --tag users --auto-wire`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAddHandler(c, cmd, args)
 		},
@@ -26,32 +48,62 @@ func BuildAddHandlerCommand(c CLI) *cobra.Command {
 
 	cmd.Flags().Bool("dry-run", false, "Preview changes without applying them")
 	cmd.Flags().Bool("auto-wire", false, "Automatically wire the handler into routes")
+	cmd.Flags().String("from-openapi", "", "Generate handlers from an OpenAPI 3 document (YAML or JSON)")
+	cmd.Flags().String("tag", "", "Only generate the operations with this tag (requires --from-openapi)")
 
 	return cmd
 }
 
 // runAddHandler executes the add handler subcommand
 func runAddHandler(c CLI, cmd *cobra.Command, args []string) error {
-	name := args[0]
-
 	// Get flags
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	autoWire, _ := cmd.Flags().GetBool("auto-wire")
+	specFile, _ := cmd.Flags().GetString("from-openapi")
+	tag, _ := cmd.Flags().GetString("tag")
+
+	if tag != "" && specFile == "" {
+		return fmt.Errorf("--tag can only be used with --from-openapi")
+	}
 
 	// Check if we're in a Go project
 	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
 		return fmt.Errorf("go.mod not found. Please run this command from your project root")
 	}
 
+	handlersDir := filepath.Join("internal", "handlers")
+
+	conflicts, err := conflictResolver(cmd, c)
+	if err != nil {
+		return err
+	}
+
+	if specFile != "" {
+		generator := generators.NewHandlerGenerator(c.GetStdout(), c.Logger())
+		if err := generator.GenerateFromOpenAPI(generators.HandlerOptions{
+			AutoWire:    autoWire,
+			DryRun:      dryRun,
+			OutputDir:   handlersDir,
+			OpenAPIFile: specFile,
+			Tag:         tag,
+			Diff:        diffOptions(cmd, c.GetStdout()),
+			Conflicts:   conflicts,
+		}); err != nil {
+			return err
+		}
+		printDecisions(c.GetStdout(), conflicts)
+		return nil
+	}
+
+	name := args[0]
+
 	fmt.Fprintf(c.GetStdout(), "🔨 Adding handler: %s\n", name)
 
-	// Create handlers directory
-	handlersDir := filepath.Join("internal", "handlers")
 	handlerPath := filepath.Join(handlersDir, fmt.Sprintf("%s.go", name))
 
 	// Check if handler already exists
-	if _, err := os.Stat(handlerPath); err == nil && !dryRun {
-		return fmt.Errorf("handler %s already exists", handlerPath)
+	if _, err := os.Stat(handlerPath); err == nil && !dryRun && conflicts.Policy() == conflict.Fail {
+		return &conflict.ExistsError{Paths: []string{filepath.ToSlash(handlerPath)}}
 	}
 
 	if dryRun {
@@ -63,23 +115,29 @@ func runAddHandler(c CLI, cmd *cobra.Command, args []string) error {
 	}
 
 	// Create directory if it doesn't exist
+	if err := journal.TouchDir(handlersDir); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(handlersDir, 0755); err != nil {
 		return fmt.Errorf("failed to create handlers directory: %w", err)
 	}
 
 	// Create handler generator
-	generator := generators.NewHandlerGenerator(c.GetStdout(), c.GetStderr())
+	generator := generators.NewHandlerGenerator(c.GetStdout(), c.Logger())
 
 	// Generate handler files
 	options := generators.HandlerOptions{
 		Name:      name,
 		AutoWire:  autoWire,
 		OutputDir: handlersDir,
+		Diff:      diffOptions(cmd, c.GetStdout()),
+		Conflicts: conflicts,
 	}
 
 	if err := generator.Generate(options); err != nil {
 		return fmt.Errorf("failed to generate handler files: %w", err)
 	}
+	printDecisions(c.GetStdout(), conflicts)
 
 	return nil
 }
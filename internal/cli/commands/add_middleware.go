@@ -7,6 +7,8 @@ import (
 
 	"github.com/shapestone/foundry/internal/cli/generators"
 	"github.com/shapestone/foundry/internal/cli/templates"
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
 	"github.com/spf13/cobra"
 )
 
@@ -63,9 +65,14 @@ func runAddMiddleware(c CLI, cmd *cobra.Command, args []string) error {
 	middlewareDir := filepath.Join("internal", "middleware")
 	middlewarePath := filepath.Join(middlewareDir, fmt.Sprintf("%s.go", middlewareType))
 
+	conflicts, err := conflictResolver(cmd, c)
+	if err != nil {
+		return err
+	}
+
 	// Check if middleware already exists
-	if _, err := os.Stat(middlewarePath); err == nil && !dryRun {
-		return fmt.Errorf("middleware %s already exists", middlewarePath)
+	if _, err := os.Stat(middlewarePath); err == nil && !dryRun && conflicts.Policy() == conflict.Fail {
+		return &conflict.ExistsError{Paths: []string{filepath.ToSlash(middlewarePath)}}
 	}
 
 	if dryRun {
@@ -77,23 +84,29 @@ func runAddMiddleware(c CLI, cmd *cobra.Command, args []string) error {
 	}
 
 	// Create directory if it doesn't exist
+	if err := journal.TouchDir(middlewareDir); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(middlewareDir, 0755); err != nil {
 		return fmt.Errorf("failed to create middleware directory: %w", err)
 	}
 
 	// Create middleware generator
-	generator := generators.NewMiddlewareGenerator(c.GetStdout(), c.GetStderr())
+	generator := generators.NewMiddlewareGenerator(c.GetStdout(), c.Logger())
 
 	// Generate middleware files
 	options := generators.MiddlewareOptions{
 		Type:      middlewareType,
 		AutoWire:  autoWire,
 		OutputDir: middlewareDir,
+		Diff:      diffOptions(cmd, c.GetStdout()),
+		Conflicts: conflicts,
 	}
 
 	if err := generator.Generate(options); err != nil {
 		return fmt.Errorf("failed to generate middleware files: %w", err)
 	}
+	printDecisions(c.GetStdout(), conflicts)
 
 	return nil
 }
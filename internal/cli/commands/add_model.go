@@ -6,18 +6,28 @@ import (
 	"path/filepath"
 
 	"github.com/shapestone/foundry/internal/cli/generators"
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/parser"
 	"github.com/spf13/cobra"
 )
 
 // BuildAddModelCommand creates the add model subcommand
 func BuildAddModelCommand(c CLI) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "model [name]",
+		Use:   "model [name] [field:type[:required][:unique]...]",
 		Short: "Add a new data model",
-		Args:  cobra.ExactArgs(1),
+		Long: `Add a new data model to the current project.
+
+Fields are given as name:type pairs, optionally followed by modifiers:
+  required  the field must be set (adds a validate:"required" tag and a Validate check)
+  unique    the column is unique (adds ",unique" to the db tag)
+
+Types can be Go builtin types, time.Time, or slices, maps and pointers of them.`,
+		Args: cobra.MinimumNArgs(1),
 		Example: `  foundry add model user
-  foundry add model product
-  foundry add model order`,
+  foundry add model product name:string price:float64:required sku:string:unique tags:[]string
+  foundry add model order total:float64:required placed_at:time.Time`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAddModel(c, cmd, args)
 		},
@@ -30,6 +40,12 @@ func BuildAddModelCommand(c CLI) *cobra.Command {
 func runAddModel(c CLI, cmd *cobra.Command, args []string) error {
 	name := args[0]
 
+	// Parse field definitions before touching the file system
+	fields, err := parser.ParseFieldSpecs(args[1:])
+	if err != nil {
+		return err
+	}
+
 	// Check if we're in a Go project
 	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
 		return fmt.Errorf("go.mod not found. Please run this command from your project root")
@@ -41,28 +57,39 @@ func runAddModel(c CLI, cmd *cobra.Command, args []string) error {
 	modelsDir := filepath.Join("internal", "models")
 	modelPath := filepath.Join(modelsDir, fmt.Sprintf("%s.go", name))
 
+	conflicts, err := conflictResolver(cmd, c)
+	if err != nil {
+		return err
+	}
+
 	// Check if model already exists
-	if _, err := os.Stat(modelPath); err == nil {
-		return fmt.Errorf("model %s already exists", modelPath)
+	if _, err := os.Stat(modelPath); err == nil && conflicts.Policy() == conflict.Fail {
+		return &conflict.ExistsError{Paths: []string{filepath.ToSlash(modelPath)}}
 	}
 
 	// Create directory if it doesn't exist
+	if err := journal.TouchDir(modelsDir); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(modelsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create models directory: %w", err)
 	}
 
 	// Create model generator
-	generator := generators.NewModelGenerator(c.GetStdout(), c.GetStderr())
+	generator := generators.NewModelGenerator(c.GetStdout(), c.Logger())
 
 	// Generate model files
 	options := generators.ModelOptions{
 		Name:      name,
 		OutputDir: modelsDir,
+		Fields:    fields,
+		Conflicts: conflicts,
 	}
 
 	if err := generator.Generate(options); err != nil {
 		return fmt.Errorf("failed to generate model files: %w", err)
 	}
+	printDecisions(c.GetStdout(), conflicts)
 
 	return nil
 }
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// addAnswersFlags adds the flags that read and write answers files
+func addAnswersFlags(cmd *cobra.Command) {
+	cmd.Flags().String("answers", "", "Read the project settings from an answers file instead of asking")
+	cmd.Flags().String("save-answers", "", "Write the project settings to an answers file in the project")
+	cmd.Flags().Lookup("save-answers").NoOptDefVal = layout.AnswersFileName
+}
+
+// applyAnswers fills in the settings of the --answers file that were not
+// given on the command line and returns the layout it names
+func applyAnswers(cmd *cobra.Command, args []string, layoutName string, data *ProjectData) (string, error) {
+	path, _ := cmd.Flags().GetString("answers")
+	answers, err := layout.LoadAnswers(path)
+	if err != nil {
+		return "", err
+	}
+	saved := answers.ProjectData()
+
+	if !cmd.Flags().Changed("layout") {
+		layoutName = answers.Layout
+	}
+	if len(args) == 0 {
+		data.ProjectName = saved.ProjectName
+	}
+	setUnlessChanged(cmd, "module", &data.ModuleName, saved.ModuleName)
+	setUnlessChanged(cmd, "author", &data.Author, saved.Author)
+	setUnlessChanged(cmd, "license", &data.License, saved.License)
+	setUnlessChanged(cmd, "description", &data.Description, saved.Description)
+	setUnlessChanged(cmd, "github", &data.GitHubUsername, saved.GitHubUsername)
+	if saved.GoVersion != "" {
+		data.GoVersion = saved.GoVersion
+	}
+	if saved.Year != 0 {
+		data.Year = saved.Year
+	}
+
+	// Values given as flags win over the saved ones
+	data.CustomVariables = overlay(saved.CustomVariables, data.CustomVariables)
+	data.Options = overlay(saved.Options, data.Options)
+	data.Features = overlay(saved.Features, data.Features)
+
+	return layoutName, nil
+}
+
+// saveAnswers writes the settings the project was generated with, defaults
+// included, to the --save-answers file. Relative paths are resolved in the
+// project directory.
+func saveAnswers(cmd *cobra.Command, adapter *CLIAdapter, layoutName, projectPath string, data ProjectData) error {
+	path, _ := cmd.Flags().GetString("save-answers")
+	if path == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	resolved, err := manager.ResolveProjectData(context.Background(), layoutName, data.layoutData())
+	if err != nil {
+		return err
+	}
+
+	if err := layout.NewAnswers(layoutName, resolved).Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(adapter.GetStdout(), "✓ Saved answers to %s\n", path)
+	return nil
+}
+
+// setUnlessChanged sets *dst to a non-empty value unless the flag was given
+func setUnlessChanged(cmd *cobra.Command, name string, dst *string, value string) {
+	if value != "" && !cmd.Flags().Changed(name) {
+		*dst = value
+	}
+}
+
+// overlay returns the entries of base replaced by the entries of top
+func overlay[V any](base, top map[string]V) map[string]V {
+	merged := make(map[string]V, len(base)+len(top))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range top {
+		merged[key] = value
+	}
+	return merged
+}
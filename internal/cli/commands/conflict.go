@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/interactive"
+	"github.com/spf13/cobra"
+)
+
+// addConflictFlag adds the --on-conflict flag to a command
+func addConflictFlag(cmd *cobra.Command) {
+	cmd.Flags().String("on-conflict", string(conflict.Fail),
+		"What to do with generated files that already exist: fail, skip, overwrite, merge or prompt")
+}
+
+// conflictResolver returns the resolver for the --on-conflict policy, whose
+// decisions go in the report of the command. The prompt policy shows diffs
+// on stdout, even when messages are logged, and reads answers from stdin,
+// even when it is not a terminal; at the end of the input, files are
+// skipped.
+func conflictResolver(cmd *cobra.Command, c CLI) (*conflict.Resolver, error) {
+	stdout := c.GetOutput()
+	name, _ := cmd.Flags().GetString("on-conflict")
+	policy, err := conflict.ParsePolicy(name)
+	if err != nil {
+		return nil, err
+	}
+
+	prompter := interactive.NewConsolePrompterWithIO(c.GetStdin(), stdout)
+	prompter.SetDiffOptions(diffOptions(cmd, stdout))
+	prompter.SetInteractive(true)
+	resolver := conflict.NewResolver(policy, conflict.WithPrompter(prompter))
+	c.ReportDecisions(resolver)
+	return resolver, nil
+}
+
+// printDecisions reports what happened to the generated files that already
+// existed
+func printDecisions(stdout io.Writer, resolver *conflict.Resolver) {
+	var (
+		header    bool
+		conflicts int
+	)
+	for _, decision := range resolver.Decisions() {
+		var line string
+		switch decision.Action {
+		case conflict.Skipped, conflict.Overwritten, conflict.Merged:
+			mark := "~"
+			if decision.Action == conflict.Skipped {
+				mark = "="
+			}
+			line = fmt.Sprintf("  %s %s (%s)", mark, decision.Path, decision.Action)
+		case conflict.Conflicted:
+			detail := fmt.Sprintf("%d conflict(s)", decision.Conflicts)
+			if decision.Reason != "" {
+				detail += ", " + decision.Reason
+			}
+			line = fmt.Sprintf("  ! %s (%s)", decision.Path, detail)
+			conflicts++
+		default:
+			continue
+		}
+
+		if !header {
+			fmt.Fprintf(stdout, "⚖️  Existing files (--on-conflict=%s):\n", resolver.Policy())
+			header = true
+		}
+		fmt.Fprintln(stdout, line)
+	}
+
+	if conflicts > 0 {
+		fmt.Fprintf(stdout, "⚠️  Resolve the <<<<<<< markers in %d file(s)\n", conflicts)
+	}
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/interactive"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// BuildDiffCommand creates the diff command using the adapter pattern
+func BuildDiffCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [path...]",
+		Short: "Show how the project has drifted from its layout",
+		Long: `Diff renders the project's layout and components again in memory, with the
+settings recorded in .foundry/lock.yaml, and prints unified diffs from the
+generated output to the files in the project. Nothing is written.
+
+A summary lists the files that are untouched, modified, deleted from the
+project, or missing because the layout generates them but the project
+never got them. Paths limit the comparison to those files and directories.`,
+		Example: `  foundry diff
+  foundry diff internal/handlers
+  foundry diff --summary`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().Bool("summary", false, "Only print the summary")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runDiff executes the diff command
+func runDiff(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetOutput()
+	summaryOnly, _ := cmd.Flags().GetBool("summary")
+	options := diffOptions(cmd, stdout)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	files, lock, err := manager.ProjectDrift(context.Background(), cwd)
+	if err != nil {
+		return fmt.Errorf("diff failed: %w", err)
+	}
+
+	var (
+		counts   = make(map[layout.DriftStatus]int)
+		selected = []layout.DriftFile{}
+		changed  []layout.DriftFile
+	)
+	for _, file := range files {
+		if !selectedPath(file.Path, args) {
+			continue
+		}
+		selected = append(selected, file)
+		counts[file.Status]++
+		if file.Status != layout.DriftUntouched {
+			changed = append(changed, file)
+		}
+	}
+	if adapter.SetReportData(selected) {
+		return nil
+	}
+
+	if !summaryOnly {
+		for _, file := range changed {
+			printDrift(stdout, file, options)
+		}
+	}
+
+	fmt.Fprintf(stdout, "📋 Drift from the %s layout %s:\n", lock.Layout.Name, lock.Layout.Version)
+	for _, file := range changed {
+		mark := map[layout.DriftStatus]string{
+			layout.DriftModified: "~",
+			layout.DriftDeleted:  "-",
+			layout.DriftMissing:  "+",
+		}[file.Status]
+		fmt.Fprintf(stdout, "  %s %s (%s, from %s)\n", mark, file.Path, file.Status, file.Source)
+	}
+	fmt.Fprintf(stdout, "✓ %d untouched, %d modified, %d deleted, %d missing\n",
+		counts[layout.DriftUntouched], counts[layout.DriftModified],
+		counts[layout.DriftDeleted], counts[layout.DriftMissing])
+	return nil
+}
+
+// printDrift prints the unified diff from the generated output of a file to
+// the file in the project
+func printDrift(stdout io.Writer, file layout.DriftFile, options diff.Options) {
+	if file.Expected == nil {
+		fmt.Fprintf(stdout, "%s: %s; the generated output was not kept, so there is no diff\n\n", file.Path, file.Status)
+		return
+	}
+
+	change := diff.Change{Path: file.Path, Before: file.Expected, After: file.Actual}
+	if file.Status != layout.DriftModified {
+		change.After = nil
+	}
+	fmt.Fprint(stdout, options.Render(change))
+	fmt.Fprintln(stdout)
+}
+
+// diffOptions returns how diffs are rendered, from the --diff-context and
+// --no-color flags. Colors are only used when stdout is a terminal and
+// NO_COLOR is not set.
+func diffOptions(cmd *cobra.Command, stdout io.Writer) diff.Options {
+	options := diff.Options{Context: diff.DefaultContext, Color: interactive.ColorOutput(stdout)}
+	if context, err := cmd.Flags().GetInt("diff-context"); err == nil {
+		options.Context = context
+	}
+	if noColor, _ := cmd.Flags().GetBool("no-color"); noColor {
+		options.Color = false
+	}
+	return options
+}
+
+// selectedPath reports whether a project path is one of the given paths or
+// lies in one of them; no paths select everything
+func selectedPath(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = filepath.ToSlash(filepath.Clean(p))
+		if p == "." || path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec" // <-- ADD THIS IMPORT
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/shapestone/foundry/internal/conflict"
 	"github.com/shapestone/foundry/internal/layout"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +28,8 @@ type ProjectData struct {
 	GoVersion       string
 	Year            int
 	CustomVariables map[string]string
+	Options         map[string]string
+	Features        map[string]bool
 }
 
 // BuildInitCommand creates the init command using the adapter pattern
@@ -50,6 +54,21 @@ Use 'foundry new' if you want to create a new directory for your project.`,
   # Initialize with custom layout and module
   foundry init --layout=microservice --module=github.com/user/myproject
 
+  # Choose the router and logger of the standard layout
+  foundry init --router=gin --logger=zap
+
+  # Leave out the Docker files and preview the result
+  foundry init --without=docker --dry-run
+
+  # Set layout variables
+  foundry init --layout=microservice --var service_name=orders --var grpc_port=9191
+
+  # Answer questions instead of passing flags
+  foundry init --interactive
+
+  # Generate from recorded settings, e.g. in CI
+  foundry init --answers ../project.yaml
+
   # Compare with 'new' command (creates subdirectory):
   foundry new myproject    # Creates ./myproject/ directory`,
 		Args: cobra.MaximumNArgs(1),
@@ -65,16 +84,75 @@ Use 'foundry new' if you want to create a new directory for your project.`,
 	cmd.Flags().StringP("license", "", "MIT", "Project license")
 	cmd.Flags().StringP("description", "d", "", "Project description")
 	cmd.Flags().StringP("github", "g", "", "GitHub username")
-	cmd.Flags().BoolP("force", "f", false, "Overwrite existing files")
+	cmd.Flags().BoolP("force", "f", false, "Overwrite existing files (same as --on-conflict=overwrite)")
 	cmd.Flags().Bool("no-git", false, "Skip git initialization")
+	addStackFlags(cmd)
+	addWizardFlags(cmd)
+	addAnswersFlags(cmd)
+	addConflictFlag(cmd)
+	cmd.MarkFlagsMutuallyExclusive("force", "on-conflict")
+	journaled(adapter, cmd)
+	reportable(cmd, reportFiles)
 
 	return cmd
 }
 
+// addStackFlags adds the flags that choose the stack of a new project
+func addStackFlags(cmd *cobra.Command) {
+	cmd.Flags().String("router", "", "HTTP router: chi, gin, echo, gorilla or stdlib (default: the layout's)")
+	cmd.Flags().String("logger", "", "Logger: slog, zap or zerolog (default: the layout's)")
+	cmd.Flags().StringSlice("with", nil, "Layout features to turn on, e.g. --with=grpc")
+	cmd.Flags().StringSlice("without", nil, "Layout features to turn off, e.g. --without=docker")
+	cmd.Flags().Bool("dry-run", false, "Show the files that would be created without creating them")
+	cmd.Flags().StringArray("var", nil, "Set a layout variable, e.g. --var grpc_port=9191 (repeatable)")
+}
+
+// layoutVariables returns the layout variables set with --var name=value
+func layoutVariables(cmd *cobra.Command) (map[string]string, error) {
+	variables := make(map[string]string)
+	values, _ := cmd.Flags().GetStringArray("var")
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var '%s' (use name=value)", value)
+		}
+		variables[name] = v
+	}
+	return variables, nil
+}
+
+// stackFeatures returns the layout features turned on or off with the
+// --with and --without flags
+func stackFeatures(cmd *cobra.Command) map[string]bool {
+	features := make(map[string]bool)
+	with, _ := cmd.Flags().GetStringSlice("with")
+	for _, name := range with {
+		features[name] = true
+	}
+	without, _ := cmd.Flags().GetStringSlice("without")
+	for _, name := range without {
+		features[name] = false
+	}
+	return features
+}
+
+// stackOptions returns the layout options chosen with the stack flags
+func stackOptions(cmd *cobra.Command) map[string]string {
+	options := make(map[string]string)
+	for _, name := range []string{"router", "logger"} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			options[name] = value
+		}
+	}
+	return options
+}
+
 // runInit executes the init command
 func runInit(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 	stdout := adapter.GetStdout()
 	stderr := adapter.GetStderr()
+	logger := adapter.Logger()
 
 	// Get current working directory for clear messaging
 	cwd, err := os.Getwd()
@@ -97,31 +175,43 @@ func runInit(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 	}
 
 	// Get flags
-	moduleName, _ := cmd.Flags().GetString("module")
 	layoutName, _ := cmd.Flags().GetString("layout")
-	author, _ := cmd.Flags().GetString("author")
-	license, _ := cmd.Flags().GetString("license")
-	description, _ := cmd.Flags().GetString("description")
-	githubUsername, _ := cmd.Flags().GetString("github")
 	force, _ := cmd.Flags().GetBool("force")
 	noGit, _ := cmd.Flags().GetBool("no-git")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	projectData, err := projectDataFromFlags(cmd, projectName)
+	if err != nil {
+		return err
+	}
 
-	// Default module name
-	if moduleName == "" {
-		if githubUsername != "" {
-			moduleName = fmt.Sprintf("github.com/%s/%s", githubUsername, projectName)
-		} else {
-			moduleName = projectName
+	// Take the settings the flags leave open from an answers file, or ask
+	if answers, _ := cmd.Flags().GetString("answers"); answers != "" {
+		if layoutName, err = applyAnswers(cmd, args, layoutName, &projectData); err != nil {
+			return err
+		}
+	} else if prompter, wizard := wizardPrompter(cmd, adapter); wizard {
+		if layoutName, err = runWizard(cmd, args, adapter, prompter, layoutName, &projectData); err != nil {
+			return err
 		}
 	}
-
-	// Default description
-	if description == "" {
-		description = fmt.Sprintf("A Go project created with Foundry using the %s layout", layoutName)
+	projectData.applyDefaults(layoutName)
+	projectName = projectData.ProjectName
+
+	// Existing files are resolved with the --on-conflict policy; --force
+	// overwrites them
+	var conflicts *conflict.Resolver
+	switch {
+	case cmd.Flags().Changed("on-conflict"):
+		if conflicts, err = conflictResolver(cmd, adapter); err != nil {
+			return err
+		}
+	case force:
+		conflicts = conflict.NewResolver(conflict.Overwrite)
+		adapter.ReportDecisions(conflicts)
 	}
 
 	// Enhanced safety check with clearer messaging
-	if !force {
+	if conflicts == nil && !dryRun {
 		empty, err := isDirEmpty(".")
 		if err != nil {
 			return fmt.Errorf("failed to check directory: %w", err)
@@ -132,45 +222,41 @@ func runInit(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 			fmt.Fprintln(stderr, "")
 			fmt.Fprintln(stderr, "Options:")
 			fmt.Fprintln(stderr, "  1. Use --force to initialize anyway (may overwrite files)")
-			fmt.Fprintln(stderr, "  2. Use 'foundry new myproject' to create a new directory instead")
-			fmt.Fprintln(stderr, "  3. Create an empty directory first: mkdir myproject && cd myproject")
+			fmt.Fprintln(stderr, "  2. Use --on-conflict=skip|merge|prompt to decide per existing file")
+			fmt.Fprintln(stderr, "  3. Use 'foundry new myproject' to create a new directory instead")
+			fmt.Fprintln(stderr, "  4. Create an empty directory first: mkdir myproject && cd myproject")
 			return fmt.Errorf("directory not empty (use --force to overwrite or 'foundry new' to create subdirectory)")
 		}
 	}
 
-	// Create project data
-	projectData := ProjectData{
-		ProjectName:     projectName,
-		ModuleName:      moduleName,
-		Author:          author,
-		License:         license,
-		Description:     description,
-		GitHubUsername:  githubUsername,
-		GoVersion:       "1.21",
-		Year:            time.Now().Year(),
-		CustomVariables: make(map[string]string),
-	}
-
 	// Clear initialization message showing current location
 	fmt.Fprintf(stdout, "🚀 Initializing project '%s' in current directory...\n", projectName)
 	fmt.Fprintf(stdout, "📁 Location: %s\n", cwd)
 	fmt.Fprintf(stdout, "🏗️  Layout: %s\n", layoutName)
 	fmt.Fprintln(stdout, "")
 
-	if err := generateProject(layoutName, ".", projectData, stdout, stderr); err != nil {
+	if dryRun {
+		return previewProject(layoutName, projectData, stdout, logger)
+	}
+
+	if err := generateProject(layoutName, ".", projectData, conflicts, stdout, logger); err != nil {
 		return fmt.Errorf("failed to generate project: %w", err)
 	}
 
+	if err := saveAnswers(cmd, adapter, layoutName, ".", projectData); err != nil {
+		return err
+	}
+
 	// Initialize git repository
 	if !noGit && !isGitRepo(".") {
 		if err := initGitRepo("."); err != nil {
-			fmt.Fprintf(stderr, "Warning: failed to initialize git repository: %v\n", err)
+			logger.Warn("failed to initialize git repository", "error", err)
 		} else {
 			fmt.Fprintln(stdout, "✓ Initialized git repository")
 
 			// Create initial commit
 			if err := createInitialCommit(); err != nil {
-				fmt.Fprintf(stderr, "Warning: failed to create initial commit: %v\n", err)
+				logger.Warn("failed to create initial commit", "error", err)
 			}
 		}
 	}
@@ -195,23 +281,82 @@ func runInit(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 	return nil
 }
 
-// generateProject creates the project structure using the layout system
-func generateProject(layoutName, targetDir string, data ProjectData, stdout, stderr io.Writer) error {
-	// Get layout manager config path
+// generateProject creates the project structure using the layout system.
+// Files that already exist are resolved with conflicts, or overwritten
+// when it is nil.
+func generateProject(layoutName, targetDir string, data ProjectData, conflicts *conflict.Resolver, stdout io.Writer, logger *slog.Logger) error {
+	manager, err := newLayoutManager(logger)
+	if err != nil {
+		return err
+	}
+
+	var opts []layout.ProjectOption
+	if conflicts != nil {
+		opts = append(opts, layout.WithProjectConflicts(conflicts))
+	}
+
+	// Generate project using layout system
+	ctx := context.Background()
+	err = manager.GenerateProject(ctx, layoutName, targetDir, data.layoutData(), opts...)
+	if err != nil {
+		return fmt.Errorf("layout generation failed: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "✓ Generated project using '%s' layout\n", layoutName)
+	if conflicts != nil {
+		printDecisions(stdout, conflicts)
+	}
+	return nil
+}
+
+// previewProject shows what generateProject would create, including the
+// entries the layout skips and the conditions that skip them
+func previewProject(layoutName string, data ProjectData, stdout io.Writer, logger *slog.Logger) error {
+	manager, err := newLayoutManager(logger)
+	if err != nil {
+		return err
+	}
+
+	plan, err := manager.PlanProject(context.Background(), layoutName, data.layoutData())
+	if err != nil {
+		return fmt.Errorf("layout generation failed: %w", err)
+	}
+
+	fmt.Fprintln(stdout, "🔍 Dry run - no files will be created")
+	for _, dir := range plan.Directories {
+		fmt.Fprintf(stdout, "  + %s/\n", dir)
+	}
+	for _, file := range plan.Files {
+		fmt.Fprintf(stdout, "  + %s\n", file)
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintln(stdout, "")
+		fmt.Fprintln(stdout, "⏭️  Skipped:")
+		for _, entry := range plan.Skipped {
+			fmt.Fprintf(stdout, "  - %s (when: %s)\n", entry.Path, entry.When)
+		}
+	}
+	return nil
+}
+
+// newLayoutManager creates the layout manager from the user's configuration
+func newLayoutManager(logger *slog.Logger) (*layout.Manager, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
 
-	// Create layout manager
-	manager, err := layout.NewManager(configPath)
+	manager, err := layout.NewManager(configPath, layout.WithLogger(logger))
 	if err != nil {
-		return fmt.Errorf("failed to create layout manager: %w", err)
+		return nil, fmt.Errorf("failed to create layout manager: %w", err)
 	}
+	return manager, nil
+}
 
-	// Convert ProjectData to layout.ProjectData
-	layoutData := layout.ProjectData{
+// layoutData converts the project data to the layout package's form
+func (data ProjectData) layoutData() layout.ProjectData {
+	return layout.ProjectData{
 		ProjectName:     data.ProjectName,
 		ModuleName:      data.ModuleName,
 		Author:          data.Author,
@@ -221,20 +366,57 @@ func generateProject(layoutName, targetDir string, data ProjectData, stdout, std
 		Year:            data.Year,
 		GoVersion:       data.GoVersion,
 		CustomVariables: data.CustomVariables,
+		Options:         data.Options,
+		Features:        data.Features,
 	}
+}
 
-	// Generate project using layout system
-	ctx := context.Background()
-	err = manager.GenerateProject(ctx, layoutName, targetDir, layoutData)
+// Helper functions
+
+// projectDataFromFlags returns the project settings given as flags
+func projectDataFromFlags(cmd *cobra.Command, projectName string) (ProjectData, error) {
+	moduleName, _ := cmd.Flags().GetString("module")
+	author, _ := cmd.Flags().GetString("author")
+	license, _ := cmd.Flags().GetString("license")
+	description, _ := cmd.Flags().GetString("description")
+	githubUsername, _ := cmd.Flags().GetString("github")
+	variables, err := layoutVariables(cmd)
 	if err != nil {
-		return fmt.Errorf("layout generation failed: %w", err)
+		return ProjectData{}, err
 	}
 
-	fmt.Fprintf(stdout, "✓ Generated project using '%s' layout\n", layoutName)
-	return nil
+	return ProjectData{
+		ProjectName:     projectName,
+		ModuleName:      moduleName,
+		Author:          author,
+		License:         license,
+		Description:     description,
+		GitHubUsername:  githubUsername,
+		GoVersion:       "1.22",
+		Year:            time.Now().Year(),
+		CustomVariables: variables,
+		Options:         stackOptions(cmd),
+		Features:        stackFeatures(cmd),
+	}, nil
 }
 
-// Helper functions
+// applyDefaults fills in the module and description when they were not given
+func (data *ProjectData) applyDefaults(layoutName string) {
+	if data.ModuleName == "" {
+		data.ModuleName = defaultModuleName(data.ProjectName, data.GitHubUsername)
+	}
+	if data.Description == "" {
+		data.Description = fmt.Sprintf("A Go project created with Foundry using the %s layout", layoutName)
+	}
+}
+
+// defaultModuleName returns the module path of a project without --module
+func defaultModuleName(projectName, githubUsername string) string {
+	if githubUsername != "" {
+		return fmt.Sprintf("github.com/%s/%s", githubUsername, projectName)
+	}
+	return projectName
+}
 
 // isValidProjectName validates a project name
 func isValidProjectName(name string) bool {
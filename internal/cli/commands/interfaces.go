@@ -2,19 +2,34 @@ package commands
 
 import (
 	"io"
+	"log/slog"
+
+	"github.com/shapestone/foundry/internal/logging"
 )
 
 // CLIAdapter adapts the main CLI struct to the commands interface
 type CLIAdapter struct {
 	stdout io.Writer
+	output io.Writer
 	stderr io.Writer
+	stdin  io.Reader
 	config *Config
+	logger *slog.Logger
+
+	// out and errOut are the writers of the CLI, before the options of
+	// the running command apply
+	out    io.Writer
+	errOut io.Writer
+
+	// run is set while a command reports with --output json or yaml
+	run *reportRun
 }
 
 // NewCLIAdapter creates a new CLI adapter
 func NewCLIAdapter(cli interface {
 	GetStdout() io.Writer
 	GetStderr() io.Writer
+	GetStdin() io.Reader
 	GetConfig() interface{}
 }) *CLIAdapter {
 	// Convert config interface{} to our Config type
@@ -45,21 +60,60 @@ func NewCLIAdapter(cli interface {
 
 	return &CLIAdapter{
 		stdout: cli.GetStdout(),
+		output: cli.GetStdout(),
 		stderr: cli.GetStderr(),
+		stdin:  cli.GetStdin(),
 		config: config,
+		logger: logging.New(cli.GetStderr(), logging.Options{Level: slog.LevelInfo}),
+		out:    cli.GetStdout(),
+		errOut: cli.GetStderr(),
+	}
+}
+
+// StartLogging sets up the logger of the command about to run. With Plain,
+// its messages are printed without emoji too. With JSON logs or a level
+// above info, such as for --quiet, its messages are logged at info instead
+// of printed on stdout.
+func (a *CLIAdapter) StartLogging(options logging.Options) {
+	a.stdout, a.output, a.stderr = a.out, a.out, a.errOut
+	if options.Plain {
+		a.stdout = logging.PlainWriter(a.stdout)
+		a.output = a.stdout
+		a.stderr = logging.PlainWriter(a.stderr)
+	}
+	a.logger = logging.New(a.stderr, options)
+	if options.Format == logging.FormatJSON || options.Level > slog.LevelInfo {
+		a.stdout = logging.MessageWriter(a.logger)
 	}
 }
 
-// GetStdout returns stdout writer
+// GetStdout returns the writer for the messages of a command, which is
+// stdout unless they are logged
 func (a *CLIAdapter) GetStdout() io.Writer {
 	return a.stdout
 }
 
+// GetOutput returns the writer for what a command lists or exports and the
+// questions it asks. It is stdout even when messages are logged.
+func (a *CLIAdapter) GetOutput() io.Writer {
+	return a.output
+}
+
 // GetStderr returns stderr writer
 func (a *CLIAdapter) GetStderr() io.Writer {
 	return a.stderr
 }
 
+// GetStdin returns stdin reader
+func (a *CLIAdapter) GetStdin() io.Reader {
+	return a.stdin
+}
+
+// Logger returns the logger for diagnostics, which writes to stderr
+func (a *CLIAdapter) Logger() *slog.Logger {
+	return a.logger
+}
+
 // GetConfig returns configuration
 func (a *CLIAdapter) GetConfig() *Config {
 	return a.config
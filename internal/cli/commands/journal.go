@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// journaled wraps the run of a command that writes to the project in the
+// current directory, so the files it touches are recorded in the journal
+// and can be undone
+func journaled(c CLI, cmd *cobra.Command) {
+	run := cmd.RunE
+	if run == nil {
+		return
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return run(cmd, args)
+		}
+		op, err := journal.Begin(".")
+		if err != nil {
+			return err
+		}
+
+		// A failed run can leave changes too, such as wiring applied
+		// before a later step failed
+		err = run(cmd, args)
+		op.End()
+		if rerr := recordOperation(c.Logger(), op, commandLine(cmd, args)); rerr != nil && err == nil {
+			return rerr
+		}
+		return err
+	}
+}
+
+// recordOperation appends the changes the operation made to the journal of
+// a Foundry project
+func recordOperation(logger *slog.Logger, op *journal.Operation, command string) error {
+	if _, err := project.LoadConfig(op.Root()); err != nil {
+		return nil
+	}
+	entry, err := journal.Record(op, command)
+	if err != nil {
+		return fmt.Errorf("the changes were made but cannot be undone: %w", err)
+	}
+	if entry != nil {
+		logger.Debug("recorded operation", "id", entry.ID, "files", entry.Summary())
+	}
+	return nil
+}
+
+// displayFlags only change how a command prints, so they are left out of
+// the command lines in the journal and reports
+var displayFlags = map[string]bool{
+	"verbose":      true,
+	"quiet":        true,
+	"log-format":   true,
+	"no-emoji":     true,
+	"no-color":     true,
+	"diff-context": true,
+	"output":       true,
+}
+
+// commandLine returns the command as it was run, with the flags that were set
+func commandLine(cmd *cobra.Command, args []string) string {
+	parts := append([]string{cmd.CommandPath()}, args...)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if displayFlags[flag.Name] && flag == cmd.Root().PersistentFlags().Lookup(flag.Name) {
+			return
+		}
+		if flag.Value.Type() == "bool" && flag.Value.String() == "true" {
+			parts = append(parts, "--"+flag.Name)
+			return
+		}
+		parts = append(parts, fmt.Sprintf("--%s=%s", flag.Name, flag.Value))
+	})
+	return strings.Join(parts, " ")
+}
+
+// BuildUndoCommand creates the undo command using the adapter pattern
+func BuildUndoCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the last operation that changed the project",
+		Long: `Undo reverts the last Foundry command that wrote files in the project, using
+the journal in .foundry/journal: files it created are removed, and files it
+changed or deleted get their old content back.
+
+Files changed since the command ran are not overwritten unless --force is
+given. Run it again to undo earlier operations; 'foundry history' lists them.`,
+		Example: `  foundry undo
+  foundry undo --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUndo(cmd, adapter)
+		},
+	}
+
+	cmd.Flags().BoolP("force", "f", false, "Undo even if files changed since the operation")
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runUndo executes the undo command
+func runUndo(cmd *cobra.Command, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	force, _ := cmd.Flags().GetBool("force")
+
+	entry, err := journal.Undo(".", force)
+	if errors.Is(err, journal.ErrEmpty) {
+		return fmt.Errorf("nothing to undo: %w in %s", err, journal.Dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to undo: %w", err)
+	}
+	if adapter.SetReportData(entry) {
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "↩️  Undid #%d: %s\n", entry.ID, entry.Command)
+	for _, file := range entry.Files {
+		switch file.Action {
+		case journal.Created:
+			fmt.Fprintf(stdout, "  - %s (removed)\n", file.Path)
+		default:
+			fmt.Fprintf(stdout, "  ~ %s (restored)\n", file.Path)
+		}
+	}
+	return nil
+}
+
+// BuildHistoryCommand creates the history command using the adapter pattern
+func BuildHistoryCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the operations recorded in the project's journal",
+		Long: `History lists the Foundry commands that wrote files in the project, newest
+first, with the files each one changed. 'foundry undo' reverts the first one.`,
+		Example: `  foundry history
+  foundry history --files`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cmd, adapter)
+		},
+	}
+
+	cmd.Flags().Bool("files", false, "List the files of every operation")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runHistory executes the history command
+func runHistory(cmd *cobra.Command, adapter *CLIAdapter) error {
+	stdout := adapter.GetOutput()
+	files, _ := cmd.Flags().GetBool("files")
+
+	entries, err := journal.List(".")
+	if err != nil {
+		return err
+	}
+	if adapter.SetReportData(entries) {
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(stdout, "No operations recorded in %s\n", journal.Dir)
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "📜 Operations, newest first:")
+	for _, entry := range entries {
+		fmt.Fprintf(stdout, "  #%-3d %s  %s  (%s)\n",
+			entry.ID, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Command, entry.Summary())
+		if files {
+			for _, file := range entry.Files {
+				fmt.Fprintf(stdout, "         %-8s %s\n", file.Action, file.Path)
+			}
+		}
+	}
+	return nil
+}
@@ -19,45 +19,45 @@ type LayoutSource = layout.LayoutSource
 
 // Layout represents a complete layout
 type Layout struct {
-	Name     string
-	Version  string
-	Source   LayoutSource
-	Manifest LayoutManifest
+	Name     string         `json:"name" yaml:"name"`
+	Version  string         `json:"version" yaml:"version"`
+	Source   LayoutSource   `json:"source" yaml:"source"`
+	Manifest LayoutManifest `json:"manifest" yaml:"manifest"`
 }
 
 // LayoutManifest represents layout metadata
 type LayoutManifest struct {
-	Description  string
-	Author       string
-	Features     []string
-	Dependencies []string
-	Components   map[string]LayoutComponent
-	Variables    []LayoutVariable
-	Structure    LayoutStructure
+	Description  string                     `json:"description" yaml:"description"`
+	Author       string                     `json:"author,omitempty" yaml:"author,omitempty"`
+	Features     []string                   `json:"features,omitempty" yaml:"features,omitempty"`
+	Dependencies []string                   `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Components   map[string]LayoutComponent `json:"components,omitempty" yaml:"components,omitempty"`
+	Variables    []LayoutVariable           `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Structure    LayoutStructure            `json:"structure" yaml:"structure"`
 }
 
 // LayoutComponent represents a layout component
 type LayoutComponent struct {
-	TargetDir string
+	TargetDir string `json:"target_dir" yaml:"target_dir"`
 }
 
 // LayoutVariable represents a configurable variable
 type LayoutVariable struct {
-	Name        string
-	Description string
-	Default     string
-	Required    bool
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+	Required    bool   `json:"required" yaml:"required"`
 }
 
 // LayoutStructure represents directory structure
 type LayoutStructure struct {
-	Directories []LayoutDirectory
+	Directories []LayoutDirectory `json:"directories" yaml:"directories"`
 }
 
 // LayoutDirectory represents a directory in the structure
 type LayoutDirectory struct {
-	Path        string
-	Description string
+	Path        string `json:"path" yaml:"path"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 }
 
 // BuildLayoutCommand creates the layout command using the adapter pattern
@@ -74,6 +74,13 @@ func BuildLayoutCommand(adapter *CLIAdapter) *cobra.Command {
 	cmd.AddCommand(buildLayoutUpdateCommand(adapter))
 	cmd.AddCommand(buildLayoutRemoveCommand(adapter))
 	cmd.AddCommand(buildLayoutInfoCommand(adapter))
+	cmd.AddCommand(buildLayoutSearchCommand(adapter))
+	cmd.AddCommand(buildLayoutValidateCommand(adapter))
+	cmd.AddCommand(buildLayoutTestCommand(adapter))
+	cmd.AddCommand(buildLayoutInitCommand(adapter))
+	cmd.AddCommand(buildLayoutExtractCommand(adapter))
+	cmd.AddCommand(buildLayoutPackCommand(adapter))
+	cmd.AddCommand(buildLayoutPublishCommand(adapter))
 
 	return cmd
 }
@@ -90,6 +97,7 @@ func buildLayoutListCommand(adapter *CLIAdapter) *cobra.Command {
 			return runLayoutList(cmd, args, adapter)
 		},
 	}
+	reportable(cmd, reportData)
 
 	cmd.Flags().BoolP("remote", "r", false, "Show only remote layouts")
 	cmd.Flags().BoolP("local", "l", false, "Show only local layouts")
@@ -101,11 +109,19 @@ func buildLayoutListCommand(adapter *CLIAdapter) *cobra.Command {
 // buildLayoutAddCommand creates the layout add command
 func buildLayoutAddCommand(adapter *CLIAdapter) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "add [URL or GitHub repo]",
+		Use:   "add [name or URL or GitHub repo]",
 		Short: "Add a remote layout",
-		Long: `Add a remote layout from a URL or GitHub repository.
-	
+		Long: `Add a remote layout by its name in the configured registries, or from a URL
+or GitHub repository.
+
+A name is looked up in the registry indexes fetched by foundry layout update,
+which are updated first if none of them lists it. name@1.x adds the newest
+version 1 of the layout. The archive is downloaded and checked against the
+checksum in the index.
+
 Examples:
+  foundry layout add company-std
+  foundry layout add company-std@1.x --name std
   foundry layout add https://templates.foundry.dev/layouts/microservice
   foundry layout add github.com/user/foundry-hexagonal-layout`,
 		Args: cobra.ExactArgs(1),
@@ -116,6 +132,7 @@ Examples:
 
 	cmd.Flags().StringP("name", "n", "", "Custom name for the layout")
 	cmd.Flags().StringP("ref", "r", "", "Git reference (branch, tag, or commit)")
+	reportable(cmd, reportData)
 
 	return cmd
 }
@@ -125,12 +142,17 @@ func buildLayoutUpdateCommand(adapter *CLIAdapter) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update [name]",
 		Short: "Update layout registry",
-		Long:  `Update the layout registry by refreshing remote sources. If a layout name is provided, only that layout will be updated.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Update the layout registry by refreshing remote sources: the index of each
+registry in ~/.foundry/layouts.yaml is fetched into the cache, where foundry
+layout search and foundry layout add find it. Registries are directories,
+file:// URLs or HTTP servers. If a layout name is provided, only that layout
+will be updated.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runLayoutUpdate(cmd, args, adapter)
 		},
 	}
+	reportable(cmd, reportData)
 
 	return cmd
 }
@@ -146,6 +168,7 @@ func buildLayoutRemoveCommand(adapter *CLIAdapter) *cobra.Command {
 			return runLayoutRemove(cmd, args, adapter)
 		},
 	}
+	reportable(cmd, reportData)
 
 	return cmd
 }
@@ -161,6 +184,7 @@ func buildLayoutInfoCommand(adapter *CLIAdapter) *cobra.Command {
 			return runLayoutInfo(cmd, args, adapter)
 		},
 	}
+	reportable(cmd, reportData)
 
 	return cmd
 }
@@ -169,7 +193,7 @@ func buildLayoutInfoCommand(adapter *CLIAdapter) *cobra.Command {
 
 // runLayoutList executes the layout list command
 func runLayoutList(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
-	stdout := adapter.GetStdout()
+	stdout := adapter.GetOutput()
 
 	// Get flags
 	showRemote, _ := cmd.Flags().GetBool("remote")
@@ -183,24 +207,30 @@ func runLayoutList(cmd *cobra.Command, args []string, adapter *CLIAdapter) error
 	}
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
 
-	manager, err := layout.NewManager(configPath)
+	manager, err := layout.NewManager(configPath, layout.WithLogger(adapter.Logger()))
 	if err != nil {
 		// If layout manager fails, fall back to placeholder for now
-		fmt.Fprintf(adapter.GetStderr(), "Warning: layout manager unavailable, using basic layouts: %v\n", err)
-		layouts := getAvailableLayouts()
-		return displayLayouts(stdout, layouts, showRemote, showLocal, showInstalled)
+		adapter.Logger().Warn("layout manager unavailable, using basic layouts", "error", err)
+		layouts := filterLayouts(getAvailableLayouts(), showRemote, showLocal, showInstalled)
+		if adapter.SetReportData(layouts) {
+			return nil
+		}
+		return displayLayouts(stdout, layouts)
 	}
 
 	// Get layouts from manager (includes embedded + registry layouts)
-	layouts := manager.ListLayouts()
+	layouts := filterLayouts(manager.ListLayouts(), showRemote, showLocal, showInstalled)
+	if adapter.SetReportData(layouts) {
+		return nil
+	}
 
-	return displayLayouts(stdout, layouts, showRemote, showLocal, showInstalled)
+	return displayLayouts(stdout, layouts)
 }
 
-// displayLayouts handles the filtering and display logic
-func displayLayouts(stdout io.Writer, layouts []LayoutListEntry, showRemote, showLocal, showInstalled bool) error {
+// filterLayouts applies the list filters and sorts the layouts by name
+func filterLayouts(layouts []LayoutListEntry, showRemote, showLocal, showInstalled bool) []LayoutListEntry {
 	// Filter layouts based on flags
-	var filtered []LayoutListEntry
+	filtered := []LayoutListEntry{}
 	for _, l := range layouts {
 		// Apply filters
 		if showRemote && l.Source.Type == "local" {
@@ -219,7 +249,11 @@ func displayLayouts(stdout io.Writer, layouts []LayoutListEntry, showRemote, sho
 	sort.Slice(filtered, func(i, j int) bool {
 		return filtered[i].Name < filtered[j].Name
 	})
+	return filtered
+}
 
+// displayLayouts prints the layouts as a table
+func displayLayouts(stdout io.Writer, filtered []LayoutListEntry) error {
 	// Display layouts
 	if len(filtered) == 0 {
 		fmt.Fprintln(stdout, "No layouts found.")
@@ -241,8 +275,8 @@ func displayLayouts(stdout io.Writer, layouts []LayoutListEntry, showRemote, sho
 
 		// Truncate description if too long
 		desc := l.Description
-		if len(desc) > 50 {
-			desc = desc[:47] + "..."
+		if runes := []rune(desc); len(runes) > 50 {
+			desc = string(runes[:47]) + "..."
 		}
 
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.Name, l.Version, source, desc)
@@ -258,6 +292,11 @@ func runLayoutAdd(cmd *cobra.Command, args []string, adapter *CLIAdapter) error
 	customName, _ := cmd.Flags().GetString("name")
 	ref, _ := cmd.Flags().GetString("ref")
 
+	// Names without a path are looked up in the registries
+	if !strings.Contains(url, "/") {
+		return runLayoutAddFromRegistry(adapter, url, customName)
+	}
+
 	// Determine layout name for error message
 	name := customName
 	if name == "" {
@@ -301,10 +340,7 @@ func runLayoutUpdate(cmd *cobra.Command, args []string, adapter *CLIAdapter) err
 		return fmt.Errorf("updating individual layouts not yet implemented")
 	}
 
-	// Update all remote registries - also not implemented
-	fmt.Fprintf(stderr, "❌ Layout registry updates are not yet implemented\n")
-	fmt.Fprintf(stderr, "This feature will refresh remote layout sources when available\n")
-	return fmt.Errorf("layout registry updates not implemented")
+	return runLayoutRefresh(adapter)
 }
 
 // runLayoutRemove executes the layout remove command
@@ -319,7 +355,7 @@ func runLayoutRemove(cmd *cobra.Command, args []string, adapter *CLIAdapter) err
 
 // runLayoutInfo executes the layout info command
 func runLayoutInfo(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
-	stdout := adapter.GetStdout()
+	stdout := adapter.GetOutput()
 	name := args[0]
 
 	// Get layout info (placeholder implementation)
@@ -327,6 +363,9 @@ func runLayoutInfo(cmd *cobra.Command, args []string, adapter *CLIAdapter) error
 	if layout == nil {
 		return fmt.Errorf("layout '%s' not found", name)
 	}
+	if adapter.SetReportData(layout) {
+		return nil
+	}
 
 	// Display layout information
 	fmt.Fprintf(stdout, "Layout: %s\n", layout.Name)
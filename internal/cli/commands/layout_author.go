@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// buildLayoutInitCommand creates the layout init command
+func buildLayoutInitCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init <name>",
+		Short: "Create a skeleton for a new layout",
+		Long: `Init creates the directory of a new layout with a manifest, a few project
+templates, a handler component and a snapshot test case to start from.
+
+The layout is written to a directory named after it, or to --dir. Register it
+with foundry layout add once it is ready to use.`,
+		Example: `  foundry layout init company-std
+  foundry layout init company-std --dir ./layouts/company-std`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutInit(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().String("dir", "", "Directory to create the layout in (default: ./<name>)")
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runLayoutInit executes the layout init command
+func runLayoutInit(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	name := args[0]
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		dir = name
+	}
+
+	files, err := layout.ScaffoldLayout(dir, name)
+	if err != nil {
+		return fmt.Errorf("failed to create layout: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "✅ Created layout %s in %s\n", name, dir)
+	for _, file := range files {
+		fmt.Fprintf(stdout, "  + %s\n", file)
+	}
+	fmt.Fprintln(stdout, "\n📝 Next steps:")
+	fmt.Fprintf(stdout, "  1. Edit %s/%s and the templates\n", dir, layout.ManifestFileName)
+	fmt.Fprintf(stdout, "  2. foundry layout validate %s\n", dir)
+	fmt.Fprintf(stdout, "  3. foundry layout test %s --update\n", dir)
+	return nil
+}
+
+// buildLayoutExtractCommand creates the layout extract command
+func buildLayoutExtractCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "extract <project-dir>",
+		Short: "Make a layout from an existing project",
+		Long: `Extract turns an existing Go project into a layout. Every text file of the
+project becomes a template, with the module path, the project name (the last
+element of the module path) and the name of the binary under cmd/ replaced by
+template variables. The module path is only replaced in import paths and the
+module directive of go.mod. Lock files such as go.sum are left out.
+Directories of handlers, models, middleware, services and repositories become
+components, each with the first Go file in it as its template.
+
+Other names are replaced wherever they stand as words of their own, except as
+the first element of an absolute path such as /api/v1, so review the
+templates: a short project name such as "api" may be replaced in places it
+should not. The layout gets a snapshot test case with the settings of the
+project; foundry layout test --update records the project it generates.`,
+		Example: `  foundry layout extract ./existing-service --name company-std
+  foundry layout extract . --name company-std --dir ../company-std`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutExtract(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name of the layout (required)")
+	cmd.Flags().String("dir", "", "Directory to create the layout in (default: ./<name>)")
+	_ = cmd.MarkFlagRequired("name")
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runLayoutExtract executes the layout extract command
+func runLayoutExtract(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	name, _ := cmd.Flags().GetString("name")
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		dir = name
+	}
+
+	extraction, err := layout.ExtractLayout(args[0], dir, name)
+	if err != nil {
+		return fmt.Errorf("failed to extract layout: %w", err)
+	}
+	adapter.SetReportData(extraction)
+
+	fmt.Fprintf(stdout, "📦 Extracted layout %s from %s\n", name, args[0])
+	fmt.Fprintf(stdout, "  module %s → {{.ModuleName}} (import paths)\n", extraction.ModulePath)
+	fmt.Fprintf(stdout, "  project name %s → {{.ProjectName}}\n", extraction.ProjectName)
+	if extraction.BinaryName != "" && extraction.BinaryName != extraction.ProjectName {
+		fmt.Fprintf(stdout, "  binary %s → {{.CustomVariables.binary_name}}\n", extraction.BinaryName)
+	}
+	kinds := make([]string, 0, len(extraction.Components))
+	for kind := range extraction.Components {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(stdout, "  component %s ← %s\n", kind, extraction.Components[kind])
+	}
+
+	fmt.Fprintf(stdout, "✅ Created layout %s in %s (%d files)\n", name, dir, len(extraction.Files))
+	fmt.Fprintln(stdout, "\n📝 Next steps:")
+	fmt.Fprintf(stdout, "  1. Review the templates in %s\n", dir)
+	fmt.Fprintf(stdout, "  2. foundry layout validate %s\n", dir)
+	fmt.Fprintf(stdout, "  3. foundry layout test %s --update\n", dir)
+	return nil
+}
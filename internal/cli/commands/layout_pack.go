@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// buildLayoutPackCommand creates the layout pack command
+func buildLayoutPackCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pack <dir>",
+		Short: "Pack a layout into an archive",
+		Long: `Pack writes the manifest, templates, README and LICENSE of a layout to
+<name>-<version>.tar.gz, with its SHA-256 in a .sha256 file next to it, and
+prints the entry for a registry index. The same layout always packs to the
+same archive, so the checksum identifies its contents.
+
+Layouts with validation errors are not packed; see foundry layout validate.`,
+		Example: `  foundry layout pack ./company-std
+  foundry layout pack ./company-std --dir dist`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutPack(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().String("dir", ".", "Directory to write the archive to")
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runLayoutPack executes the layout pack command
+func runLayoutPack(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	outDir, _ := cmd.Flags().GetString("dir")
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	pkg, err := manager.PackLayout(context.Background(), args[0], outDir)
+	if err != nil {
+		return fmt.Errorf("failed to pack layout: %w", err)
+	}
+	adapter.SetReportData(pkg)
+
+	fmt.Fprintf(stdout, "📦 Packed %s %s\n", pkg.Entry.Name, pkg.Entry.Version)
+	fmt.Fprintf(stdout, "  archive:  %s (%d bytes)\n", pkg.Path, pkg.Entry.Size)
+	fmt.Fprintf(stdout, "  sha256:   %s\n", pkg.Entry.Checksum)
+	fmt.Fprintf(stdout, "  checksum: %s\n", pkg.ChecksumPath)
+	return nil
+}
+
+// buildLayoutPublishCommand creates the layout publish command
+func buildLayoutPublishCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish [dir]",
+		Short: "Publish a layout to a registry",
+		Long: `Publish packs a layout (the current directory by default) into a registry
+and adds it to the registry's index.json. The registry is a directory, such as
+one on a shared drive, or a file:// URL; a registry served over HTTP is
+published to through the directory it serves.
+
+Archives are stored as <name>/<name>-<version>.tar.gz with their checksums.
+Publishing the same contents again changes nothing; a version that was
+published with other contents is only replaced with --force.`,
+		Example: `  foundry layout publish ./company-std --registry /mnt/shared/foundry-registry
+  foundry layout publish --registry file:///srv/foundry/registry`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutPublish(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().String("registry", "", "Registry directory or file:// URL (required)")
+	cmd.Flags().Bool("force", false, "Replace a published version with other contents")
+	_ = cmd.MarkFlagRequired("registry")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runLayoutPublish executes the layout publish command
+func runLayoutPublish(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	registry, _ := cmd.Flags().GetString("registry")
+	force, _ := cmd.Flags().GetBool("force")
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	entry, published, err := manager.PublishLayout(context.Background(), dir, registry, force)
+	if err != nil {
+		return fmt.Errorf("failed to publish layout: %w", err)
+	}
+	if adapter.SetReportData(entry) {
+		return nil
+	}
+
+	if !published {
+		fmt.Fprintf(stdout, "✓ %s %s is already published to %s\n", entry.Name, entry.Version, registry)
+		return nil
+	}
+	fmt.Fprintf(stdout, "🚀 Published %s %s to %s\n", entry.Name, entry.Version, registry)
+	fmt.Fprintf(stdout, "  archive: %s\n", entry.Archive)
+	fmt.Fprintf(stdout, "  sha256:  %s\n", entry.Checksum)
+	return nil
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// buildLayoutSearchCommand creates the layout search command
+func buildLayoutSearchCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search [term]",
+		Short: "Search the layout registries",
+		Long: `Search looks for layouts whose name, description, tags or features contain
+the term in the indexes of the registries configured in ~/.foundry/layouts.yaml.
+It searches the indexes fetched last by foundry layout update, so it works
+without a network; --tag keeps the layouts with all of the tags given.
+
+Only the newest version of each layout is shown. Add a layout by its name
+with foundry layout add.`,
+		Example: `  foundry layout search api
+  foundry layout search --tag grpc
+  foundry layout search service --tag grpc --tag postgres`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutSearch(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().StringSlice("tag", nil, "Only show layouts with this tag (repeatable)")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runLayoutSearch executes the layout search command
+func runLayoutSearch(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetOutput()
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	term := ""
+	if len(args) > 0 {
+		term = args[0]
+	}
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	results, err := manager.SearchLayouts(term, tags)
+	if err != nil {
+		return fmt.Errorf("failed to search layouts: %w", err)
+	}
+	if adapter.SetReportData(results) {
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(stdout, "No layouts found. Run foundry layout update to fetch the registry indexes.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tREGISTRY\tTAGS\tDESCRIPTION")
+	fmt.Fprintln(w, "----\t-------\t--------\t----\t-----------")
+	for _, result := range results {
+		desc := result.Description
+		if runes := []rune(desc); len(runes) > 50 {
+			desc = string(runes[:47]) + "..."
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Name, result.Version, result.Registry, strings.Join(result.Tags, ","), desc)
+	}
+	return w.Flush()
+}
+
+// runLayoutAddFromRegistry adds a layout by its name in the registries
+func runLayoutAddFromRegistry(adapter *CLIAdapter, ref, name string) error {
+	stdout := adapter.GetStdout()
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	result, err := manager.AddRegistryLayout(context.Background(), ref, name)
+	if err != nil {
+		return fmt.Errorf("failed to add layout: %w", err)
+	}
+	if adapter.SetReportData(result) {
+		return nil
+	}
+
+	if name == "" {
+		name = result.Name
+	}
+	fmt.Fprintf(stdout, "✅ Added layout %s %s from registry %s\n", name, result.Version, result.Registry)
+	fmt.Fprintf(stdout, "  sha256: %s\n", result.Checksum)
+	fmt.Fprintf(stdout, "\n📝 Use it with: foundry new myproject --layout %s\n", name)
+	return nil
+}
+
+// runLayoutRefresh fetches the indexes of the registries for layout update
+func runLayoutRefresh(adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	statuses := manager.RefreshLayouts(context.Background())
+
+	failed := 0
+	for _, status := range statuses {
+		if status.Error != "" {
+			failed++
+			adapter.Logger().Warn("failed to update registry", "registry", status.Name, "error", status.Error)
+		}
+	}
+	allFailed := len(statuses) > 0 && failed == len(statuses)
+	if !adapter.SetReportData(statuses) && !allFailed {
+		fmt.Fprintln(stdout, "🔄 Updated layout registries")
+		for _, status := range statuses {
+			if status.Error == "" {
+				fmt.Fprintf(stdout, "  ✓ %s: %d layout version(s)\n", status.Name, status.Layouts)
+			}
+		}
+	}
+
+	if allFailed {
+		return fmt.Errorf("none of the %d registries could be read", len(statuses))
+	}
+	return nil
+}
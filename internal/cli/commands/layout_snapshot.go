@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// buildLayoutTestCommand creates the layout test command
+func buildLayoutTestCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <dir>",
+		Short: "Run the snapshot tests of a layout",
+		Long: `Test renders a layout with the answers of each of its test cases and
+compares the output with the golden project of the case. Cases live in the
+testdata directory of the layout:
+
+  testdata/<case>/foundry.answers.yaml   the settings to generate with
+  testdata/<case>/golden/                the project they should generate
+
+An answers file can be written with foundry new --save-answers; set year in it
+so the golden project does not change every January. Generated Go files must
+parse. With --update the golden projects are rewritten from the output, for
+changes to the layout that are intended, unless the output is not valid Go.`,
+		Example: `  foundry layout test ./my-layout
+  foundry layout test ./my-layout --update`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutTest(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().Bool("update", false, "Rewrite the golden projects from the output")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runLayoutTest executes the layout test command
+func runLayoutTest(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	update, _ := cmd.Flags().GetBool("update")
+	dir := args[0]
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	cases, err := manager.TestLayout(context.Background(), dir, update)
+	if err != nil {
+		return fmt.Errorf("failed to test layout: %w", err)
+	}
+
+	failed := 0
+	for _, result := range cases {
+		if result.Status == layout.SnapshotFailed {
+			failed++
+		}
+	}
+
+	if !adapter.SetReportData(cases) {
+		fmt.Fprintf(stdout, "🧪 Testing layout %s\n", dir)
+		options := diffOptions(cmd, stdout)
+		for _, result := range cases {
+			printSnapshotCase(stdout, result, options)
+		}
+		if failed == 0 {
+			fmt.Fprintf(stdout, "✅ %d case(s) passed\n", len(cases))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d case(s) failed", failed, len(cases))
+	}
+	return nil
+}
+
+// printSnapshotCase prints the outcome of a case with the diffs of the
+// files it does not generate as expected
+func printSnapshotCase(stdout io.Writer, result layout.SnapshotCase, options diff.Options) {
+	switch result.Status {
+	case layout.SnapshotPassed:
+		fmt.Fprintf(stdout, "  ✓ %s (%d files)\n", result.Name, result.Files)
+	case layout.SnapshotUpdated:
+		fmt.Fprintf(stdout, "  ✏️  %s (%d files, golden project updated)\n", result.Name, result.Files)
+	default:
+		fmt.Fprintf(stdout, "  ✗ %s\n", result.Name)
+	}
+	if result.Error != "" {
+		fmt.Fprintf(stdout, "      %s\n", result.Error)
+	}
+
+	for _, mismatch := range result.Mismatches {
+		mark := "~"
+		switch mismatch.Kind {
+		case layout.MismatchUnexpected:
+			mark = "+"
+		case layout.MismatchMissing:
+			mark = "-"
+		case layout.MismatchInvalidGo:
+			mark = "!"
+		}
+		fmt.Fprintf(stdout, "      %s %s: %s\n", mark, mismatch.Path, mismatch.Message)
+		if mismatch.Kind != layout.MismatchInvalidGo {
+			fmt.Fprint(stdout, options.Render(mismatch.Change))
+		}
+	}
+}
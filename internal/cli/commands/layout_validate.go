@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// buildLayoutValidateCommand creates the layout validate command
+func buildLayoutValidateCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate <dir|name>",
+		Short: "Check a layout for mistakes",
+		Long: `Validate checks a layout the way its author would before publishing it:
+
+- the manifest parses and has a name and a version
+- every file and component in the manifest has a template, for every choice
+  of the options in its path
+- every template under project/ and components/ is used
+- every template parses and renders with sample data
+- templates only use fields of the data they get, and the variables and
+  options the manifest declares
+
+The argument is the directory of a layout, or the name of a built-in or
+local layout. Problems are printed as file:line; the command fails if any
+is an error, or with --strict, a warning.`,
+		Example: `  foundry layout validate ./my-layout
+  foundry layout validate standard
+  foundry layout validate ./my-layout --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLayoutValidate(cmd, args, adapter)
+		},
+	}
+
+	cmd.Flags().Bool("strict", false, "Fail on warnings too")
+	reportable(cmd, reportData)
+
+	return cmd
+}
+
+// runLayoutValidate executes the layout validate command
+func runLayoutValidate(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	stdout := adapter.GetOutput()
+	strict, _ := cmd.Flags().GetBool("strict")
+	target := args[0]
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var problems []layout.Problem
+	if info, statErr := os.Stat(target); statErr == nil && info.IsDir() {
+		problems, err = manager.ValidateDir(ctx, target)
+	} else {
+		problems, err = manager.ValidateLayout(ctx, target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to validate layout: %w", err)
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, problem := range problems {
+		if problem.Severity == layout.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if !adapter.SetReportData(problems) {
+		fmt.Fprintf(stdout, "🔍 Validating layout %s\n", target)
+		for _, problem := range problems {
+			fmt.Fprintf(stdout, "  %s\n", problem)
+		}
+		if len(problems) == 0 {
+			fmt.Fprintln(stdout, "✅ Layout is valid")
+		}
+	}
+
+	if errorCount > 0 || (strict && warningCount > 0) {
+		return fmt.Errorf("layout has %d error(s) and %d warning(s)", errorCount, warningCount)
+	}
+	if warningCount > 0 && adapter.run == nil {
+		fmt.Fprintf(stdout, "⚠️  Layout is valid with %d warning(s)\n", warningCount)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/project"
+	"github.com/spf13/cobra"
+)
+
+// recordGenerated wraps the run of an add subcommand so the files it creates
+// or changes are recorded in the lock file of the project
+func recordGenerated(c CLI, cmd *cobra.Command) {
+	run := cmd.RunE
+	if run == nil {
+		return
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return run(cmd, args)
+		}
+		if _, err := project.LoadConfig("."); err != nil {
+			return run(cmd, args)
+		}
+
+		// The lock exists before the generator runs, so that layout
+		// components can record how they were rendered
+		if err := ensureLock(".", c.Logger()); err != nil {
+			c.Logger().Warn("failed to create lock file", "path", layout.LockFileName, "error", err)
+		}
+		op, err := journal.Begin(".")
+		if err != nil {
+			return err
+		}
+		err = run(cmd, args)
+		op.End()
+		if err != nil {
+			return err
+		}
+
+		generator := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		if err := updateLock(".", generator, op); err != nil {
+			c.Logger().Warn("failed to update lock file", "path", layout.LockFileName, "error", err)
+		}
+		return nil
+	}
+}
+
+// updateLock records the files the generator created or changed. Files
+// that are not layout output also get a copy in the base directory, to
+// compare against later.
+func updateLock(projectRoot, generator string, op *journal.Operation) error {
+	lock, err := layout.LoadLock(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	files, err := journal.Compare(op)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, touched := range files {
+		path := touched.Path
+		if touched.Action == journal.Deleted || strings.HasPrefix(path, ".foundry/") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(projectRoot, path))
+		if err != nil {
+			return err
+		}
+		file := lock.Files[path]
+		file.Generator = generator
+		lock.Record(path, content, file)
+		changed = true
+
+		if file.Template == "" {
+			base := filepath.Join(projectRoot, layout.BaseDir, path)
+			if err := journal.Touch(base); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(base, content, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return lock.Save(projectRoot)
+}
+
+// ensureLock starts a lock file from the layout named in foundry.yaml if
+// the project has none
+func ensureLock(projectRoot string, logger *slog.Logger) error {
+	_, err := layout.LoadLock(projectRoot)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	config, err := project.LoadConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+	layoutName := config.Layout
+	if layoutName == "" {
+		layoutName = "standard"
+	}
+
+	lock := layout.NewLock(&layout.Layout{Name: layoutName})
+	if manager, err := newLayoutManager(logger); err == nil {
+		if l, err := manager.GetLayout(context.Background(), layoutName); err == nil {
+			lock = layout.NewLock(l)
+		}
+	}
+	return lock.Save(projectRoot)
+}
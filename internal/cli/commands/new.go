@@ -6,8 +6,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"time"
 
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
 	"github.com/shapestone/foundry/internal/layout"
 	"github.com/spf13/cobra"
 )
@@ -29,6 +30,13 @@ Use 'foundry init' if you want to initialize in the current directory.`,
   foundry new myapi --layout=microservice
   foundry new myapp --module=github.com/user/myapp
   foundry new mysvc --layout=hexagonal --author="John Doe"
+  foundry new api --router=gin --logger=zerolog
+  foundry new api --without=docker --dry-run
+  foundry new api --on-conflict=merge  # Generate into an existing directory
+  foundry new svc --layout=microservice --var grpc_port=9191 --var service_name=orders
+  foundry new --interactive     # Answer questions instead of passing flags
+  foundry new api --save-answers  # Record the settings in api/foundry.answers.yaml
+  foundry new --answers foundry.answers.yaml  # Generate the same project again
 
   # Compare with 'init' command (current directory):
   mkdir myproject && cd myproject
@@ -41,6 +49,11 @@ Use 'foundry init' if you want to initialize in the current directory.`,
 			if listLayouts, _ := cmd.Flags().GetBool("list-layouts"); listLayouts {
 				return nil
 			}
+			// The answers file or the wizard gives the name
+			answers, _ := cmd.Flags().GetString("answers")
+			if _, wizard := wizardPrompter(cmd, adapter); wizard || answers != "" {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
 			// Otherwise require exactly 1 argument
 			return cobra.ExactArgs(1)(cmd, args)
 		},
@@ -56,9 +69,15 @@ Use 'foundry init' if you want to initialize in the current directory.`,
 	cmd.Flags().StringP("license", "", "MIT", "Project license")
 	cmd.Flags().StringP("description", "d", "", "Project description")
 	cmd.Flags().StringP("github", "g", "", "GitHub username")
-	cmd.Flags().BoolP("force", "f", false, "Overwrite existing directory")
+	cmd.Flags().BoolP("force", "f", false, "Replace an existing directory")
 	cmd.Flags().Bool("no-git", false, "Skip git initialization")
 	cmd.Flags().Bool("list-layouts", false, "List available layouts and exit")
+	addStackFlags(cmd)
+	addWizardFlags(cmd)
+	addAnswersFlags(cmd)
+	addConflictFlag(cmd)
+	cmd.MarkFlagsMutuallyExclusive("force", "on-conflict")
+	reportable(cmd, reportProject)
 
 	return cmd
 }
@@ -67,42 +86,45 @@ Use 'foundry init' if you want to initialize in the current directory.`,
 func runNew(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 	stdout := adapter.GetStdout()
 	stderr := adapter.GetStderr()
+	logger := adapter.Logger()
 
 	// Check if listing layouts
 	if listLayouts, _ := cmd.Flags().GetBool("list-layouts"); listLayouts {
 		return listAvailableLayouts(stdout, adapter)
 	}
 
-	projectName := args[0]
+	var projectName string
+	if len(args) > 0 {
+		projectName = args[0]
+	}
 
-	// Validate project name
-	if !isValidProjectName(projectName) {
+	// Validate project name; without one it comes from the answers file or the wizard
+	if len(args) > 0 && !isValidProjectName(projectName) {
 		return fmt.Errorf("invalid project name: %s (use only letters, numbers, hyphens, and underscores)", projectName)
 	}
 
 	// Get flags
-	moduleName, _ := cmd.Flags().GetString("module")
 	layoutName, _ := cmd.Flags().GetString("layout")
-	author, _ := cmd.Flags().GetString("author")
-	license, _ := cmd.Flags().GetString("license")
-	description, _ := cmd.Flags().GetString("description")
-	githubUsername, _ := cmd.Flags().GetString("github")
 	force, _ := cmd.Flags().GetBool("force")
 	noGit, _ := cmd.Flags().GetBool("no-git")
-
-	// Default module name
-	if moduleName == "" {
-		if githubUsername != "" {
-			moduleName = fmt.Sprintf("github.com/%s/%s", githubUsername, projectName)
-		} else {
-			moduleName = projectName
-		}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	projectData, err := projectDataFromFlags(cmd, projectName)
+	if err != nil {
+		return err
 	}
 
-	// Default description
-	if description == "" {
-		description = fmt.Sprintf("A Go project created with Foundry using the %s layout", layoutName)
+	// Take the settings the flags leave open from an answers file, or ask
+	if answers, _ := cmd.Flags().GetString("answers"); answers != "" {
+		if layoutName, err = applyAnswers(cmd, args, layoutName, &projectData); err != nil {
+			return err
+		}
+	} else if prompter, wizard := wizardPrompter(cmd, adapter); wizard {
+		if layoutName, err = runWizard(cmd, args, adapter, prompter, layoutName, &projectData); err != nil {
+			return err
+		}
 	}
+	projectData.applyDefaults(layoutName)
+	projectName = projectData.ProjectName
 
 	// Create project directory path
 	currentDir, err := os.Getwd()
@@ -111,59 +133,97 @@ func runNew(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
 	}
 	projectPath := filepath.Join(currentDir, projectName)
 
+	// The new project journals its creation, so that it can be undone
+	var op *journal.Operation
+	if !dryRun {
+		if op, err = journal.Begin(projectPath); err != nil {
+			return err
+		}
+		defer op.End()
+	}
+
 	// Enhanced directory existence check with clearer messaging
-	if _, err := os.Stat(projectPath); err == nil {
-		if !force {
+	var (
+		backupPath string
+		conflicts  *conflict.Resolver
+	)
+	if _, err := os.Stat(projectPath); err == nil && !dryRun {
+		switch {
+		case cmd.Flags().Changed("on-conflict"):
+			// Generate into the directory, deciding per existing file
+			if conflicts, err = conflictResolver(cmd, adapter); err != nil {
+				return err
+			}
+		case force:
+			// Move the existing directory aside until the new project is
+			// written; its files are recorded first, so it can be undone
+			fmt.Fprintf(stdout, "🗑️  Replacing existing directory: %s\n", projectPath)
+			if err := journal.TouchTree(projectPath); err != nil {
+				return err
+			}
+			backupPath = projectPath + ".foundry-backup"
+			if err := os.Rename(projectPath, backupPath); err != nil {
+				return fmt.Errorf("failed to move existing directory aside: %w", err)
+			}
+		default:
 			fmt.Fprintf(stderr, "⚠️  Directory already exists: %s\n", projectPath)
 			fmt.Fprintln(stderr, "")
 			fmt.Fprintln(stderr, "Options:")
 			fmt.Fprintln(stderr, "  1. Use --force to overwrite the existing directory")
-			fmt.Fprintln(stderr, "  2. Choose a different project name")
-			fmt.Fprintln(stderr, "  3. Use 'foundry init' to initialize in the existing directory")
+			fmt.Fprintln(stderr, "  2. Use --on-conflict=skip|overwrite|merge|prompt to generate into it")
+			fmt.Fprintln(stderr, "  3. Choose a different project name")
+			fmt.Fprintln(stderr, "  4. Use 'foundry init' to initialize in the existing directory")
 			return fmt.Errorf("directory '%s' already exists (use --force to overwrite)", projectName)
 		}
-		// Remove existing directory
-		fmt.Fprintf(stdout, "🗑️  Removing existing directory: %s\n", projectPath)
-		if err := os.RemoveAll(projectPath); err != nil {
-			return fmt.Errorf("failed to remove existing directory: %w", err)
-		}
-	}
-
-	// Create project data
-	projectData := ProjectData{
-		ProjectName:     projectName,
-		ModuleName:      moduleName,
-		Author:          author,
-		License:         license,
-		Description:     description,
-		GitHubUsername:  githubUsername,
-		GoVersion:       "1.21",
-		Year:            time.Now().Year(),
-		CustomVariables: make(map[string]string),
 	}
 
 	// Clear creation message showing new directory location
 	fmt.Fprintf(stdout, "🚀 Creating new project '%s'...\n", projectName)
-	fmt.Fprintf(stdout, "📁 Creating directory: %s\n", projectPath)
+	if conflicts != nil {
+		fmt.Fprintf(stdout, "📂 Generating into existing directory: %s\n", projectPath)
+	} else {
+		fmt.Fprintf(stdout, "📁 Creating directory: %s\n", projectPath)
+	}
 	fmt.Fprintf(stdout, "🏗️  Layout: %s\n", layoutName)
 	fmt.Fprintln(stdout, "")
 
-	if err := generateProject(layoutName, projectPath, projectData, stdout, stderr); err != nil {
-		// Clean up on failure
-		os.RemoveAll(projectPath)
+	if dryRun {
+		return previewProject(layoutName, projectData, stdout, logger)
+	}
+
+	if err := generateProject(layoutName, projectPath, projectData, conflicts, stdout, logger); err != nil {
+		// Generation writes nothing when it fails; bring back what --force replaced
+		if backupPath != "" {
+			if rerr := os.Rename(backupPath, projectPath); rerr != nil {
+				logger.Warn("failed to restore the project directory", "path", projectPath, "backup", backupPath, "error", rerr)
+			}
+		}
 		return fmt.Errorf("failed to generate project: %w", err)
 	}
+	if backupPath != "" {
+		if err := os.RemoveAll(backupPath); err != nil {
+			logger.Warn("failed to remove the backup of the project directory", "path", backupPath, "error", err)
+		}
+	}
+
+	if err := saveAnswers(cmd, adapter, layoutName, projectPath, projectData); err != nil {
+		return err
+	}
+	op.End()
+	if err := recordOperation(logger, op, commandLine(cmd, args)); err != nil {
+		return err
+	}
 
 	// Initialize git repository
-	if !noGit {
+	if !noGit && !isGitRepo(projectPath) {
 		if err := initGitRepo(projectPath); err != nil {
-			fmt.Fprintf(stderr, "Warning: failed to initialize git repository: %v\n", err)
+			logger.Warn("failed to initialize git repository", "error", err)
 		} else {
 			fmt.Fprintln(stdout, "✓ Initialized git repository")
 
 			// Create initial commit in the project directory
 			if err := createInitialCommitInDir(projectPath); err != nil {
-				fmt.Fprintf(stderr, "Warning: failed to create initial commit: %v\n", err)
+				logger.Warn("failed to create initial commit", "error", err)
 			}
 		}
 	}
@@ -198,7 +258,7 @@ func listAvailableLayouts(stdout io.Writer, adapter *CLIAdapter) error {
 	}
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
 
-	manager, err := layout.NewManager(configPath)
+	manager, err := layout.NewManager(configPath, layout.WithLogger(adapter.Logger()))
 	if err != nil {
 		// If layout manager fails, show basic layouts
 		return showBasicLayouts(stdout)
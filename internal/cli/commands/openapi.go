@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/openapi"
+	"github.com/shapestone/foundry/internal/project"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// BuildOpenAPICommand creates the openapi command using the adapter pattern
+func BuildOpenAPICommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Work with OpenAPI documents",
+		Long: `Work with OpenAPI documents.
+
+Use 'foundry add handler --from-openapi' to generate handlers from a document.`,
+	}
+
+	cmd.AddCommand(buildOpenAPIExportCommand(adapter))
+
+	return cmd
+}
+
+// buildOpenAPIExportCommand creates the openapi export subcommand
+func buildOpenAPIExportCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export an OpenAPI 3.1 document describing the project's API",
+		Long: `Export an OpenAPI 3.1 document describing the project's API.
+
+Paths come from the handlers mounted in internal/routes/routes.go and the
+routes each handler registers. Request and response schemas come from the
+structs in internal/handlers and internal/models.`,
+		Example: `  foundry openapi export
+  foundry openapi export -f openapi.yaml
+  foundry openapi export --title "Orders API" --version 2.0.0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runOpenAPIExport(cmd, adapter)
+		},
+	}
+
+	cmd.Flags().StringP("file", "f", "", "Write the document to a file instead of stdout")
+	cmd.Flags().String("title", "", "API title (default: the module name)")
+	cmd.Flags().String("version", "1.0.0", "API version")
+	cmd.Flags().String("server", "/api/v1", "Route group the API is served under")
+	journaled(adapter, cmd)
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runOpenAPIExport executes the openapi export command
+func runOpenAPIExport(cmd *cobra.Command, adapter *CLIAdapter) error {
+	output, _ := cmd.Flags().GetString("file")
+	title, _ := cmd.Flags().GetString("title")
+	version, _ := cmd.Flags().GetString("version")
+	server, _ := cmd.Flags().GetString("server")
+
+	if title == "" {
+		title = path.Base(project.GetCurrentModule())
+	}
+	// The report takes stdout, so the document needs a file of its own
+	if output == "" && adapter.machineOutput() {
+		return fmt.Errorf("--output %s reports on stdout; write the document with --file", cmd.Flag("output").Value)
+	}
+
+	doc, err := openapi.Export(".", openapi.ExportOptions{
+		Title:   title,
+		Version: version,
+		Server:  server,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export OpenAPI document: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	encoder.Close()
+
+	if output == "" {
+		_, err := adapter.GetOutput().Write(buf.Bytes())
+		return err
+	}
+
+	if err := journal.Touch(output); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	fmt.Fprintf(adapter.GetStdout(), "✅ Exported %d path(s) to %s\n", len(doc.Paths), output)
+	return nil
+}
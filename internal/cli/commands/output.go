@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/logging"
+	"github.com/shapestone/foundry/internal/parser"
+	"github.com/shapestone/foundry/internal/scaffolder"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat is how a command reports what it did
+type OutputFormat string
+
+const (
+	// OutputText prints messages for people
+	OutputText OutputFormat = "text"
+	// OutputJSON prints one JSON document
+	OutputJSON OutputFormat = "json"
+	// OutputYAML prints one YAML document
+	OutputYAML OutputFormat = "yaml"
+)
+
+// parseOutputFormat returns the output format with the given name
+func parseOutputFormat(name string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(name)); format {
+	case "", OutputText:
+		return OutputText, nil
+	case OutputJSON, OutputYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format '%s' (use text, json or yaml)", name)
+}
+
+// outputFormat returns the format the global --output flag asks for
+func outputFormat(cmd *cobra.Command) (OutputFormat, error) {
+	flag := cmd.Root().PersistentFlags().Lookup("output")
+	if flag == nil {
+		return OutputText, nil
+	}
+	return parseOutputFormat(flag.Value.String())
+}
+
+// reportAnnotation marks the commands that can report with --output json
+// or yaml, and what they report
+const reportAnnotation = "foundry.report"
+
+const (
+	// reportFiles commands report the files they change in the current
+	// directory
+	reportFiles = "files"
+	// reportProject commands report the files of the project directory
+	// named by their first argument or answers file
+	reportProject = "project"
+	// reportData commands report the data they list
+	reportData = "data"
+)
+
+// reportable lets a command report with --output json or yaml
+func reportable(cmd *cobra.Command, kind string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[reportAnnotation] = kind
+}
+
+// Report is the document a command prints with --output json or yaml.
+// Paths are relative to the directory the command ran in; the metadata
+// foundry keeps under .foundry is left out.
+type Report struct {
+	Command      string                   `json:"command" yaml:"command"`
+	Success      bool                     `json:"success" yaml:"success"`
+	DryRun       bool                     `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	FilesCreated []string                 `json:"files_created" yaml:"files_created"`
+	FilesUpdated []string                 `json:"files_updated" yaml:"files_updated"`
+	FilesDeleted []string                 `json:"files_deleted,omitempty" yaml:"files_deleted,omitempty"`
+	Decisions    []conflict.Decision      `json:"decisions,omitempty" yaml:"decisions,omitempty"`
+	Warnings     []string                 `json:"warnings" yaml:"warnings"`
+	Errors       []parser.ValidationError `json:"errors" yaml:"errors"`
+	Data         interface{}              `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// reportRun is the state of a command that reports with --output json or
+// yaml while it runs
+type reportRun struct {
+	format    OutputFormat
+	report    *Report
+	operation *journal.Operation
+	resolvers []*conflict.Resolver
+	prefix    string
+	stdout    io.Writer
+	output    io.Writer
+	stderr    io.Writer
+	logger    *slog.Logger
+}
+
+// StartReport prepares the command about to run for --output json or yaml:
+// its messages go to stderr, the warnings it logs are collected, and the
+// files it touches in its directory are recorded to report the changes. It
+// does nothing for text output.
+func (a *CLIAdapter) StartReport(cmd *cobra.Command, args []string) error {
+	format, err := outputFormat(cmd)
+	if err != nil || format == OutputText {
+		return err
+	}
+	kind, ok := cmd.Annotations[reportAnnotation]
+	if !ok {
+		return fmt.Errorf("'%s' does not support --output %s", cmd.CommandPath(), format)
+	}
+
+	run := &reportRun{
+		format: format,
+		report: newReport(cmd, args),
+		stdout: a.stdout,
+		output: a.output,
+		stderr: a.stderr,
+		logger: a.logger,
+	}
+	if !run.report.DryRun && kind != reportData {
+		root := "."
+		if kind == reportProject {
+			root = newProjectName(cmd, args)
+		}
+		if root != "" {
+			if run.operation, err = journal.Begin(root); err != nil {
+				return err
+			}
+			run.prefix = root
+		}
+	}
+
+	if a.stdout == a.output {
+		a.stdout = run.stderr
+	}
+	a.output = run.stderr
+	a.logger = slog.New(&reportHandler{Handler: a.logger.Handler(), report: run.report})
+	a.run = run
+	return nil
+}
+
+// FinishReport prints the report of the command that ran, with the error
+// it returned, and returns the error. Commands that failed before they
+// started, such as on invalid arguments, report the error alone.
+func (a *CLIAdapter) FinishReport(cmd *cobra.Command, err error) error {
+	run := a.run
+	if run == nil {
+		if err == nil || cmd == nil {
+			return err
+		}
+		format, ferr := outputFormat(cmd)
+		if _, ok := cmd.Annotations[reportAnnotation]; ferr != nil || format == OutputText || !ok {
+			return err
+		}
+		run = &reportRun{format: format, report: newReport(cmd, nil), stdout: a.stdout, output: a.output, stderr: a.stderr, logger: a.logger}
+		run.report.Errors = append(run.report.Errors, parser.ValidationError{
+			Message:     err.Error(),
+			Code:        string(parser.CodeArgsInvalid),
+			Severity:    parser.SeverityError,
+			Suggestions: []string{fmt.Sprintf("Run '%s --help' for usage", cmd.CommandPath())},
+		})
+	}
+	a.stdout, a.output, a.stderr, a.logger, a.run = run.stdout, run.output, run.stderr, run.logger, nil
+
+	report := run.report
+	report.Success = err == nil
+	if err != nil && len(report.Errors) == 0 {
+		report.Errors = reportErrors(err)
+	}
+	if run.operation != nil {
+		run.operation.End()
+		files, cerr := journal.Compare(run.operation)
+		if cerr != nil {
+			report.Warnings = append(report.Warnings, cerr.Error())
+		}
+		for _, file := range files {
+			if strings.HasPrefix(file.Path, ".foundry/") {
+				continue
+			}
+			filePath := path.Join(run.prefix, file.Path)
+			switch file.Action {
+			case journal.Created:
+				report.FilesCreated = append(report.FilesCreated, filePath)
+			case journal.Modified:
+				report.FilesUpdated = append(report.FilesUpdated, filePath)
+			case journal.Deleted:
+				report.FilesDeleted = append(report.FilesDeleted, filePath)
+			}
+		}
+	}
+
+	for _, resolver := range run.resolvers {
+		for _, decision := range resolver.Decisions() {
+			switch decision.Action {
+			case conflict.Skipped, conflict.Overwritten, conflict.Merged, conflict.Conflicted:
+				decision.Path = path.Join(run.prefix, decision.Path)
+				report.Decisions = append(report.Decisions, decision)
+			}
+		}
+	}
+
+	if werr := writeReport(run.output, run.format, report); werr != nil && err == nil {
+		return werr
+	}
+	return err
+}
+
+// SetReportData sets the data the running command reports with --output
+// json or yaml. It returns false for text output, when the command prints
+// the data itself.
+func (a *CLIAdapter) SetReportData(data interface{}) bool {
+	if a.run == nil {
+		return false
+	}
+	a.run.report.Data = data
+	return true
+}
+
+// ReportDecisions adds the decisions the resolver makes about existing
+// files to the report of the running command
+func (a *CLIAdapter) ReportDecisions(resolver *conflict.Resolver) {
+	if a.run != nil && resolver != nil {
+		a.run.resolvers = append(a.run.resolvers, resolver)
+	}
+}
+
+// machineOutput reports whether the running command prints a report
+// instead of messages, so it must not ask questions
+func (a *CLIAdapter) machineOutput() bool {
+	return a.run != nil
+}
+
+// newReport starts the report of a command
+func newReport(cmd *cobra.Command, args []string) *Report {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	return &Report{
+		Command:      commandLine(cmd, args),
+		DryRun:       dryRun,
+		FilesCreated: []string{},
+		FilesUpdated: []string{},
+		Warnings:     []string{},
+		Errors:       []parser.ValidationError{},
+	}
+}
+
+// newProjectName returns the directory foundry new creates, from its first
+// argument or its answers file
+func newProjectName(cmd *cobra.Command, args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	if path, _ := cmd.Flags().GetString("answers"); path != "" {
+		if answers, err := layout.LoadAnswers(path); err == nil {
+			return answers.ProjectData().ProjectName
+		}
+	}
+	return ""
+}
+
+// reportErrors describes an error with a code and the ways to fix it
+func reportErrors(err error) []parser.ValidationError {
+	var (
+		exists     *conflict.ExistsError
+		invalid    *scaffolder.ValidationError
+		invalidAll scaffolder.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &exists):
+		var suggestions []string
+		for _, policy := range conflict.Policies {
+			if policy != conflict.Fail {
+				suggestions = append(suggestions, "--on-conflict="+string(policy))
+			}
+		}
+		return []parser.ValidationError{{
+			Field:       "path",
+			Value:       exists.Paths,
+			Message:     err.Error(),
+			Code:        string(parser.CodeNameExists),
+			Severity:    parser.SeverityError,
+			Suggestions: suggestions,
+		}}
+	case errors.As(err, &invalidAll):
+		errs := make([]parser.ValidationError, 0, len(invalidAll))
+		for _, e := range invalidAll {
+			errs = append(errs, parser.ValidationError{
+				Field:    e.Field,
+				Message:  e.Message,
+				Code:     string(parser.CodeArgsInvalid),
+				Severity: parser.SeverityError,
+			})
+		}
+		return errs
+	case errors.As(err, &invalid):
+		return []parser.ValidationError{{
+			Field:    invalid.Field,
+			Message:  invalid.Message,
+			Code:     string(parser.CodeArgsInvalid),
+			Severity: parser.SeverityError,
+		}}
+	}
+	return []parser.ValidationError{{
+		Message:  err.Error(),
+		Code:     string(parser.CodeCommandFailed),
+		Severity: parser.SeverityError,
+	}}
+}
+
+// writeReport prints the report as one JSON or YAML document
+func writeReport(w io.Writer, format OutputFormat, report *Report) error {
+	var (
+		content []byte
+		err     error
+	)
+	switch format {
+	case OutputYAML:
+		content, err = yaml.Marshal(report)
+	default:
+		content, err = json.MarshalIndent(report, "", "  ")
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// reportHandler collects the warnings and errors logged while a command
+// runs for its report, and passes them on
+type reportHandler struct {
+	slog.Handler
+	report *Report
+}
+
+func (h *reportHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn || h.Handler.Enabled(ctx, level)
+}
+
+func (h *reportHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelWarn {
+		h.report.Warnings = append(h.report.Warnings, logging.Strip(logging.Text(record)))
+	}
+	if !h.Handler.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *reportHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &reportHandler{Handler: h.Handler.WithAttrs(attrs), report: h.report}
+}
+
+func (h *reportHandler) WithGroup(name string) slog.Handler {
+	return &reportHandler{Handler: h.Handler.WithGroup(name), report: h.report}
+}
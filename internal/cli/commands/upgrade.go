@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// BuildUpgradeCommand creates the upgrade command using the adapter pattern
+func BuildUpgradeCommand(adapter *CLIAdapter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Apply a newer version of the project's layout",
+		Long: `Upgrade renders the project's layout again, with the settings recorded in
+.foundry/lock.yaml, and brings the changes into the project.
+
+Every file is merged three ways between the output it was generated with,
+the file as it is now and the new output. Files you have not edited are
+replaced, edits the layout does not touch are kept, and where both changed
+the same lines the file is left with conflict markers to resolve.
+
+The output each file was generated with is kept in .foundry/base. Commit it
+with .foundry/lock.yaml, so that upgrades work in every checkout; the journal
+in .foundry/journal is local and ignored by git.`,
+		Example: `  foundry upgrade
+  foundry upgrade --to 1.3.0
+  foundry upgrade --dry-run
+  foundry upgrade --dry-run --diff-context 1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(cmd, adapter)
+		},
+	}
+
+	cmd.Flags().String("to", "", "Layout version to upgrade to (default: latest available)")
+	cmd.Flags().Bool("dry-run", false, "Show what would change, with diffs, without writing files")
+	journaled(adapter, cmd)
+	reportable(cmd, reportFiles)
+
+	return cmd
+}
+
+// runUpgrade executes the upgrade command
+func runUpgrade(cmd *cobra.Command, adapter *CLIAdapter) error {
+	stdout := adapter.GetStdout()
+	version, _ := cmd.Flags().GetString("to")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return err
+	}
+	report, err := manager.UpgradeProject(context.Background(), cwd, version, dryRun)
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	adapter.SetReportData(report)
+
+	if dryRun {
+		fmt.Fprintln(stdout, "🔍 Dry run - no files will be changed")
+		options := diffOptions(cmd, stdout)
+		for _, file := range report.Files {
+			if file.Action != layout.UpgradeKept {
+				fmt.Fprint(stdout, options.Render(file.Change))
+			}
+		}
+	}
+	fmt.Fprintf(stdout, "⬆️  Upgrading %s layout %s → %s\n", report.Layout, report.FromVersion, report.ToVersion)
+	if len(report.Files) == 0 {
+		fmt.Fprintln(stdout, "✓ Project is up to date")
+		return nil
+	}
+
+	for _, file := range report.Files {
+		switch file.Action {
+		case layout.UpgradeAdded:
+			fmt.Fprintf(stdout, "  + %s\n", file.Path)
+		case layout.UpgradeUpdated, layout.UpgradeMerged:
+			fmt.Fprintf(stdout, "  ~ %s (%s)\n", file.Path, file.Action)
+		case layout.UpgradeConflict:
+			detail := fmt.Sprintf("%d conflict(s)", file.Conflicts)
+			if file.Reason != "" {
+				detail += ", " + file.Reason
+			}
+			fmt.Fprintf(stdout, "  ! %s (%s)\n", file.Path, detail)
+		case layout.UpgradeKept:
+			fmt.Fprintf(stdout, "  = %s (kept: %s)\n", file.Path, file.Reason)
+		}
+	}
+
+	if conflicts := report.Conflicts(); conflicts > 0 {
+		if dryRun {
+			fmt.Fprintf(stdout, "⚠️  %d file(s) would get conflict markers\n", conflicts)
+			return nil
+		}
+		fmt.Fprintf(stdout, "⚠️  Resolve the <<<<<<< markers in %d file(s)\n", conflicts)
+		return fmt.Errorf("upgrade left conflicts in %d file(s)", conflicts)
+	}
+	if !dryRun {
+		fmt.Fprintf(stdout, "✅ Upgraded to %s %s\n", report.Layout, report.ToVersion)
+	}
+	return nil
+}
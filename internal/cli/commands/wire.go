@@ -1,9 +1,26 @@
 package commands
 
 import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/middleware"
+	"github.com/shapestone/foundry/internal/project"
+	"github.com/shapestone/foundry/internal/routes"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// wireConfig describes where components are wired. It can be overridden
+// with a YAML file passed through --config.
+type wireConfig struct {
+	RoutesFile string `yaml:"routes_file"`
+	MainFile   string `yaml:"main_file"`
+	RouteGroup string `yaml:"route_group"`
+}
+
 // BuildWireCommand creates the wire command using the adapter pattern
 func BuildWireCommand(adapter *CLIAdapter) *cobra.Command {
 	cmd := &cobra.Command{
@@ -11,58 +28,176 @@ func BuildWireCommand(adapter *CLIAdapter) *cobra.Command {
 		Aliases: []string{"w"},
 		Short:   "Wire components into your project",
 		Long: `Wire automatically connects components to your application.
-This includes updating imports, registering handlers, and applying middleware.`,
+This includes updating imports, registering handlers, and applying middleware.
+
+Files are edited through their Go syntax tree, so wiring keeps working
+no matter how routes.go or main.go are formatted.`,
 		Example: `  foundry wire handler user
-  foundry wire middleware auth`,
+  foundry wire middleware auth
+  foundry wire handler user --dry-run
+  foundry wire middleware cors --config wire.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runWire(cmd, args, adapter)
 		},
 	}
 
 	// Add flags for wire command
-	cmd.Flags().Bool("dry-run", false, "Show what would be wired without making changes")
-	cmd.Flags().BoolP("force", "f", false, "Overwrite existing wiring configurations")
-	cmd.Flags().StringP("config", "c", "", "Custom wiring configuration file")
+	cmd.PersistentFlags().Bool("dry-run", false, "Show what would be wired without making changes")
+	cmd.PersistentFlags().BoolP("force", "f", false, "Overwrite existing wiring configurations")
+	cmd.PersistentFlags().StringP("config", "c", "", "Custom wiring configuration file")
+
+	cmd.AddCommand(buildWireHandlerCommand(adapter))
+	cmd.AddCommand(buildWireMiddlewareCommand(adapter))
+	for _, sub := range cmd.Commands() {
+		journaled(adapter, sub)
+		reportable(sub, reportFiles)
+	}
 
 	return cmd
 }
 
+// buildWireHandlerCommand creates the wire handler subcommand
+func buildWireHandlerCommand(adapter *CLIAdapter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "handler [name]",
+		Short: "Register a handler with the API routes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWireHandler(cmd, args, adapter)
+		},
+	}
+}
+
+// buildWireMiddlewareCommand creates the wire middleware subcommand
+func buildWireMiddlewareCommand(adapter *CLIAdapter) *cobra.Command {
+	return &cobra.Command{
+		Use:   "middleware [type]",
+		Short: "Apply middleware to the application router",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWireMiddleware(cmd, args, adapter)
+		},
+	}
+}
+
 // runWire executes the wire command
 func runWire(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
-	// Get flags
+	if len(args) == 0 {
+		// Show help if no arguments provided
+		return cmd.Help()
+	}
+
+	return fmt.Errorf("unknown resource %q: expected handler or middleware", args[0])
+}
+
+// runWireHandler registers a handler in routes.go
+func runWireHandler(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	name := args[0]
+
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	force, _ := cmd.Flags().GetBool("force")
-	configFile, _ := cmd.Flags().GetString("config")
 
-	// For now, this is a placeholder implementation
-	// TODO: Implement actual wiring functionality
+	config, err := loadWireConfig(cmd)
+	if err != nil {
+		return err
+	}
 
-	if len(args) == 0 {
-		// Show help if no arguments provided
-		return cmd.Help()
+	generator := routes.NewFileGenerator(
+		routes.WithRoutesFile(config.RoutesFile),
+		routes.WithRouteGroup(config.RouteGroup),
+		routes.WithForce(force),
+	)
+
+	fmt.Fprintf(adapter.GetStdout(), "🔌 Wiring handler: %s\n", name)
+
+	update, err := generator.UpdateRoutes(name, project.GetCurrentModule())
+	if err != nil {
+		return fmt.Errorf("failed to wire handler: %w", err)
+	}
+
+	if err := applyWireUpdate(adapter.GetStdout(), update, generator, diffOptions(cmd, adapter.GetStdout()), dryRun); err != nil {
+		return fmt.Errorf("failed to wire handler: %w", err)
 	}
 
-	resource := args[0]
+	if !dryRun {
+		fmt.Fprintf(adapter.GetStdout(), "✅ Handler %s wired successfully!\n", name)
+	}
+	return nil
+}
+
+// runWireMiddleware registers middleware with the router in main.go
+func runWireMiddleware(cmd *cobra.Command, args []string, adapter *CLIAdapter) error {
+	middlewareType := args[0]
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+
+	config, err := loadWireConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	wirer := middleware.NewAutoWirer(".",
+		middleware.WithMainFile(config.MainFile),
+		middleware.WithForce(force),
+		middleware.WithLogger(adapter.Logger()),
+	)
+
+	fmt.Fprintf(adapter.GetStdout(), "🔌 Wiring middleware: %s\n", middlewareType)
+
+	update, err := wirer.PlanMiddleware(middlewareType)
+	if err != nil {
+		return fmt.Errorf("failed to wire middleware: %w", err)
+	}
+
+	if err := applyWireUpdate(adapter.GetStdout(), update, routes.NewFileGenerator(), diffOptions(cmd, adapter.GetStdout()), dryRun); err != nil {
+		return fmt.Errorf("failed to wire middleware: %w", err)
+	}
+
+	if !dryRun {
+		fmt.Fprintf(adapter.GetStdout(), "✅ Middleware %s wired successfully!\n", middlewareType)
+	}
+	return nil
+}
+
+// applyWireUpdate shows the planned changes and their diff, and writes them
+// unless dryRun is set
+func applyWireUpdate(stdout io.Writer, update *routes.Update, validator routes.Generator, options diff.Options, dryRun bool) error {
+	if dryRun {
+		fmt.Fprintf(stdout, "📝 Would update %s:\n", update.Path)
+	} else {
+		fmt.Fprintf(stdout, "📝 Updating %s:\n", update.Path)
+	}
+	for _, change := range update.Changes {
+		fmt.Fprintf(stdout, "  - %s\n", change)
+	}
+	fmt.Fprint(stdout, options.Render(update.Diff()))
 
 	if dryRun {
-		cmd.Printf("Would wire %s component (dry-run mode)\n", resource)
-		if force {
-			cmd.Printf("  - Force mode enabled\n")
-		}
-		if configFile != "" {
-			cmd.Printf("  - Using config file: %s\n", configFile)
-		}
+		fmt.Fprintln(stdout, "🔍 Dry run complete - no changes made")
 		return nil
 	}
 
-	// Placeholder implementation
-	cmd.Printf("Wiring %s component...\n", resource)
-	cmd.Printf("⚠️  Wire command is not yet fully implemented\n")
-	cmd.Printf("This feature will automatically:\n")
-	cmd.Printf("  - Update imports\n")
-	cmd.Printf("  - Register handlers with routers\n")
-	cmd.Printf("  - Apply middleware to routes\n")
-	cmd.Printf("  - Update dependency injection configurations\n")
+	return routes.ApplyUpdate(update, validator)
+}
 
-	return nil
+// loadWireConfig reads the optional wiring configuration file
+func loadWireConfig(cmd *cobra.Command) (*wireConfig, error) {
+	config := &wireConfig{}
+
+	configFile, _ := cmd.Flags().GetString("config")
+	if configFile == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wiring config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse wiring config %s: %w", configFile, err)
+	}
+
+	return config, nil
 }
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/interactive"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// addWizardFlags adds the flag that turns the project wizard on or off
+func addWizardFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("interactive", "i", false, "Ask for the project settings (default: when run in a terminal without --layout)")
+}
+
+// wizardPrompter returns the prompter for the project wizard and whether the
+// wizard runs: when --interactive is set, or when the input is a terminal
+// and no layout was chosen
+func wizardPrompter(cmd *cobra.Command, adapter *CLIAdapter) (*interactive.ConsolePrompter, bool) {
+	prompter := interactive.NewConsolePrompterWithIO(adapter.GetStdin(), adapter.GetOutput())
+	prompter.SetDiffOptions(diffOptions(cmd, adapter.GetOutput()))
+	if cmd.Flags().Changed("interactive") {
+		enabled, _ := cmd.Flags().GetBool("interactive")
+		prompter.SetInteractive(enabled)
+		return prompter, enabled
+	}
+	return prompter, prompter.Interactive() && !cmd.Flags().Changed("layout") && !adapter.machineOutput()
+}
+
+// runWizard asks for the project settings the command line leaves open,
+// shows the files the project will get and asks for confirmation. It fills
+// in data and returns the chosen layout.
+func runWizard(cmd *cobra.Command, args []string, adapter *CLIAdapter, prompter interactive.Prompter, layoutName string, data *ProjectData) (string, error) {
+	manager, err := newLayoutManager(adapter.Logger())
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		name, err := prompter.Input("Project name", data.ProjectName, func(name string) error {
+			if !isValidProjectName(name) {
+				return fmt.Errorf("invalid project name: %s (use only letters, numbers, hyphens, and underscores)", name)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		data.ProjectName = name
+	}
+
+	if !cmd.Flags().Changed("layout") {
+		layoutName = prompter.Select("Layout", layoutNames(manager), layoutName)
+	}
+	l, err := manager.GetLayout(ctx, layoutName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load layout: %w", err)
+	}
+
+	if data.ModuleName == "" {
+		module, err := prompter.Input("Module path", defaultModuleName(data.ProjectName, data.GitHubUsername), nil)
+		if err != nil {
+			return "", err
+		}
+		data.ModuleName = module
+	}
+
+	// Options in name order, skipping the ones chosen with flags
+	names := make([]string, 0, len(l.Manifest.Options))
+	for name := range l.Manifest.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, chosen := data.Options[name]; chosen {
+			continue
+		}
+		option := l.Manifest.Options[name]
+		data.Options[name] = prompter.Select(promptLabel(name, option.Description), option.Choices, option.Default)
+	}
+
+	for _, variable := range l.Manifest.Variables {
+		if _, set := data.CustomVariables[variable.Name]; set {
+			continue
+		}
+		value, err := askVariable(prompter, variable)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			data.CustomVariables[variable.Name] = value
+		}
+	}
+
+	if features := l.Manifest.Features; len(features) > 0 {
+		var enabled []string
+		for _, feature := range features {
+			if on, set := data.Features[feature]; !set || on {
+				enabled = append(enabled, feature)
+			}
+		}
+		chosen := prompter.MultiSelect("Features", features, enabled)
+		for _, feature := range features {
+			data.Features[feature] = slices.Contains(chosen, feature)
+		}
+	}
+
+	plan, err := manager.PlanProject(ctx, layoutName, data.layoutData())
+	if err != nil {
+		return "", fmt.Errorf("layout generation failed: %w", err)
+	}
+	preview := fileTree(data.ProjectName, plan)
+	title := fmt.Sprintf("%s (%s layout)", data.ProjectName, layoutName)
+	if !prompter.ShowPreview(title, preview, "Create this project?") {
+		return "", fmt.Errorf("project creation cancelled by user")
+	}
+
+	return layoutName, nil
+}
+
+// askVariable asks for the value of a layout variable, checked against its
+// type
+func askVariable(prompter interactive.Prompter, variable layout.LayoutVariable) (string, error) {
+	label := promptLabel(variable.Name, variable.Description)
+	if variable.Type == layout.VariableEnum {
+		return prompter.Select(label, variable.Choices, variable.Default), nil
+	}
+
+	return prompter.Input(label, variable.Default, func(value string) error {
+		if value == "" {
+			if variable.Required {
+				return fmt.Errorf("%s is required", variable.Name)
+			}
+			return nil
+		}
+		_, err := variable.Validate(value)
+		return err
+	})
+}
+
+// promptLabel describes a setting by its description, falling back to its
+// name
+func promptLabel(name, description string) string {
+	if description == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", description, name)
+}
+
+// layoutNames returns the names of the available layouts in order
+func layoutNames(manager *layout.Manager) []string {
+	var names []string
+	for _, entry := range manager.ListLayouts() {
+		if !slices.Contains(names, entry.Name) {
+			names = append(names, entry.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileTree renders the plan of a project as a tree under its name, followed
+// by the entries the layout skips
+func fileTree(projectName string, plan *layout.ProjectPlan) []string {
+	root := &treeNode{}
+	for _, dir := range plan.Directories {
+		root.add(strings.Split(strings.Trim(dir, "/"), "/"), true)
+	}
+	for _, file := range plan.Files {
+		root.add(strings.Split(file, "/"), false)
+	}
+
+	lines := []string{projectName + "/"}
+	lines = root.render("", lines)
+	for _, entry := range plan.Skipped {
+		lines = append(lines, fmt.Sprintf("- %s (skipped: %s)", entry.Path, entry.When))
+	}
+	return lines
+}
+
+// treeNode is a directory or file in a file tree preview
+type treeNode struct {
+	name     string
+	dir      bool
+	children []*treeNode
+}
+
+// add inserts the path below the node, creating its parent directories
+func (n *treeNode) add(parts []string, dir bool) {
+	if len(parts) == 0 || parts[0] == "" {
+		return
+	}
+	last := len(parts) == 1
+	for _, child := range n.children {
+		if child.name == parts[0] {
+			child.add(parts[1:], dir)
+			return
+		}
+	}
+	child := &treeNode{name: parts[0], dir: dir || !last}
+	n.children = append(n.children, child)
+	child.add(parts[1:], dir)
+}
+
+// render appends the children of the node to lines, directories first
+func (n *treeNode) render(indent string, lines []string) []string {
+	sort.SliceStable(n.children, func(i, j int) bool {
+		if n.children[i].dir != n.children[j].dir {
+			return n.children[i].dir
+		}
+		return n.children[i].name < n.children[j].name
+	})
+
+	for i, child := range n.children {
+		branch, next := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, next = "└── ", "    "
+		}
+		name := child.name
+		if child.dir {
+			name += "/"
+		}
+		lines = append(lines, indent+branch+name)
+		lines = child.render(indent+next, lines)
+	}
+	return lines
+}
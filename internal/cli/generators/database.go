@@ -3,23 +3,27 @@ package generators
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/shapestone/foundry/internal/cli/templates"
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/staging"
 )
 
 // DatabaseGenerator handles database file generation
 type DatabaseGenerator struct {
 	stdout io.Writer
-	stderr io.Writer
+	logger *slog.Logger
 }
 
 // NewDatabaseGenerator creates a new database generator
-func NewDatabaseGenerator(stdout, stderr io.Writer) *DatabaseGenerator {
+func NewDatabaseGenerator(stdout io.Writer, logger *slog.Logger) *DatabaseGenerator {
 	return &DatabaseGenerator{
 		stdout: stdout,
-		stderr: stderr,
+		logger: logger,
 	}
 }
 
@@ -29,6 +33,9 @@ type DatabaseOptions struct {
 	WithMigrations bool
 	WithDocker     bool
 	OutputDir      string
+
+	// Conflicts decides what happens to generated files that already exist
+	Conflicts *conflict.Resolver
 }
 
 // Generate creates database files based on options
@@ -38,37 +45,59 @@ func (g *DatabaseGenerator) Generate(options DatabaseOptions) error {
 		return fmt.Errorf("unsupported database type: %s", options.Type)
 	}
 
+	// Stage all files first; the project is only written to once every
+	// file is ready, and not at all if writing any of them fails
+	area := staging.New(".")
+
 	// Create database configuration file
 	dbPath := filepath.Join(options.OutputDir, "database.go")
-	if err := g.createDatabaseFile(dbPath, options.Type); err != nil {
+	if err := g.createDatabaseFile(area, dbPath, options.Type); err != nil {
 		return fmt.Errorf("failed to create database file: %w", err)
 	}
 
 	// Create config file
 	configPath := filepath.Join(options.OutputDir, "config.go")
-	if err := g.createConfigFile(configPath); err != nil {
+	if err := g.createConfigFile(area, configPath); err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
 
 	// Create .env.example
-	if err := g.createEnvExample(options.Type, dbInfo.DefaultPort); err != nil {
-		fmt.Fprintf(g.stderr, "⚠️  Warning: couldn't create .env.example: %v\n", err)
+	if err := g.createEnvExample(area, options.Type, dbInfo.DefaultPort); err != nil {
+		g.logger.Warn("couldn't create .env.example", "error", err)
 	}
 
 	// Handle migrations
 	if options.WithMigrations && options.Type != "mongodb" {
-		if err := g.createMigrationSetup(options.Type); err != nil {
-			fmt.Fprintf(g.stderr, "⚠️  Warning: couldn't create migration setup: %v\n", err)
+		if err := g.createMigrationSetup(area, options.Type); err != nil {
+			g.logger.Warn("couldn't create migration setup", "error", err)
 		}
 	}
 
 	// Handle Docker
 	if options.WithDocker && dbInfo.DockerImage != "" {
-		if err := g.createDockerSetup(options.Type); err != nil {
-			fmt.Fprintf(g.stderr, "⚠️  Warning: couldn't create Docker setup: %v\n", err)
+		if err := g.createDockerSetup(area, options.Type); err != nil {
+			g.logger.Warn("couldn't create Docker setup", "error", err)
 		}
 	}
 
+	// .env.example is extended rather than generated, so it never conflicts
+	if options.Conflicts != nil {
+		var generated []string
+		for _, path := range area.Files() {
+			if path != ".env.example" {
+				generated = append(generated, path)
+			}
+		}
+		options.Conflicts.SetBaseline(layout.Baseline("."))
+		if _, err := options.Conflicts.Resolve(area, generated); err != nil {
+			return err
+		}
+	}
+
+	if err := area.Commit(); err != nil {
+		return fmt.Errorf("failed to write database files: %w", err)
+	}
+
 	// Show success message
 	g.showSuccess(options, dbInfo)
 
@@ -76,19 +105,19 @@ func (g *DatabaseGenerator) Generate(options DatabaseOptions) error {
 }
 
 // createDatabaseFile creates the main database configuration file
-func (g *DatabaseGenerator) createDatabaseFile(dbPath, dbType string) error {
+func (g *DatabaseGenerator) createDatabaseFile(area *staging.Area, dbPath, dbType string) error {
 	template := templates.GetDatabaseTemplate(dbType)
-	return writeFile(dbPath, template)
+	return area.WriteFile(dbPath, []byte(template), 0644)
 }
 
 // createConfigFile creates the database config file
-func (g *DatabaseGenerator) createConfigFile(configPath string) error {
+func (g *DatabaseGenerator) createConfigFile(area *staging.Area, configPath string) error {
 	template := templates.GetConfigTemplate()
-	return writeFile(configPath, template)
+	return area.WriteFile(configPath, []byte(template), 0644)
 }
 
 // createEnvExample creates or updates .env.example
-func (g *DatabaseGenerator) createEnvExample(dbType, defaultPort string) error {
+func (g *DatabaseGenerator) createEnvExample(area *staging.Area, dbType, defaultPort string) error {
 	envPath := ".env.example"
 
 	// Read existing content if file exists
@@ -107,31 +136,31 @@ func (g *DatabaseGenerator) createEnvExample(dbType, defaultPort string) error {
 		existing = dbEnvVars
 	}
 
-	return os.WriteFile(envPath, []byte(existing), 0644)
+	return area.WriteFile(envPath, []byte(existing), 0644)
 }
 
 // createMigrationSetup creates migration directory and files
-func (g *DatabaseGenerator) createMigrationSetup(dbType string) error {
+func (g *DatabaseGenerator) createMigrationSetup(area *staging.Area, dbType string) error {
 	migrationsDir := "migrations"
-	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+	if err := area.MkdirAll(migrationsDir); err != nil {
 		return err
 	}
 
 	// Create README for migrations
 	readmePath := filepath.Join(migrationsDir, "README.md")
 	readmeTemplate := templates.GetMigrationReadmeTemplate()
-	if err := writeFile(readmePath, readmeTemplate); err != nil {
+	if err := area.WriteFile(readmePath, []byte(readmeTemplate), 0644); err != nil {
 		return err
 	}
 
 	// Create example migration
 	examplePath := filepath.Join(migrationsDir, "001_initial_schema.sql")
 	exampleTemplate := templates.GetExampleMigrationTemplate(dbType)
-	return writeFile(examplePath, exampleTemplate)
+	return area.WriteFile(examplePath, []byte(exampleTemplate), 0644)
 }
 
 // createDockerSetup creates docker-compose.yml
-func (g *DatabaseGenerator) createDockerSetup(dbType string) error {
+func (g *DatabaseGenerator) createDockerSetup(area *staging.Area, dbType string) error {
 	dockerPath := "docker-compose.yml"
 	if _, err := os.Stat(dockerPath); err == nil {
 		fmt.Fprintln(g.stdout, "⚠️  docker-compose.yml already exists, skipping Docker setup")
@@ -139,7 +168,7 @@ func (g *DatabaseGenerator) createDockerSetup(dbType string) error {
 	}
 
 	template := templates.GetDockerTemplate(dbType)
-	return writeFile(dockerPath, template)
+	return area.WriteFile(dockerPath, []byte(template), 0644)
 }
 
 // showSuccess displays success message with setup instructions
@@ -4,25 +4,29 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/diff"
 	"github.com/shapestone/foundry/internal/layout"
 	"github.com/shapestone/foundry/internal/routes"
+	"github.com/shapestone/foundry/internal/scaffolder"
 )
 
 // HandlerGenerator handles handler file generation
 type HandlerGenerator struct {
 	stdout io.Writer
-	stderr io.Writer
+	logger *slog.Logger
 }
 
 // NewHandlerGenerator creates a new handler generator
-func NewHandlerGenerator(stdout, stderr io.Writer) *HandlerGenerator {
+func NewHandlerGenerator(stdout io.Writer, logger *slog.Logger) *HandlerGenerator {
 	return &HandlerGenerator{
 		stdout: stdout,
-		stderr: stderr,
+		logger: logger,
 	}
 }
 
@@ -30,7 +34,18 @@ func NewHandlerGenerator(stdout, stderr io.Writer) *HandlerGenerator {
 type HandlerOptions struct {
 	Name      string
 	AutoWire  bool
+	DryRun    bool
 	OutputDir string
+
+	// OpenAPIFile generates handlers from an OpenAPI document instead of
+	// the CRUD template, optionally limited to operations with Tag
+	OpenAPIFile string
+	Tag         string
+
+	// Diff controls how the changes to existing files are shown
+	Diff diff.Options
+	// Conflicts decides what happens to handler files that already exist
+	Conflicts *conflict.Resolver
 }
 
 // Generate creates handler files based on options
@@ -38,22 +53,38 @@ func (g *HandlerGenerator) Generate(options HandlerOptions) error {
 	// Detect current layout
 	layoutName, err := detectProjectLayout()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: could not detect project layout, using standard: %v\n", err)
+		g.logger.Warn("could not detect project layout, using standard", "error", err)
 		layoutName = "standard"
 	}
 
 	// Get layout manager
 	manager, err := g.getLayoutManager()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: layout manager unavailable, falling back to legacy generation: %v\n", err)
+		g.logger.Warn("layout manager unavailable, falling back to legacy generation", "error", err)
 		return g.generateLegacyHandler(options)
 	}
 
 	// Generate component using layout system
-	fmt.Fprintf(g.stdout, "🔨 Generating handler using '%s' layout...\n", layoutName)
+	router := routes.DetectRouter(".")
+	fmt.Fprintf(g.stdout, "🔨 Generating handler using '%s' layout for the %s router...\n", layoutName, router)
 
 	ctx := context.Background()
-	err = manager.GenerateComponent(ctx, layoutName, "handler", options.Name, ".")
+	err = manager.GenerateComponent(ctx, layoutName, "handler", options.Name, ".",
+		layout.WithComponentConflicts(options.Conflicts),
+		layout.WithComponentData(map[string]interface{}{
+			"ModuleName":   getCurrentModule(),
+			"Router":       string(router),
+			"RouterImport": router.ImportPath(),
+			"IDParam":      router.PathValue("id"),
+			"Routes": []string{
+				router.Route("GET", "/", "h.List"),
+				router.Route("POST", "/", "h.Create"),
+				router.Route("GET", "/{id}", "h.GetByID"),
+				router.Route("PUT", "/{id}", "h.Update"),
+				router.Route("DELETE", "/{id}", "h.Delete"),
+			},
+		}),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to generate handler using layout system: %w", err)
 	}
@@ -61,8 +92,8 @@ func (g *HandlerGenerator) Generate(options HandlerOptions) error {
 	// Handle auto-wiring
 	if options.AutoWire {
 		fmt.Fprintln(g.stdout, "\n🔄 Auto-wiring handler...")
-		if err := g.wireHandler(options.Name); err != nil {
-			fmt.Fprintf(g.stderr, "❌ Error auto-wiring handler: %v\n", err)
+		if err := g.wireHandler(options.Name, options.Diff); err != nil {
+			g.logger.Error("auto-wiring handler failed", "error", err)
 			fmt.Fprintln(g.stdout, "💡 Your handler was created but you'll need to manually wire it up")
 			fmt.Fprintf(g.stdout, "   You can try: foundry wire handler %s\n", options.Name)
 			g.showSuccess(options, false)
@@ -80,17 +111,22 @@ func (g *HandlerGenerator) Generate(options HandlerOptions) error {
 func (g *HandlerGenerator) generateLegacyHandler(options HandlerOptions) error {
 	fmt.Fprintln(g.stdout, "🔧 Using legacy handler generation...")
 
-	// Create handler file using legacy template
-	handlerPath := filepath.Join(options.OutputDir, fmt.Sprintf("%s.go", strings.ToLower(options.Name)))
-	if err := g.createLegacyHandlerFile(handlerPath, options.Name); err != nil {
+	// Create handler file from the built-in template
+	spec := &scaffolder.HandlerSpec{
+		Name:        options.Name,
+		ProjectRoot: ".",
+		Module:      getCurrentModule(),
+		Conflicts:   options.Conflicts,
+	}
+	if _, err := scaffolder.NewScaffolderWithAdapters().CreateHandler(context.Background(), spec); err != nil {
 		return fmt.Errorf("failed to create handler file: %w", err)
 	}
 
 	// Handle auto-wiring
 	if options.AutoWire {
 		fmt.Fprintln(g.stdout, "\n🔄 Auto-wiring handler...")
-		if err := g.wireHandler(options.Name); err != nil {
-			fmt.Fprintf(g.stderr, "❌ Error auto-wiring handler: %v\n", err)
+		if err := g.wireHandler(options.Name, options.Diff); err != nil {
+			g.logger.Error("auto-wiring handler failed", "error", err)
 			fmt.Fprintln(g.stdout, "💡 Your handler was created but you'll need to manually wire it up")
 			fmt.Fprintf(g.stdout, "   You can try: foundry wire handler %s\n", options.Name)
 			g.showSuccess(options, false)
@@ -104,13 +140,6 @@ func (g *HandlerGenerator) generateLegacyHandler(options HandlerOptions) error {
 	return nil
 }
 
-// createLegacyHandlerFile creates a handler file using legacy templates
-func (g *HandlerGenerator) createLegacyHandlerFile(handlerPath, name string) error {
-	// Get legacy template (you can move this to a separate legacy templates file)
-	template := getLegacyHandlerTemplate(name)
-	return writeFile(handlerPath, template)
-}
-
 // getLayoutManager gets the layout manager instance
 func (g *HandlerGenerator) getLayoutManager() (*layout.Manager, error) {
 	homeDir, err := os.UserHomeDir()
@@ -119,7 +148,7 @@ func (g *HandlerGenerator) getLayoutManager() (*layout.Manager, error) {
 	}
 
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
-	return layout.NewManager(configPath)
+	return layout.NewManager(configPath, layout.WithLogger(g.logger))
 }
 
 // detectProjectLayout detects the current project layout
@@ -144,7 +173,7 @@ func detectProjectLayout() (string, error) {
 }
 
 // wireHandler attempts to auto-wire handler into routes
-func (g *HandlerGenerator) wireHandler(name string) error {
+func (g *HandlerGenerator) wireHandler(name string, options diff.Options) error {
 	// Get current module name
 	moduleName := getCurrentModule()
 	if moduleName == "" {
@@ -165,6 +194,7 @@ func (g *HandlerGenerator) wireHandler(name string) error {
 	for _, change := range update.Changes {
 		fmt.Fprintf(g.stdout, "  - %s\n", change)
 	}
+	fmt.Fprint(g.stdout, options.Render(update.Diff()))
 
 	// Apply the changes
 	if err := routes.ApplyUpdate(update, generator); err != nil {
@@ -179,6 +209,8 @@ func (g *HandlerGenerator) wireHandler(name string) error {
 func (g *HandlerGenerator) showSuccess(options HandlerOptions, autoWired bool) {
 	handlerPath := filepath.Join(options.OutputDir, fmt.Sprintf("%s.go", strings.ToLower(options.Name)))
 	resourcePath := strings.ToLower(options.Name) + "s" // simple pluralization
+	typeName := routes.ExportedName(strings.ToLower(options.Name)) + "Handler"
+	handlerVar := routes.UnexportedName(typeName)
 
 	wireStatus := ""
 	if autoWired {
@@ -208,14 +240,14 @@ func (g *HandlerGenerator) showSuccess(options HandlerOptions, autoWired bool) {
 
 💡 Next steps:
   - Import the handlers package in your routes file
-  - Implement your business logic in %sHandler methods
+  - Implement your business logic in %s methods
   - Add validation and error handling
   - Connect to your database or service layer
   - Add tests for the handler
   
   Example usage:
-    %sHandler := handlers.New%sHandler()
-    %sHandler.RegisterRoutes(apiRouter)
+    %s := handlers.New%s()
+    %s
 `, handlerPath,
 		wireStatus,
 		resourcePath, resourcePath,
@@ -223,145 +255,9 @@ func (g *HandlerGenerator) showSuccess(options HandlerOptions, autoWired bool) {
 		resourcePath, strings.ToLower(options.Name),
 		resourcePath, strings.ToLower(options.Name),
 		resourcePath, strings.ToLower(options.Name),
-		options.Name,
-		strings.ToLower(options.Name), options.Name,
-		strings.ToLower(options.Name))
-}
-
-// getLegacyHandlerTemplate returns the legacy handler template
-func getLegacyHandlerTemplate(name string) string {
-	// This is your existing handler template from templates package
-	return `package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"strconv"
-
-	"github.com/gorilla/mux"
-)
-
-// ` + capitalize(name) + `Handler handles ` + capitalize(name) + ` related requests
-type ` + capitalize(name) + `Handler struct {
-	// Add dependencies here (database, services, etc.)
-}
-
-// New` + capitalize(name) + `Handler creates a new ` + name + ` handler
-func New` + capitalize(name) + `Handler() *` + capitalize(name) + `Handler {
-	return &` + capitalize(name) + `Handler{
-		// Initialize dependencies
-	}
-}
-
-// List` + capitalize(name) + `s handles GET /` + strings.ToLower(name) + `s
-func (h *` + capitalize(name) + `Handler) List` + capitalize(name) + `s(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement list ` + strings.ToLower(name) + `s logic
-	` + strings.ToLower(name) + `s := []map[string]interface{}{
-		{
-			"id":   1,
-			"name": "Example ` + capitalize(name) + `",
-		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"data": ` + strings.ToLower(name) + `s,
-	})
-}
-
-// Get` + capitalize(name) + ` handles GET /` + strings.ToLower(name) + `s/{id}
-func (h *` + capitalize(name) + `Handler) Get` + capitalize(name) + `(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
-	}
-
-	// TODO: Implement get ` + strings.ToLower(name) + ` logic
-	` + strings.ToLower(name) + ` := map[string]interface{}{
-		"id":   id,
-		"name": "Example ` + capitalize(name) + `",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"data": ` + strings.ToLower(name) + `,
-	})
-}
-
-// Create` + capitalize(name) + ` handles POST /` + strings.ToLower(name) + `s
-func (h *` + capitalize(name) + `Handler) Create` + capitalize(name) + `(w http.ResponseWriter, r *http.Request) {
-	var req map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	// TODO: Implement create ` + strings.ToLower(name) + ` logic
-	` + strings.ToLower(name) + ` := map[string]interface{}{
-		"id":   1,
-		"name": req["name"],
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"data": ` + strings.ToLower(name) + `,
-	})
-}
-
-// Update` + capitalize(name) + ` handles PUT /` + strings.ToLower(name) + `s/{id}
-func (h *` + capitalize(name) + `Handler) Update` + capitalize(name) + `(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
-	}
-
-	var req map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	// TODO: Implement update ` + strings.ToLower(name) + ` logic
-	` + strings.ToLower(name) + ` := map[string]interface{}{
-		"id":   id,
-		"name": req["name"],
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"data": ` + strings.ToLower(name) + `,
-	})
-}
-
-// Delete` + capitalize(name) + ` handles DELETE /` + strings.ToLower(name) + `s/{id}
-func (h *` + capitalize(name) + `Handler) Delete` + capitalize(name) + `(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
-	}
-
-	// TODO: Implement delete ` + strings.ToLower(name) + ` logic
-	w.WriteHeader(http.StatusNoContent)
-}
-
-// RegisterRoutes registers all ` + strings.ToLower(name) + ` routes
-func (h *` + capitalize(name) + `Handler) RegisterRoutes(r *mux.Router) {
-	` + strings.ToLower(name) + `Router := r.PathPrefix("/` + strings.ToLower(name) + `s").Subrouter()
-	
-	` + strings.ToLower(name) + `Router.HandleFunc("", h.List` + capitalize(name) + `s).Methods("GET")
-	` + strings.ToLower(name) + `Router.HandleFunc("", h.Create` + capitalize(name) + `).Methods("POST")
-	` + strings.ToLower(name) + `Router.HandleFunc("/{id}", h.Get` + capitalize(name) + `).Methods("GET")
-	` + strings.ToLower(name) + `Router.HandleFunc("/{id}", h.Update` + capitalize(name) + `).Methods("PUT")
-	` + strings.ToLower(name) + `Router.HandleFunc("/{id}", h.Delete` + capitalize(name) + `).Methods("DELETE")
-}
-`
+		typeName,
+		handlerVar, typeName,
+		routes.DetectRouter(".").Mount("api", "/"+resourcePath, handlerVar))
 }
 
 // capitalize helper function
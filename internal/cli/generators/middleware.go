@@ -4,23 +4,28 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/diff"
 	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/middleware"
+	"github.com/shapestone/foundry/internal/routes"
 )
 
 // MiddlewareGenerator handles middleware file generation
 type MiddlewareGenerator struct {
 	stdout io.Writer
-	stderr io.Writer
+	logger *slog.Logger
 }
 
 // NewMiddlewareGenerator creates a new middleware generator
-func NewMiddlewareGenerator(stdout, stderr io.Writer) *MiddlewareGenerator {
+func NewMiddlewareGenerator(stdout io.Writer, logger *slog.Logger) *MiddlewareGenerator {
 	return &MiddlewareGenerator{
 		stdout: stdout,
-		stderr: stderr,
+		logger: logger,
 	}
 }
 
@@ -29,6 +34,11 @@ type MiddlewareOptions struct {
 	Type      string
 	AutoWire  bool
 	OutputDir string
+
+	// Diff controls how the changes to existing files are shown
+	Diff diff.Options
+	// Conflicts decides what happens to a middleware file that already exists
+	Conflicts *conflict.Resolver
 }
 
 // Generate creates middleware files based on options
@@ -36,14 +46,14 @@ func (g *MiddlewareGenerator) Generate(options MiddlewareOptions) error {
 	// Detect current layout
 	layoutName, err := detectProjectLayout()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: could not detect project layout, using standard: %v\n", err)
+		g.logger.Warn("could not detect project layout, using standard", "error", err)
 		layoutName = "standard"
 	}
 
 	// Get layout manager
 	manager, err := g.getLayoutManager()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: layout manager unavailable, falling back to legacy generation: %v\n", err)
+		g.logger.Warn("layout manager unavailable, falling back to legacy generation", "error", err)
 		return g.generateLegacyMiddleware(options)
 	}
 
@@ -51,7 +61,8 @@ func (g *MiddlewareGenerator) Generate(options MiddlewareOptions) error {
 	fmt.Fprintf(g.stdout, "🔨 Generating middleware using '%s' layout...\n", layoutName)
 
 	ctx := context.Background()
-	err = manager.GenerateComponent(ctx, layoutName, "middleware", options.Type, ".")
+	err = manager.GenerateComponent(ctx, layoutName, "middleware", options.Type, ".",
+		layout.WithComponentConflicts(options.Conflicts))
 	if err != nil {
 		return fmt.Errorf("failed to generate middleware using layout system: %w", err)
 	}
@@ -59,8 +70,8 @@ func (g *MiddlewareGenerator) Generate(options MiddlewareOptions) error {
 	// Handle auto-wiring
 	if options.AutoWire {
 		fmt.Fprintln(g.stdout, "\n🔄 Auto-wiring middleware...")
-		if err := g.wireMiddleware(options.Type); err != nil {
-			fmt.Fprintf(g.stderr, "❌ Error auto-wiring middleware: %v\n", err)
+		if err := g.wireMiddleware(options.Type, options.Diff); err != nil {
+			g.logger.Error("auto-wiring middleware failed", "error", err)
 			fmt.Fprintln(g.stdout, "💡 Your middleware was created but you'll need to manually wire it up")
 			fmt.Fprintf(g.stdout, "   You can try: foundry wire middleware %s\n", options.Type)
 			g.showSuccess(options, getMiddlewareInfo(options.Type), false)
@@ -80,15 +91,15 @@ func (g *MiddlewareGenerator) generateLegacyMiddleware(options MiddlewareOptions
 
 	// Create middleware file using legacy template
 	middlewarePath := filepath.Join(options.OutputDir, fmt.Sprintf("%s.go", options.Type))
-	if err := g.createLegacyMiddlewareFile(middlewarePath, options.Type); err != nil {
+	if err := g.createLegacyMiddlewareFile(middlewarePath, options.Type, options.Conflicts); err != nil {
 		return fmt.Errorf("failed to create middleware file: %w", err)
 	}
 
 	// Handle auto-wiring
 	if options.AutoWire {
 		fmt.Fprintln(g.stdout, "\n🔄 Auto-wiring middleware...")
-		if err := g.wireMiddleware(options.Type); err != nil {
-			fmt.Fprintf(g.stderr, "❌ Error auto-wiring middleware: %v\n", err)
+		if err := g.wireMiddleware(options.Type, options.Diff); err != nil {
+			g.logger.Error("auto-wiring middleware failed", "error", err)
 			fmt.Fprintln(g.stdout, "💡 Your middleware was created but you'll need to manually wire it up")
 			fmt.Fprintf(g.stdout, "   You can try: foundry wire middleware %s\n", options.Type)
 			g.showSuccess(options, getMiddlewareInfo(options.Type), false)
@@ -103,9 +114,9 @@ func (g *MiddlewareGenerator) generateLegacyMiddleware(options MiddlewareOptions
 }
 
 // createLegacyMiddlewareFile creates a middleware file using legacy templates
-func (g *MiddlewareGenerator) createLegacyMiddlewareFile(middlewarePath, middlewareType string) error {
+func (g *MiddlewareGenerator) createLegacyMiddlewareFile(middlewarePath, middlewareType string, conflicts *conflict.Resolver) error {
 	template := getLegacyMiddlewareTemplate(middlewareType)
-	return writeFile(middlewarePath, template)
+	return writeGenerated(middlewarePath, template, conflicts)
 }
 
 // getLayoutManager gets the layout manager instance
@@ -116,15 +127,32 @@ func (g *MiddlewareGenerator) getLayoutManager() (*layout.Manager, error) {
 	}
 
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
-	return layout.NewManager(configPath)
+	return layout.NewManager(configPath, layout.WithLogger(g.logger))
 }
 
 // wireMiddleware attempts to auto-wire middleware into the application
-func (g *MiddlewareGenerator) wireMiddleware(middlewareType string) error {
-	// TODO: Implement auto-wiring logic
-	// For now, return a placeholder implementation
-	fmt.Fprintf(g.stdout, "⚠️  Auto-wiring not yet implemented - use manual instructions\n")
-	return fmt.Errorf("auto-wiring not implemented")
+func (g *MiddlewareGenerator) wireMiddleware(middlewareType string, options diff.Options) error {
+	wirer := middleware.NewAutoWirer(".", middleware.WithLogger(g.logger))
+
+	// Calculate the required changes
+	update, err := wirer.PlanMiddleware(middlewareType)
+	if err != nil {
+		return fmt.Errorf("calculating middleware wiring: %w", err)
+	}
+
+	// Show what changes will be made
+	fmt.Fprintf(g.stdout, "📝 Updating %s\n", update.Path)
+	for _, change := range update.Changes {
+		fmt.Fprintf(g.stdout, "  - %s\n", change)
+	}
+	fmt.Fprint(g.stdout, options.Render(update.Diff()))
+
+	// Apply the changes
+	if err := routes.ApplyUpdate(update, routes.NewFileGenerator()); err != nil {
+		return fmt.Errorf("applying middleware wiring: %w", err)
+	}
+
+	return nil
 }
 
 // showSuccess displays success message with instructions
@@ -3,25 +3,30 @@ package generators
 import (
 	"context"
 	"fmt"
+	"go/format"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/shapestone/foundry/internal/conflict"
 	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/scaffolder"
 )
 
 // ModelGenerator handles model file generation
 type ModelGenerator struct {
 	stdout io.Writer
-	stderr io.Writer
+	logger *slog.Logger
 }
 
 // NewModelGenerator creates a new model generator
-func NewModelGenerator(stdout, stderr io.Writer) *ModelGenerator {
+func NewModelGenerator(stdout io.Writer, logger *slog.Logger) *ModelGenerator {
 	return &ModelGenerator{
 		stdout: stdout,
-		stderr: stderr,
+		logger: logger,
 	}
 }
 
@@ -29,6 +34,10 @@ func NewModelGenerator(stdout, stderr io.Writer) *ModelGenerator {
 type ModelOptions struct {
 	Name      string
 	OutputDir string
+	Fields    []scaffolder.FieldSpec
+
+	// Conflicts decides what happens to a model file that already exists
+	Conflicts *conflict.Resolver
 }
 
 // Generate creates model files based on options
@@ -36,14 +45,14 @@ func (g *ModelGenerator) Generate(options ModelOptions) error {
 	// Detect current layout
 	layoutName, err := detectProjectLayout()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: could not detect project layout, using standard: %v\n", err)
+		g.logger.Warn("could not detect project layout, using standard", "error", err)
 		layoutName = "standard"
 	}
 
 	// Get layout manager
 	manager, err := g.getLayoutManager()
 	if err != nil {
-		fmt.Fprintf(g.stderr, "Warning: layout manager unavailable, falling back to legacy generation: %v\n", err)
+		g.logger.Warn("layout manager unavailable, falling back to legacy generation", "error", err)
 		return g.generateLegacyModel(options)
 	}
 
@@ -51,7 +60,12 @@ func (g *ModelGenerator) Generate(options ModelOptions) error {
 	fmt.Fprintf(g.stdout, "🔨 Generating model using '%s' layout...\n", layoutName)
 
 	ctx := context.Background()
-	err = manager.GenerateComponent(ctx, layoutName, "model", options.Name, ".")
+	fields := newModelFields(options.Fields)
+	err = manager.GenerateComponent(ctx, layoutName, "model", options.Name, ".",
+		layout.WithComponentConflicts(options.Conflicts),
+		layout.WithComponentData(map[string]interface{}{
+			"Fields": fields,
+		}))
 	if err != nil {
 		return fmt.Errorf("failed to generate model using layout system: %w", err)
 	}
@@ -67,7 +81,7 @@ func (g *ModelGenerator) generateLegacyModel(options ModelOptions) error {
 
 	// Create model file using legacy template
 	modelPath := filepath.Join(options.OutputDir, fmt.Sprintf("%s.go", strings.ToLower(options.Name)))
-	if err := g.createLegacyModelFile(modelPath, options.Name); err != nil {
+	if err := g.createLegacyModelFile(modelPath, options.Name, newModelFields(options.Fields), options.Conflicts); err != nil {
 		return fmt.Errorf("failed to create model file: %w", err)
 	}
 
@@ -77,9 +91,12 @@ func (g *ModelGenerator) generateLegacyModel(options ModelOptions) error {
 }
 
 // createLegacyModelFile creates a model file using legacy templates
-func (g *ModelGenerator) createLegacyModelFile(modelPath, name string) error {
-	template := getLegacyModelTemplate(name)
-	return writeFile(modelPath, template)
+func (g *ModelGenerator) createLegacyModelFile(modelPath, name string, fields []modelField, conflicts *conflict.Resolver) error {
+	template := getLegacyModelTemplate(name, fields)
+	if formatted, err := format.Source([]byte(template)); err == nil {
+		template = string(formatted)
+	}
+	return writeGenerated(modelPath, template, conflicts)
 }
 
 // getLayoutManager gets the layout manager instance
@@ -90,14 +107,18 @@ func (g *ModelGenerator) getLayoutManager() (*layout.Manager, error) {
 	}
 
 	configPath := filepath.Join(homeDir, ".foundry", "layouts.yaml")
-	return layout.NewManager(configPath)
+	return layout.NewManager(configPath, layout.WithLogger(g.logger))
 }
 
 // showSuccess displays success message with instructions
 func (g *ModelGenerator) showSuccess(options ModelOptions) {
 	modelPath := filepath.Join(options.OutputDir, fmt.Sprintf("%s.go", strings.ToLower(options.Name)))
-	fields := getModelFields(strings.ToLower(options.Name))
-	fieldsList := g.getFieldsList(fields)
+	fieldsList := g.getFieldsList(getModelFields(strings.ToLower(options.Name)))
+	addFieldsStep := "  - Add your specific fields to the model\n"
+	if len(options.Fields) > 0 {
+		fieldsList = g.getSpecFieldsList(newModelFields(options.Fields))
+		addFieldsStep = ""
+	}
 
 	fmt.Fprintf(g.stdout, `✅ Model created successfully!
 
@@ -114,8 +135,7 @@ func (g *ModelGenerator) showSuccess(options ModelOptions) {
   - Helper functions
 
 💡 Next steps:
-  - Add your specific fields to the model
-  - Implement validation logic
+%s  - Implement validation logic
   - Use in your handlers and services:
     
     import "%s/internal/models"
@@ -129,7 +149,7 @@ func (g *ModelGenerator) showSuccess(options ModelOptions) {
   foundry add handler %s     # Create HTTP handler
   foundry add service %s     # Create business logic layer
   foundry add repository %s  # Create data access layer
-`, modelPath, fieldsList, getCurrentModule(), strings.ToLower(options.Name),
+`, modelPath, fieldsList, addFieldsStep, getCurrentModule(), strings.ToLower(options.Name),
 		capitalize(options.Name), strings.ToLower(options.Name),
 		strings.ToLower(options.Name), strings.ToLower(options.Name), strings.ToLower(options.Name))
 }
@@ -155,6 +175,26 @@ func (g *ModelGenerator) getFieldsList(fields ModelFields) string {
 	return strings.Join(fieldsList, "\n  ")
 }
 
+// getSpecFieldsList returns a formatted list of fields given on the command line
+func (g *ModelGenerator) getSpecFieldsList(fields []modelField) string {
+	var fieldsList []string
+	for _, field := range fields {
+		var modifiers []string
+		if field.Required {
+			modifiers = append(modifiers, "required")
+		}
+		if field.Unique {
+			modifiers = append(modifiers, "unique")
+		}
+		line := fmt.Sprintf("- %s field (%s)", field.Name, field.Type)
+		if len(modifiers) > 0 {
+			line += ", " + strings.Join(modifiers, ", ")
+		}
+		fieldsList = append(fieldsList, line)
+	}
+	return strings.Join(fieldsList, "\n  ")
+}
+
 // ModelFields represents which fields to include in a model
 type ModelFields struct {
 	IncludeNameField        bool
@@ -173,29 +213,144 @@ func getModelFields(name string) ModelFields {
 	}
 }
 
+// modelField is the template view of a model field given on the command line
+type modelField struct {
+	Name     string // Go field name
+	Type     string
+	Key      string // json and db key
+	Tag      string // struct tag without backquotes
+	Required bool
+	Unique   bool
+}
+
+// EmptyCheck returns a condition on recv that is true when the field holds
+// its zero value, or an empty string if the type has no meaningful check
+func (f modelField) EmptyCheck(recv string) string {
+	ref := recv + "." + f.Name
+	switch {
+	case f.Type == "string":
+		return ref + ` == ""`
+	case strings.HasPrefix(f.Type, "[]"), strings.HasPrefix(f.Type, "map["):
+		return "len(" + ref + ") == 0"
+	case strings.HasPrefix(f.Type, "*"), f.Type == "any", f.Type == "interface{}":
+		return ref + " == nil"
+	case f.Type == "time.Time":
+		return ref + ".IsZero()"
+	case f.Type == "bool":
+		return ""
+	case strings.HasPrefix(f.Type, "int"), strings.HasPrefix(f.Type, "uint"),
+		strings.HasPrefix(f.Type, "float"), f.Type == "byte", f.Type == "rune":
+		return ref + " == 0"
+	}
+	return ""
+}
+
+// UpdateType returns the type used for the field in update requests, where
+// nil means "leave unchanged"
+func (f modelField) UpdateType() string {
+	if strings.HasPrefix(f.Type, "*") {
+		return f.Type
+	}
+	return "*" + f.Type
+}
+
+// newModelFields converts field specs into template views
+func newModelFields(specs []scaffolder.FieldSpec) []modelField {
+	fields := make([]modelField, 0, len(specs))
+	for _, spec := range specs {
+		key := spec.Tags["json"]
+		if key == "" {
+			key = toSnakeCase(spec.Name)
+		}
+
+		// Emit tags in a stable order: json, db, validate, then the rest
+		order := []string{"json", "db", "validate"}
+		var extra []string
+		for name := range spec.Tags {
+			if name != "json" && name != "db" && name != "validate" {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+
+		var tags []string
+		for _, name := range append(order, extra...) {
+			if value, ok := spec.Tags[name]; ok && value != "" {
+				tags = append(tags, fmt.Sprintf("%s:%q", name, value))
+			}
+		}
+
+		fields = append(fields, modelField{
+			Name:     goFieldName(spec.Name),
+			Type:     spec.Type,
+			Key:      key,
+			Tag:      strings.Join(tags, " "),
+			Required: spec.Required,
+			Unique:   spec.Unique,
+		})
+	}
+	return fields
+}
+
+// commonInitialisms are written in upper case in Go field names
+var commonInitialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "sku": true,
+	"sql": true, "uri": true, "url": true, "uuid": true, "http": true,
+}
+
+// goFieldName converts a field name such as "unit_price" or "sku" to an
+// exported Go name ("UnitPrice", "SKU")
+func goFieldName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(toSnakeCase(name), "_") {
+		if part == "" {
+			continue
+		}
+		if commonInitialisms[part] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
 // getLegacyModelTemplate returns the legacy model template
-func getLegacyModelTemplate(name string) string {
+func getLegacyModelTemplate(name string, specFields []modelField) string {
 	nameField := ""
 	emailField := ""
 	titleField := ""
 	descriptionField := ""
 
-	fields := getModelFields(strings.ToLower(name))
+	nameInitial := strings.ToLower(string(name[0]))
 
-	if fields.IncludeNameField {
-		nameField = "\tName      string    `json:\"name\" db:\"name\"`\n"
+	// Fields given on the command line replace the guessed defaults
+	var specStruct, specValidate, specToMap, specFromMap strings.Builder
+	if len(specFields) > 0 {
+		for _, f := range specFields {
+			fmt.Fprintf(&specStruct, "\t%s %s `%s`\n", f.Name, f.Type, f.Tag)
+			if check := f.EmptyCheck(nameInitial); f.Required && check != "" {
+				fmt.Fprintf(&specValidate, "\tif %s {\n\t\treturn fmt.Errorf(\"%s is required\")\n\t}\n\n", check, f.Key)
+			}
+			fmt.Fprintf(&specToMap, "\t\t%q: %s.%s,\n", f.Key, nameInitial, f.Name)
+			fmt.Fprintf(&specFromMap, "\tif v, ok := data[%q].(%s); ok {\n\t\t%s.%s = v\n\t}\n\n", f.Key, f.Type, nameInitial, f.Name)
+		}
+	} else {
+		fields := getModelFields(strings.ToLower(name))
+
+		if fields.IncludeNameField {
+			nameField = "\tName      string    `json:\"name\" db:\"name\"`\n"
+		}
+		if fields.IncludeEmailField {
+			emailField = "\tEmail     string    `json:\"email\" db:\"email\"`\n"
+		}
+		if fields.IncludeTitleField {
+			titleField = "\tTitle     string    `json:\"title\" db:\"title\"`\n"
+		}
+		if fields.IncludeDescriptionField {
+			descriptionField = "\tDescription string  `json:\"description\" db:\"description\"`\n"
+		}
 	}
-	if fields.IncludeEmailField {
-		emailField = "\tEmail     string    `json:\"email\" db:\"email\"`\n"
-	}
-	if fields.IncludeTitleField {
-		titleField = "\tTitle     string    `json:\"title\" db:\"title\"`\n"
-	}
-	if fields.IncludeDescriptionField {
-		descriptionField = "\tDescription string  `json:\"description\" db:\"description\"`\n"
-	}
-
-	nameInitial := strings.ToLower(string(name[0]))
 
 	return `package models
 
@@ -207,7 +362,7 @@ import (
 // ` + capitalize(name) + ` represents a ` + strings.ToLower(name) + ` in the system
 type ` + capitalize(name) + ` struct {
 	ID        int64     ` + "`json:\"id\" db:\"id\"`" + `
-` + nameField + emailField + titleField + descriptionField + `	CreatedAt time.Time ` + "`json:\"created_at\" db:\"created_at\"`" + `
+` + nameField + emailField + titleField + descriptionField + specStruct.String() + `	CreatedAt time.Time ` + "`json:\"created_at\" db:\"created_at\"`" + `
 	UpdatedAt time.Time ` + "`json:\"updated_at\" db:\"updated_at\"`" + `
 }
 
@@ -226,7 +381,7 @@ func (` + nameInitial + ` *` + capitalize(name) + `) Validate() error {
 		return fmt.Errorf("invalid ID")
 	}
 
-	return nil
+` + specValidate.String() + `	return nil
 }
 
 // Update updates the ` + strings.ToLower(name) + ` with new data
@@ -239,7 +394,7 @@ func (` + nameInitial + ` *` + capitalize(name) + `) Update(data map[string]inte
 func (` + nameInitial + ` *` + capitalize(name) + `) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"id":         ` + nameInitial + `.ID,
-		"created_at": ` + nameInitial + `.CreatedAt,
+` + specToMap.String() + `		"created_at": ` + nameInitial + `.CreatedAt,
 		"updated_at": ` + nameInitial + `.UpdatedAt,
 	}
 }
@@ -250,7 +405,7 @@ func (` + nameInitial + ` *` + capitalize(name) + `) FromMap(data map[string]int
 		` + nameInitial + `.ID = id
 	}
 
-	if createdAt, ok := data["created_at"].(time.Time); ok {
+` + specFromMap.String() + `	if createdAt, ok := data["created_at"].(time.Time); ok {
 		` + nameInitial + `.CreatedAt = createdAt
 	}
 
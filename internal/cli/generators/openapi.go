@@ -0,0 +1,165 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/openapi"
+	"github.com/shapestone/foundry/internal/routes"
+	"github.com/shapestone/foundry/internal/scaffolder"
+)
+
+// GenerateFromOpenAPI creates a handler for each tag in an OpenAPI document,
+// with request/response structs and routes for every operation, registered
+// with the router the project uses
+func (g *HandlerGenerator) GenerateFromOpenAPI(options HandlerOptions) error {
+	doc, err := openapi.Load(options.OpenAPIFile)
+	if err != nil {
+		return err
+	}
+
+	// Handlers that may be generated again do not count as declaring types
+	regenerated := make(map[string]bool)
+	if options.Conflicts != nil {
+		specs, err := openapi.HandlerSpecs(doc, openapi.Options{Tag: options.Tag, PathPrefix: "/api/v1"})
+		if err != nil {
+			return fmt.Errorf("failed to read operations from %s: %w", options.OpenAPIFile, err)
+		}
+		for _, spec := range specs {
+			regenerated[strings.ToLower(spec.Name)+".go"] = true
+		}
+	}
+
+	existing, err := declaredTypes(options.OutputDir, regenerated)
+	if err != nil {
+		return fmt.Errorf("failed to scan existing handlers: %w", err)
+	}
+
+	specs, err := openapi.HandlerSpecs(doc, openapi.Options{
+		Tag:           options.Tag,
+		PathPrefix:    "/api/v1",
+		ExistingTypes: existing,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read operations from %s: %w", options.OpenAPIFile, err)
+	}
+
+	// Refuse to start if any handler exists so a run never stops half way,
+	// unless the conflict policy decides per file
+	var present []string
+	for _, spec := range specs {
+		handlerPath := filepath.Join(options.OutputDir, strings.ToLower(spec.Name)+".go")
+		if _, err := os.Stat(handlerPath); err == nil {
+			if options.Conflicts == nil {
+				return fmt.Errorf("handler %s already exists", handlerPath)
+			}
+			present = append(present, filepath.ToSlash(handlerPath))
+		}
+	}
+	if len(present) > 0 && options.Conflicts.Policy() == conflict.Fail {
+		return &conflict.ExistsError{Paths: present}
+	}
+
+	router := routes.DetectRouter(".")
+	fmt.Fprintf(g.stdout, "🔨 Generating %d handler(s) from %s for the %s router...\n", len(specs), options.OpenAPIFile, router)
+
+	s := scaffolder.NewScaffolderWithAdapters()
+	module := getCurrentModule()
+	ctx := context.Background()
+
+	for i := range specs {
+		spec := &specs[i]
+		spec.ProjectRoot = "."
+		spec.Module = module
+		spec.AutoWire = options.AutoWire
+		spec.DryRun = options.DryRun
+		spec.Router = router
+		spec.Conflicts = options.Conflicts
+
+		result, err := s.CreateHandler(ctx, spec)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s handler: %w", spec.Name, err)
+		}
+
+		g.showOpenAPIResult(spec, result, options)
+	}
+
+	if options.DryRun {
+		fmt.Fprintln(g.stdout, "\n🔍 Dry run complete - no changes made")
+		return nil
+	}
+
+	fmt.Fprintln(g.stdout, "\n✅ Handlers generated successfully!")
+	fmt.Fprintln(g.stdout, "\n💡 Next steps:")
+	fmt.Fprintln(g.stdout, "  - Implement the TODOs in each generated operation")
+	if !options.AutoWire {
+		fmt.Fprintln(g.stdout, "  - Mount the handlers in internal/routes/routes.go, or rerun with --auto-wire")
+	}
+	return nil
+}
+
+// showOpenAPIResult prints the outcome of generating a single handler. A dry
+// run shows the diff of every file; otherwise only modified files get one.
+func (g *HandlerGenerator) showOpenAPIResult(spec *scaffolder.HandlerSpec, result *scaffolder.Result, options HandlerOptions) {
+	fmt.Fprintf(g.stdout, "\n📁 %s handler (%d operations):\n", spec.Name, len(spec.Operations))
+	for _, change := range result.Changes {
+		fmt.Fprintf(g.stdout, "  %s\n", change)
+	}
+	for _, change := range result.Diffs {
+		if options.DryRun || change.Before != nil {
+			fmt.Fprint(g.stdout, options.Diff.Render(change))
+		}
+	}
+	for _, operation := range spec.Operations {
+		path := spec.BasePath
+		if operation.Path != "/" {
+			path += operation.Path
+		}
+		fmt.Fprintf(g.stdout, "  %-7s /api/v1%s -> %s\n", operation.Method, path, operation.Name)
+	}
+	for _, warning := range result.Warnings {
+		g.logger.Warn(warning)
+		fmt.Fprintf(g.stdout, "💡 Mount it manually: %s\n",
+			spec.Router.Mount("r", spec.BasePath, "handlers.New"+capitalize(spec.Name)+"Handler()"))
+	}
+}
+
+// declaredTypes returns the names of the types declared in the Go files of
+// dir, leaving out the files named in skip
+func declaredTypes(dir string, skip map[string]bool) (map[string]bool, error) {
+	types := make(map[string]bool)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if skip[filepath.Base(path)] {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				types[spec.(*ast.TypeSpec).Name.Name] = true
+			}
+		}
+	}
+
+	return types, nil
+}
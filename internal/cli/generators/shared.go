@@ -4,10 +4,19 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/layout"
+	"github.com/shapestone/foundry/internal/staging"
 )
 
 // writeFile writes content to a file
 func writeFile(path, content string) error {
+	if err := journal.Touch(path); err != nil {
+		return err
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -17,6 +26,24 @@ func writeFile(path, content string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+// writeGenerated writes a generated file, applying the conflict policy if
+// the file already exists. Without a resolver the file is overwritten.
+func writeGenerated(path, content string, conflicts *conflict.Resolver) error {
+	if conflicts == nil {
+		return writeFile(path, content)
+	}
+
+	area := staging.New(".")
+	if err := area.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	conflicts.SetBaseline(layout.Baseline("."))
+	if _, err := conflicts.Resolve(area, []string{path}); err != nil {
+		return err
+	}
+	return area.Commit()
+}
+
 // getCurrentModule gets the current module name from go.mod
 func getCurrentModule() string {
 	data, err := os.ReadFile("go.mod")
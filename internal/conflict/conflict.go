@@ -0,0 +1,268 @@
+// Package conflict decides what happens to generated files that already
+// exist in a project.
+package conflict
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/staging"
+)
+
+// Policy decides what happens when a generated file already exists with
+// different content
+type Policy string
+
+const (
+	// Fail stops before anything is written
+	Fail Policy = "fail"
+	// Skip keeps the existing file
+	Skip Policy = "skip"
+	// Overwrite replaces the existing file
+	Overwrite Policy = "overwrite"
+	// Merge merges the generated file into the existing one three ways,
+	// against the output the file was generated with
+	Merge Policy = "merge"
+	// Prompt shows the diff and asks for each file
+	Prompt Policy = "prompt"
+)
+
+// Policies lists the policies in the order they are offered
+var Policies = []Policy{Fail, Skip, Overwrite, Merge, Prompt}
+
+// ParsePolicy returns the policy with the given name
+func ParsePolicy(name string) (Policy, error) {
+	for _, policy := range Policies {
+		if string(policy) == strings.ToLower(strings.TrimSpace(name)) {
+			return policy, nil
+		}
+	}
+	names := make([]string, len(Policies))
+	for i, policy := range Policies {
+		names[i] = string(policy)
+	}
+	return "", fmt.Errorf("unknown conflict policy '%s' (use %s)", name, strings.Join(names, ", "))
+}
+
+// Action is what was done with a generated file
+type Action string
+
+const (
+	// Created files did not exist
+	Created Action = "created"
+	// Unchanged files already had the generated content
+	Unchanged Action = "unchanged"
+	// Overwritten files were replaced by the generated content
+	Overwritten Action = "overwritten"
+	// Skipped files were kept as they were
+	Skipped Action = "skipped"
+	// Merged files got the generated changes merged in
+	Merged Action = "merged"
+	// Conflicted files were merged with conflict markers left to resolve
+	Conflicted Action = "conflicted"
+)
+
+// Decision records what was done with one generated file
+type Decision struct {
+	Path      string `json:"path" yaml:"path"`
+	Action    Action `json:"action" yaml:"action"`
+	Conflicts int    `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+	Reason    string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ExistsError reports generated files that already exist when the policy
+// is Fail
+type ExistsError struct {
+	Paths []string
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("%s already exists (use --on-conflict=skip, overwrite, merge or prompt to choose what happens)",
+		strings.Join(e.Paths, ", "))
+}
+
+// Baseline returns the output a file was generated with, given its path and
+// current content, if it is known
+type Baseline func(path string, current []byte) ([]byte, bool)
+
+// Prompter shows the diff of a conflicting file and asks what to do
+type Prompter interface {
+	ShowDiff(title string, changes []diff.Change)
+	Select(message string, choices []string, defaultChoice string) string
+}
+
+// Resolver applies a policy to generated files before they are written,
+// and keeps the decisions it made
+type Resolver struct {
+	policy    Policy
+	baseline  Baseline
+	prompter  Prompter
+	decisions []Decision
+}
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithBaseline sets where the merge policy finds the generated output
+func WithBaseline(baseline Baseline) Option {
+	return func(r *Resolver) {
+		r.baseline = baseline
+	}
+}
+
+// WithPrompter sets the prompter the prompt policy asks with
+func WithPrompter(prompter Prompter) Option {
+	return func(r *Resolver) {
+		r.prompter = prompter
+	}
+}
+
+// NewResolver creates a resolver for the given policy
+func NewResolver(policy Policy, opts ...Option) *Resolver {
+	r := &Resolver{policy: policy}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Policy returns the policy the resolver applies
+func (r *Resolver) Policy() Policy {
+	return r.policy
+}
+
+// SetBaseline sets where the merge policy finds the generated output, for
+// resolvers created before the project was known
+func (r *Resolver) SetBaseline(baseline Baseline) {
+	r.baseline = baseline
+}
+
+// Decisions returns every decision the resolver has made
+func (r *Resolver) Decisions() []Decision {
+	return r.decisions
+}
+
+// Resolve compares the given staged files with the files in the area's
+// target and applies the policy to those that exist with other content:
+// the staged content is replaced by a merge, or dropped to keep the
+// existing file. Nothing is written. With the Fail policy, or when asked
+// to fail, it returns an *ExistsError and the area is left as it was.
+func (r *Resolver) Resolve(area *staging.Area, paths []string) ([]Decision, error) {
+	var (
+		decisions []Decision
+		existing  []string
+	)
+	for _, path := range paths {
+		generated, _, ok := area.ReadFile(path)
+		if !ok {
+			continue
+		}
+		// Paths that cannot be read, such as those under a file, are left
+		// for the commit to report
+		current, err := os.ReadFile(filepath.Join(area.Target(), path))
+		switch {
+		case err != nil:
+			decisions = append(decisions, Decision{Path: filepath.ToSlash(path), Action: Created})
+			continue
+		case string(current) == string(generated):
+			decisions = append(decisions, Decision{Path: filepath.ToSlash(path), Action: Unchanged})
+			continue
+		}
+		existing = append(existing, path)
+	}
+
+	if r.policy == Fail && len(existing) > 0 {
+		return nil, r.exists(existing...)
+	}
+
+	for _, path := range existing {
+		decision, err := r.resolve(area, path)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+	}
+
+	r.decisions = append(r.decisions, decisions...)
+	return decisions, nil
+}
+
+// resolve applies the policy to one staged file that exists with other
+// content
+func (r *Resolver) resolve(area *staging.Area, path string) (Decision, error) {
+	decision := Decision{Path: filepath.ToSlash(path)}
+	generated, mode, _ := area.ReadFile(path)
+	current, err := os.ReadFile(filepath.Join(area.Target(), path))
+	if err != nil {
+		return decision, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	policy := r.policy
+	if policy == Prompt {
+		policy = r.ask(path, current, generated)
+	}
+
+	switch policy {
+	case Skip:
+		area.Remove(path)
+		decision.Action = Skipped
+	case Overwrite:
+		decision.Action = Overwritten
+	case Merge:
+		base, ok := r.base(path, current)
+		merged := diff.Merge3(string(base), string(current), string(generated), "current", "generated")
+		decision.Action, decision.Conflicts = Merged, merged.Conflicts
+		if !ok {
+			decision.Reason = "no generated output recorded"
+		}
+		if merged.Conflicts > 0 {
+			decision.Action = Conflicted
+			area.Unchecked(path)
+		}
+		if err := area.WriteFile(path, []byte(merged.Text), mode); err != nil {
+			return decision, err
+		}
+	default:
+		return decision, r.exists(path)
+	}
+	return decision, nil
+}
+
+// ask shows the diff from the existing file to the generated one and asks
+// which policy to apply. Without a prompter, or without an answer, the
+// file is skipped.
+func (r *Resolver) ask(path string, current, generated []byte) Policy {
+	if r.prompter == nil {
+		return Skip
+	}
+
+	choices := []string{string(Skip), string(Overwrite)}
+	if _, ok := r.base(path, current); ok {
+		choices = append(choices, string(Merge))
+	}
+	choices = append(choices, string(Fail))
+
+	change := diff.Change{Path: filepath.ToSlash(path), Before: current, After: generated}
+	r.prompter.ShowDiff(fmt.Sprintf("%s already exists; the generated file differs:", change.Path), []diff.Change{change})
+	return Policy(r.prompter.Select(fmt.Sprintf("What should happen to %s?", change.Path), choices, string(Skip)))
+}
+
+// base returns the output a file was generated with
+func (r *Resolver) base(path string, current []byte) ([]byte, bool) {
+	if r.baseline == nil {
+		return nil, false
+	}
+	return r.baseline(filepath.ToSlash(path), current)
+}
+
+// exists returns the error for files that must not be replaced
+func (r *Resolver) exists(paths ...string) error {
+	slashed := make([]string, len(paths))
+	for i, path := range paths {
+		slashed[i] = filepath.ToSlash(path)
+	}
+	return &ExistsError{Paths: slashed}
+}
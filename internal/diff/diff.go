@@ -0,0 +1,112 @@
+// Package diff compares and merges text line by line.
+package diff
+
+import "strings"
+
+// SplitLines splits text into lines, keeping the line endings so that
+// joining the lines gives the text back
+func SplitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Match pairs the lines of a with the lines of b along a shortest edit
+// script, found with Myers' algorithm. The result holds, for every line of a,
+// the index of the matching line of b, or -1 when the line was removed.
+func Match(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// Lines shared at the start and end need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		match[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	for x, y := range myers(midA, midB) {
+		if y >= 0 {
+			match[prefix+x] = prefix + y
+		}
+	}
+	return match
+}
+
+// myers returns the matching line of b for every line of a along a shortest
+// edit script, or -1
+func myers(a, b []string) []int {
+	n, m := len(a), len(b)
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+	if n == 0 || m == 0 {
+		return match
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k = x - y; trace
+	// keeps v as it was before each step d, for diagonals -d..d
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	steps := 0
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				steps = d
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end, matching the lines along each diagonal run
+	x, y := n, m
+	for d := steps; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			match[x] = y
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		match[x] = y
+	}
+	return match
+}
@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	Text      string
+	Conflicts int
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs.
+// Regions changed the same way on both sides, or on one side only, merge
+// cleanly; regions changed differently on both sides are kept with both
+// versions between conflict markers labelled with oursLabel and theirsLabel.
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) MergeResult {
+	o, a, b := SplitLines(base), SplitLines(ours), SplitLines(theirs)
+	matchA, matchB := Match(o, a), Match(o, b)
+
+	var (
+		out       strings.Builder
+		conflicts int
+	)
+	emit := func(lines []string) {
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+	}
+	// resolve writes a region in which the sides are not in step
+	resolve := func(base, ours, theirs []string) {
+		switch {
+		case slices.Equal(ours, theirs), slices.Equal(base, theirs):
+			emit(ours)
+		case slices.Equal(base, ours):
+			emit(theirs)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			emit(terminated(ours))
+			out.WriteString("=======\n")
+			emit(terminated(theirs))
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+	}
+
+	i, j, k := 0, 0, 0
+	for i < len(o) {
+		// A base line kept in place on both sides
+		if matchA[i] == j && matchB[i] == k {
+			out.WriteString(o[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Otherwise the region runs up to the next base line both sides kept
+		next := i
+		for next < len(o) && (matchA[next] < 0 || matchB[next] < 0) {
+			next++
+		}
+		if next == len(o) {
+			break
+		}
+		resolve(o[i:next], a[j:matchA[next]], b[k:matchB[next]])
+		i, j, k = next, matchA[next], matchB[next]
+	}
+	if i < len(o) || j < len(a) || k < len(b) {
+		resolve(o[i:], a[j:], b[k:])
+	}
+
+	return MergeResult{Text: out.String(), Conflicts: conflicts}
+}
+
+// terminated returns lines whose last line ends with a newline, so that a
+// conflict marker after them starts on a line of its own
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	lines = slices.Clone(lines)
+	lines[len(lines)-1] += "\n"
+	return lines
+}
@@ -0,0 +1,175 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// op is one line of an edit script: ' ' kept, '-' removed or '+' added
+type op struct {
+	kind byte
+	line string
+}
+
+// edits returns the edit script that turns a into b
+func edits(a, b []string) []op {
+	match := Match(a, b)
+	ops := make([]op, 0, len(a)+len(b))
+	j := 0
+	for i, line := range a {
+		if match[i] < 0 {
+			ops = append(ops, op{'-', line})
+			continue
+		}
+		for ; j < match[i]; j++ {
+			ops = append(ops, op{'+', b[j]})
+		}
+		ops = append(ops, op{' ', line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// Unified renders the changes from before to after as a unified diff with
+// the given number of context lines. It returns an empty string when the
+// texts are equal.
+func Unified(beforeName, afterName, before, after string, context int) string {
+	if before == after {
+		return ""
+	}
+	ops := edits(SplitLines(before), SplitLines(after))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", beforeName, afterName)
+
+	// Line numbers before each op, in before and after
+	beforeLine, afterLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, o := range ops {
+		beforeLine[i+1], afterLine[i+1] = beforeLine[i], afterLine[i]
+		if o.kind != '+' {
+			beforeLine[i+1]++
+		}
+		if o.kind != '-' {
+			afterLine[i+1]++
+		}
+	}
+
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops) && i <= last+2*context+1; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+
+		from, to := max(first-context, start), min(last+context+1, len(ops))
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(beforeLine[from], beforeLine[to]-beforeLine[from]),
+			hunkRange(afterLine[from], afterLine[to]-afterLine[from]))
+		for _, o := range ops[from:to] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// DefaultContext is the number of unchanged lines shown around changes
+const DefaultContext = 3
+
+// ANSI color codes for rendered diffs
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// Options control how diffs are rendered
+type Options struct {
+	Context int  // unchanged lines shown around changes
+	Color   bool // color the diff for a terminal
+}
+
+// DefaultOptions returns the default context with color
+func DefaultOptions() Options {
+	return Options{Context: DefaultContext, Color: true}
+}
+
+// Change is a file Foundry creates or modifies. Before is nil for a new
+// file and After is nil for a removed one.
+type Change struct {
+	Path   string
+	Before []byte
+	After  []byte
+}
+
+// Render renders a change as a unified diff between a/ and b/ paths, with
+// /dev/null for the missing side of a created or removed file
+func (o Options) Render(change Change) string {
+	beforeName, afterName := "a/"+change.Path, "b/"+change.Path
+	if change.Before == nil {
+		beforeName = "/dev/null"
+	}
+	if change.After == nil {
+		afterName = "/dev/null"
+	}
+
+	text := Unified(beforeName, afterName, string(change.Before), string(change.After), max(o.Context, 0))
+	if o.Color {
+		text = Colorize(text)
+	}
+	return text
+}
+
+// Colorize colors the lines of a unified diff of one file: file headers
+// bold, hunk headers cyan, removed lines red and added lines green
+func Colorize(text string) string {
+	lines := SplitLines(text)
+	inHunk := false
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case !inHunk && (strings.HasPrefix(body, "--- ") || strings.HasPrefix(body, "+++ ")):
+			color = colorBold
+		case strings.HasPrefix(body, "@@"):
+			color, inHunk = colorCyan, true
+		case strings.HasPrefix(body, "-"):
+			color = colorRed
+		case strings.HasPrefix(body, "+"):
+			color = colorGreen
+		}
+		if color != "" {
+			lines[i] = color + body + colorReset + line[len(body):]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// hunkRange formats the start and length of a hunk side
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
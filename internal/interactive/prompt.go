@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/shapestone/foundry/internal/diff"
 )
 
 // ANSI color codes
@@ -24,44 +27,76 @@ const (
 type Prompter interface {
 	Confirm(message string) bool
 	ShowPreview(title string, changes []string, message string) bool
+	ShowChanges(title string, changes []diff.Change, message string) bool
+	ShowDiff(title string, changes []diff.Change)
+	Select(message string, choices []string, defaultChoice string) string
+	MultiSelect(message string, choices []string, defaults []string) []string
+	Input(message, defaultValue string, validate func(string) error) (string, error)
 }
 
 // ConsolePrompter implements Prompter for console interaction
 type ConsolePrompter struct {
-	input  io.Reader
-	output io.Writer
+	input       io.Reader
+	reader      *bufio.Reader
+	output      io.Writer
+	interactive bool
+	diffOptions diff.Options
 }
 
 // NewConsolePrompter creates a new console prompter
 func NewConsolePrompter() *ConsolePrompter {
-	return &ConsolePrompter{
-		input:  os.Stdin,
-		output: os.Stdout,
-	}
+	return NewConsolePrompterWithIO(os.Stdin, os.Stdout)
 }
 
 // NewConsolePrompterWithIO creates a new console prompter with custom input/output
 // This is useful for testing
 func NewConsolePrompterWithIO(input io.Reader, output io.Writer) *ConsolePrompter {
 	return &ConsolePrompter{
-		input:  input,
-		output: output,
+		input:       input,
+		reader:      bufio.NewReader(input),
+		output:      output,
+		interactive: IsTerminal(input),
+		diffOptions: diff.Options{Context: diff.DefaultContext, Color: ColorOutput(output)},
 	}
 }
 
+// Interactive reports whether Select, MultiSelect and Input read answers.
+// When they don't, they return their defaults without prompting.
+func (p *ConsolePrompter) Interactive() bool {
+	return p.interactive
+}
+
+// SetInteractive overrides whether the input is treated as interactive
+func (p *ConsolePrompter) SetInteractive(interactive bool) {
+	p.interactive = interactive
+}
+
+// SetDiffOptions sets how previews render diffs
+func (p *ConsolePrompter) SetDiffOptions(options diff.Options) {
+	p.diffOptions = options
+}
+
+// readLine reads one line of input, reporting false at EOF
+func (p *ConsolePrompter) readLine() (string, bool) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
 // Confirm asks the user for confirmation
 func (p *ConsolePrompter) Confirm(message string) bool {
-	scanner := bufio.NewScanner(p.input)
-
 	for {
 		fmt.Fprintf(p.output, "%s (y/N): ", message)
 
-		if !scanner.Scan() {
+		line, ok := p.readLine()
+		if !ok {
 			// Handle EOF or error
 			return false
 		}
 
-		response := strings.TrimSpace(strings.ToLower(scanner.Text()))
+		response := strings.ToLower(line)
 
 		switch response {
 		case "y", "yes":
@@ -102,9 +137,189 @@ func (p *ConsolePrompter) ShowPreview(title string, changes []string, message st
 	return p.Confirm(fmt.Sprintf("\n❓ %s", message))
 }
 
+// ShowChanges shows unified diffs of the files that will be created or
+// modified and asks for confirmation
+func (p *ConsolePrompter) ShowChanges(title string, changes []diff.Change, message string) bool {
+	p.ShowDiff(title, changes)
+
+	if message == "" {
+		message = "Apply these changes?"
+	}
+
+	return p.Confirm(fmt.Sprintf("\n❓ %s", message))
+}
+
+// ShowDiff shows unified diffs of the files that will be created or
+// modified
+func (p *ConsolePrompter) ShowDiff(title string, changes []diff.Change) {
+	fmt.Fprintf(p.output, "\n📝 %s\n", title)
+	fmt.Fprintf(p.output, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+
+	shown := 0
+	for _, change := range changes {
+		if text := p.diffOptions.Render(change); text != "" {
+			fmt.Fprint(p.output, text)
+			shown++
+		}
+	}
+	if shown == 0 {
+		fmt.Fprintf(p.output, "No changes\n")
+	}
+
+	fmt.Fprintf(p.output, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+}
+
+// Select asks the user to pick one of choices by number or name. An empty
+// answer picks defaultChoice.
+func (p *ConsolePrompter) Select(message string, choices []string, defaultChoice string) string {
+	if !p.interactive || len(choices) == 0 {
+		return defaultChoice
+	}
+
+	fmt.Fprintf(p.output, "\n❓ %s\n", message)
+	for i, choice := range choices {
+		marker := " "
+		if choice == defaultChoice {
+			marker = "*"
+		}
+		fmt.Fprintf(p.output, "  %s %d) %s\n", marker, i+1, choice)
+	}
+
+	for {
+		fmt.Fprintf(p.output, "Choose 1-%d [%s]: ", len(choices), defaultChoice)
+
+		line, ok := p.readLine()
+		if !ok || line == "" {
+			return defaultChoice
+		}
+
+		if choice, ok := pickChoice(choices, line); ok {
+			return choice
+		}
+		fmt.Fprintf(p.output, "Please enter a number between 1 and %d or one of the names.\n", len(choices))
+	}
+}
+
+// MultiSelect asks the user to pick any number of choices, as a
+// comma-separated list of numbers or names. An empty answer keeps defaults
+// and "none" picks nothing.
+func (p *ConsolePrompter) MultiSelect(message string, choices []string, defaults []string) []string {
+	if !p.interactive || len(choices) == 0 {
+		return defaults
+	}
+
+	selected := make(map[string]bool, len(defaults))
+	for _, choice := range defaults {
+		selected[choice] = true
+	}
+
+	fmt.Fprintf(p.output, "\n❓ %s\n", message)
+	for i, choice := range choices {
+		box := "[ ]"
+		if selected[choice] {
+			box = "[x]"
+		}
+		fmt.Fprintf(p.output, "  %s %d) %s\n", box, i+1, choice)
+	}
+
+	for {
+		fmt.Fprintf(p.output, "Choose numbers or names, comma-separated, or 'none' [keep]: ")
+
+		line, ok := p.readLine()
+		if !ok || line == "" {
+			return defaults
+		}
+		if strings.EqualFold(line, "none") {
+			return []string{}
+		}
+
+		picked := make(map[string]bool)
+		valid := true
+		for _, answer := range strings.Split(line, ",") {
+			choice, ok := pickChoice(choices, strings.TrimSpace(answer))
+			if !ok {
+				fmt.Fprintf(p.output, "Unknown choice '%s'.\n", strings.TrimSpace(answer))
+				valid = false
+				break
+			}
+			picked[choice] = true
+		}
+		if !valid {
+			continue
+		}
+
+		// Keep the order of choices
+		result := make([]string, 0, len(picked))
+		for _, choice := range choices {
+			if picked[choice] {
+				result = append(result, choice)
+			}
+		}
+		return result
+	}
+}
+
+// Input asks the user for a value, repeating the question until validate
+// accepts the answer. An empty answer is defaultValue. Without interactive
+// input the default is returned, or the error validate reports for it.
+func (p *ConsolePrompter) Input(message, defaultValue string, validate func(string) error) (string, error) {
+	check := func(value string) error {
+		if validate == nil {
+			return nil
+		}
+		return validate(value)
+	}
+
+	if !p.interactive {
+		return defaultValue, check(defaultValue)
+	}
+
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(p.output, "❓ %s [%s]: ", message, defaultValue)
+		} else {
+			fmt.Fprintf(p.output, "❓ %s: ", message)
+		}
+
+		line, ok := p.readLine()
+		if !ok {
+			return defaultValue, check(defaultValue)
+		}
+		if line == "" {
+			line = defaultValue
+		}
+
+		if err := check(line); err != nil {
+			fmt.Fprintf(p.output, "⚠️  %v\n", err)
+			continue
+		}
+		return line, nil
+	}
+}
+
+// pickChoice finds the choice an answer names, by its 1-based number or by
+// its name
+func pickChoice(choices []string, answer string) (string, bool) {
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n >= 1 && n <= len(choices) {
+			return choices[n-1], true
+		}
+		return "", false
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(choice, answer) {
+			return choice, true
+		}
+	}
+	return "", false
+}
+
 // colorizeChange applies color formatting to changes based on their prefix
 func (p *ConsolePrompter) colorizeChange(change string) string {
 	change = strings.TrimSpace(change)
+	if !p.diffOptions.Color {
+		return change
+	}
 
 	if strings.HasPrefix(change, "+") {
 		// Green for additions
@@ -121,6 +336,37 @@ func (p *ConsolePrompter) colorizeChange(change string) string {
 	return change
 }
 
+// IsTerminal reports whether input comes from a terminal. Readers that are
+// not files, such as the ones tests inject, count as terminals; the null
+// device does not, although it is a character device too.
+func IsTerminal(input io.Reader) bool {
+	file, ok := input.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := file.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// ColorOutput reports whether output goes to a terminal that should get
+// colors. Setting NO_COLOR turns colors off.
+func ColorOutput(output io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	file, ok := output.(*os.File)
+	if !ok {
+		return false
+	}
+	return IsTerminal(file)
+}
+
 // IsColorSupported checks if the current terminal supports color output
 func IsColorSupported() bool {
 	term := os.Getenv("TERM")
@@ -0,0 +1,509 @@
+// Package journal records the files each Foundry command changes in a
+// project, with their content before and after, so that the last operation
+// can be undone.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir holds the journal, relative to the project root. Every operation gets
+// a numbered directory with an entry file and the content of its files
+// before and after it ran.
+const Dir = ".foundry/journal"
+
+// entryFileName is the entry file in the directory of an operation
+const entryFileName = "entry.yaml"
+
+// skipDirs are the directories whose files TouchTree leaves out
+var skipDirs = map[string]bool{
+	".git":         true,
+	"vendor":       true,
+	"node_modules": true,
+}
+
+// ErrEmpty is returned when the journal has no operations
+var ErrEmpty = errors.New("no operations recorded")
+
+// Action is what an operation did to a file
+type Action string
+
+const (
+	// Created files did not exist before the operation
+	Created Action = "created"
+	// Modified files got new content
+	Modified Action = "modified"
+	// Deleted files were removed
+	Deleted Action = "deleted"
+)
+
+// Entry is one operation in the journal
+type Entry struct {
+	ID      int       `json:"id" yaml:"id"`
+	Command string    `json:"command" yaml:"command"`
+	Time    time.Time `json:"time" yaml:"time"`
+	Files   []File    `json:"files" yaml:"files"`
+	Dirs    []string  `json:"dirs,omitempty" yaml:"dirs,omitempty"`
+}
+
+// File is a file an operation touched, with the checksums of its content
+// before and after the operation
+type File struct {
+	Path   string      `json:"path" yaml:"path"`
+	Action Action      `json:"action" yaml:"action"`
+	Mode   os.FileMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Before string      `json:"before,omitempty" yaml:"before,omitempty"`
+	After  string      `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// Count returns the number of files the operation touched with the action
+func (e *Entry) Count(action Action) int {
+	count := 0
+	for _, file := range e.Files {
+		if file.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary describes the files the operation touched, such as
+// "2 created, 1 modified"
+func (e *Entry) Summary() string {
+	var parts []string
+	for _, action := range []Action{Created, Modified, Deleted} {
+		if count := e.Count(action); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, action))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Operation records the files a command touches in a project while it
+// runs. Code that writes to a project calls Touch before it writes or
+// removes a file, so the content the file had before is kept for undo.
+type Operation struct {
+	root  string
+	abs   string
+	files map[string]*fileState
+	dirs  map[string]bool
+}
+
+// fileState is the content and mode of a file at one moment
+type fileState struct {
+	content []byte
+	mode    os.FileMode
+}
+
+var (
+	activeMu sync.Mutex
+	active   []*Operation
+)
+
+// Begin starts recording the files touched in the project at root, which
+// need not exist yet. End stops it.
+func Begin(root string) (*Operation, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record operation in %s: %w", root, err)
+	}
+	op := &Operation{
+		root:  root,
+		abs:   abs,
+		files: make(map[string]*fileState),
+		dirs:  make(map[string]bool),
+	}
+	activeMu.Lock()
+	active = append(active, op)
+	activeMu.Unlock()
+	return op, nil
+}
+
+// End stops recording the files touched. The files touched so far can
+// still be recorded or compared.
+func (o *Operation) End() {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	for i, op := range active {
+		if op == o {
+			active = append(active[:i], active[i+1:]...)
+			return
+		}
+	}
+}
+
+// Root returns the project root the operation is recorded in
+func (o *Operation) Root() string {
+	return o.root
+}
+
+// Touch tells the operations being recorded that the file at path is about
+// to be written or removed. The content of a file is kept the first time
+// it is touched; a file that cannot be read is an error, so that no change
+// goes unrecorded.
+func Touch(path string) error {
+	return touch(path, false)
+}
+
+// TouchDir tells the operations being recorded that the directory at path
+// is about to be created
+func TouchDir(path string) error {
+	return touch(path, true)
+}
+
+// TouchTree touches every file under dir, for operations that replace a
+// whole directory
+func TouchTree(dir string) error {
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && skipDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return Touch(path)
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// touch records path in every operation being recorded
+func touch(path string, dir bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("cannot record %s for undo: %w", path, err)
+	}
+	activeMu.Lock()
+	ops := append([]*Operation(nil), active...)
+	activeMu.Unlock()
+
+	for _, op := range ops {
+		if err := op.touch(abs, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touch records the file or directory at abs, if it is in the project and
+// not in the journal itself. Directories are recorded when they do not
+// exist yet, with the missing directories above them.
+func (o *Operation) touch(abs string, dir bool) error {
+	rel, err := filepath.Rel(o.abs, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == Dir || strings.HasPrefix(rel, Dir+"/") {
+		return nil
+	}
+
+	parent := path.Dir(rel)
+	if dir {
+		parent = rel
+	}
+	for ; parent != "." && !o.dirs[parent]; parent = path.Dir(parent) {
+		if _, err := os.Stat(filepath.Join(o.abs, filepath.FromSlash(parent))); err == nil {
+			break
+		}
+		o.dirs[parent] = true
+	}
+	if dir {
+		return nil
+	}
+
+	if _, seen := o.files[rel]; seen {
+		return nil
+	}
+	file, err := readFile(abs)
+	if err != nil {
+		return fmt.Errorf("cannot record %s for undo: %w", rel, err)
+	}
+	o.files[rel] = file
+	return nil
+}
+
+// readFile returns the content and mode of the file at path, or nil if
+// there is none
+func readFile(path string) (*fileState, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileState{content: content, mode: info.Mode().Perm()}, nil
+}
+
+// Record appends an entry for the files the operation changed to the
+// journal of its project. It returns nil when nothing changed.
+func Record(o *Operation, command string) (*Entry, error) {
+	entry, after, err := o.compare()
+	if err != nil || len(entry.Files) == 0 {
+		return nil, err
+	}
+	entry.Command = command
+
+	entries, err := List(o.root)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = 1
+	if len(entries) > 0 {
+		entry.ID = entries[0].ID + 1
+	}
+
+	dir := entryDir(o.root, entry.ID)
+	for _, file := range entry.Files {
+		if file.Action != Created {
+			if err := writeContent(filepath.Join(dir, "before", filepath.FromSlash(file.Path)), o.files[file.Path].content); err != nil {
+				return nil, err
+			}
+		}
+		if file.Action != Deleted {
+			if err := writeContent(filepath.Join(dir, "after", filepath.FromSlash(file.Path)), after[file.Path].content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	content, err := yaml.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if err := writeContent(filepath.Join(dir, entryFileName), content); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Compare returns the files the operation changed, without recording them
+func Compare(o *Operation) ([]File, error) {
+	entry, _, err := o.compare()
+	if err != nil {
+		return nil, err
+	}
+	return entry.Files, nil
+}
+
+// compare returns an unsaved entry with the touched files and directories
+// that changed, and the content of the files now
+func (o *Operation) compare() (*Entry, map[string]*fileState, error) {
+	entry := &Entry{Time: time.Now().UTC().Truncate(time.Second)}
+	after := make(map[string]*fileState, len(o.files))
+	for rel, old := range o.files {
+		file, err := readFile(filepath.Join(o.abs, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot record %s for undo: %w", rel, err)
+		}
+		after[rel] = file
+
+		switch {
+		case old == nil && file != nil:
+			entry.Files = append(entry.Files, File{Path: rel, Action: Created, Mode: file.mode, After: checksum(file.content)})
+		case old != nil && file == nil:
+			entry.Files = append(entry.Files, File{Path: rel, Action: Deleted, Mode: old.mode, Before: checksum(old.content)})
+		case old != nil && (string(old.content) != string(file.content) || old.mode != file.mode):
+			entry.Files = append(entry.Files, File{Path: rel, Action: Modified, Mode: old.mode,
+				Before: checksum(old.content), After: checksum(file.content)})
+		}
+	}
+	sort.Slice(entry.Files, func(i, j int) bool { return entry.Files[i].Path < entry.Files[j].Path })
+
+	for dir := range o.dirs {
+		if info, err := os.Stat(filepath.Join(o.abs, filepath.FromSlash(dir))); err == nil && info.IsDir() {
+			entry.Dirs = append(entry.Dirs, dir)
+		}
+	}
+	sort.Strings(entry.Dirs)
+	return entry, after, nil
+}
+
+// List returns the operations in the journal of the project at root,
+// newest first
+func List(root string) ([]*Entry, error) {
+	dirs, err := os.ReadDir(filepath.Join(root, Dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []*Entry
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(dir.Name())
+		if !dir.IsDir() || err != nil {
+			continue
+		}
+		entry, err := load(root, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return entries, nil
+}
+
+// Last returns the newest operation in the journal, or ErrEmpty
+func Last(root string) (*Entry, error) {
+	entries, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmpty
+	}
+	return entries[0], nil
+}
+
+// EditedError reports files that changed after the operation being undone
+type EditedError struct {
+	Entry *Entry
+	Paths []string
+}
+
+func (e *EditedError) Error() string {
+	return fmt.Sprintf("%s changed since '%s' (use --force to undo anyway)",
+		strings.Join(e.Paths, ", "), e.Entry.Command)
+}
+
+// Undo reverts the newest operation in the journal of the project at root
+// and removes it from the journal: created files are removed, and modified
+// and deleted files get their content back. If files changed after the
+// operation, it returns an *EditedError and leaves the project as it is,
+// unless force is set.
+func Undo(root string, force bool) (*Entry, error) {
+	entry, err := Last(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		var edited []string
+		for _, file := range entry.Files {
+			if !matches(filepath.Join(root, file.Path), file.After) {
+				edited = append(edited, file.Path)
+			}
+		}
+		if len(edited) > 0 {
+			return entry, &EditedError{Entry: entry, Paths: edited}
+		}
+	}
+
+	dir := entryDir(root, entry.ID)
+	for _, file := range entry.Files {
+		path := filepath.Join(root, filepath.FromSlash(file.Path))
+		if err := Touch(path); err != nil {
+			return entry, err
+		}
+		if file.Action == Created {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return entry, fmt.Errorf("failed to remove %s: %w", file.Path, err)
+			}
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, "before", filepath.FromSlash(file.Path)))
+		if err != nil {
+			return entry, fmt.Errorf("failed to read the journal copy of %s: %w", file.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return entry, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(path, content, file.Mode); err != nil {
+			return entry, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		if err := os.Chmod(path, file.Mode); err != nil {
+			return entry, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+	}
+
+	// Directories the operation created go too, deepest first, unless
+	// something else is in them now
+	for i := len(entry.Dirs) - 1; i >= 0; i-- {
+		os.Remove(filepath.Join(root, filepath.FromSlash(entry.Dirs[i])))
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return entry, fmt.Errorf("failed to remove journal entry %d: %w", entry.ID, err)
+	}
+	return entry, nil
+}
+
+// load reads the entry of an operation
+func load(root string, id int) (*Entry, error) {
+	content, err := os.ReadFile(filepath.Join(entryDir(root, id), entryFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal entry %d: %w", id, err)
+	}
+	var entry Entry
+	if err := yaml.Unmarshal(content, &entry); err != nil {
+		return nil, fmt.Errorf("invalid journal entry %d: %w", id, err)
+	}
+	entry.ID = id
+	return &entry, nil
+}
+
+// entryDir returns the directory of an operation
+func entryDir(root string, id int) string {
+	return filepath.Join(root, Dir, strconv.Itoa(id))
+}
+
+// matches reports whether the file at path has the checksum, or does not
+// exist when the checksum is empty
+func matches(path, sum string) bool {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sum == ""
+	}
+	return err == nil && checksum(content) == sum
+}
+
+// writeContent writes a file of the journal
+func writeContent(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of content
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
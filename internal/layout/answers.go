@@ -0,0 +1,123 @@
+package layout
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/journal"
+	"gopkg.in/yaml.v3"
+)
+
+// AnswersFileName is the default name of a saved answers file
+const AnswersFileName = "foundry.answers.yaml"
+
+// Answers are the settings a project was generated with. An answers file
+// holds them so the project can be generated again without prompts or flags.
+type Answers struct {
+	Layout      string            `yaml:"layout"`
+	ProjectName string            `yaml:"project_name"`
+	Module      string            `yaml:"module"`
+	Author      string            `yaml:"author,omitempty"`
+	License     string            `yaml:"license,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	GitHub      string            `yaml:"github,omitempty"`
+	GoVersion   string            `yaml:"go_version,omitempty"`
+	Year        int               `yaml:"year,omitempty"`
+	Options     map[string]string `yaml:"options,omitempty"`
+	Variables   map[string]string `yaml:"variables,omitempty"`
+	Features    map[string]bool   `yaml:"features,omitempty"`
+}
+
+// answersKeys are the keys an answers file may contain
+var answersKeys = []string{
+	"layout", "project_name", "module", "author", "license", "description",
+	"github", "go_version", "year", "options", "variables", "features",
+}
+
+// requiredAnswersKeys are the keys an answers file must contain
+var requiredAnswersKeys = []string{"layout", "project_name", "module"}
+
+// LoadAnswers reads an answers file, rejecting unknown and missing keys
+func LoadAnswers(path string) (*Answers, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	var keys map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	for key, node := range keys {
+		if !slices.Contains(answersKeys, key) {
+			return nil, fmt.Errorf("answers file %s has unknown key '%s' on line %d (keys: %s)",
+				path, key, node.Line, strings.Join(answersKeys, ", "))
+		}
+	}
+	for _, key := range requiredAnswersKeys {
+		if node, ok := keys[key]; !ok || node.Value == "" {
+			return nil, fmt.Errorf("answers file %s is missing '%s'", path, key)
+		}
+	}
+
+	var answers Answers
+	if err := yaml.Unmarshal(content, &answers); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// NewAnswers records the settings of a project generated with the layout
+func NewAnswers(layoutName string, data ProjectData) *Answers {
+	return &Answers{
+		Layout:      layoutName,
+		ProjectName: data.ProjectName,
+		Module:      data.ModuleName,
+		Author:      data.Author,
+		License:     data.License,
+		Description: data.Description,
+		GitHub:      data.GitHubUsername,
+		GoVersion:   data.GoVersion,
+		Year:        data.Year,
+		Options:     data.Options,
+		Variables:   data.CustomVariables,
+		Features:    data.Features,
+	}
+}
+
+// ProjectData returns the project data the answers describe
+func (a *Answers) ProjectData() ProjectData {
+	return ProjectData{
+		ProjectName:     a.ProjectName,
+		ModuleName:      a.Module,
+		Author:          a.Author,
+		License:         a.License,
+		Description:     a.Description,
+		GitHubUsername:  a.GitHub,
+		Year:            a.Year,
+		GoVersion:       a.GoVersion,
+		CustomVariables: a.Variables,
+		Options:         a.Options,
+		Features:        a.Features,
+	}
+}
+
+// Save writes the answers to path
+func (a *Answers) Save(path string) error {
+	content, err := yaml.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to encode answers: %w", err)
+	}
+
+	header := "# Generate this project again with: foundry new --answers " + filepath.Base(path) + "\n"
+	if err := journal.Touch(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append([]byte(header), content...), 0644); err != nil {
+		return fmt.Errorf("failed to write answers file: %w", err)
+	}
+	return nil
+}
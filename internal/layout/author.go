@@ -0,0 +1,578 @@
+package layout
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// skeletonFiles are the files of a new layout, with $LAYOUT for its name
+var skeletonFiles = map[string]string{
+	ManifestFileName: `# Manifest of the $LAYOUT layout. See foundry layout validate and
+# foundry layout test for checking it as it grows.
+name: "$LAYOUT"
+version: "0.1.0"
+description: "The $LAYOUT project layout"
+# Keywords foundry layout search finds the layout by once it is published
+tags: []
+
+# Settings asked for when a project is created, available to templates as
+# {{.CustomVariables.<name>}}
+variables:
+  - name: "http_port"
+    type: "port"
+    default: "8080"
+    description: "Port the server listens on"
+
+structure:
+  directories:
+    - path: "cmd/{{.ProjectName}}"
+    - path: "internal/handlers"
+
+  # Files rendered from the templates under project/
+  files:
+    - template: "project/go.mod.tmpl"
+      target: "go.mod"
+    - template: "project/main.go.tmpl"
+      target: "cmd/{{.ProjectName}}/main.go"
+    - template: "project/README.md.tmpl"
+      target: "README.md"
+    - template: "project/gitignore.tmpl"
+      target: ".gitignore"
+
+# Components foundry add can generate from the templates under components/
+components:
+  handler:
+    template: "components/handler.go.tmpl"
+    target_dir: "internal/handlers"
+`,
+	"project/go.mod.tmpl": `module {{.ModuleName}}
+
+go {{.GoVersion}}
+`,
+	"project/main.go.tmpl": `package main
+
+import (
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := ":{{.CustomVariables.http_port}}"
+	log.Printf("{{.ProjectName}} listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
+`,
+	"project/README.md.tmpl": `# {{.ProjectName}}
+
+{{.Description}}
+`,
+	"project/gitignore.tmpl": `/{{.ProjectName}}
+*.test
+`,
+	"components/handler.go.tmpl": `package handlers
+
+import "net/http"
+
+// {{.ComponentName | pascal}}Handler handles {{.ComponentName}} requests
+type {{.ComponentName | pascal}}Handler struct{}
+
+// New{{.ComponentName | pascal}}Handler creates a {{.ComponentName | pascal}}Handler
+func New{{.ComponentName | pascal}}Handler() *{{.ComponentName | pascal}}Handler {
+	return &{{.ComponentName | pascal}}Handler{}
+}
+
+// ServeHTTP handles a request
+func (h *{{.ComponentName | pascal}}Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+`,
+	filepath.Join(SnapshotDir, "basic", AnswersFileName): `layout: $LAYOUT
+project_name: demo
+module: github.com/example/demo
+description: A demo project
+go_version: "1.22"
+year: 2024
+`,
+}
+
+// ScaffoldLayout writes a skeleton of a layout named name to dir, which
+// must not exist or be empty. It returns the files written.
+func ScaffoldLayout(dir, name string) ([]string, error) {
+	if !isValidLayoutName(name) {
+		return nil, fmt.Errorf("invalid layout name '%s': use letters, digits, '-' and '_'", name)
+	}
+	if err := checkEmptyDir(dir); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(skeletonFiles))
+	for path, content := range skeletonFiles {
+		files[filepath.ToSlash(path)] = []byte(strings.ReplaceAll(content, "$LAYOUT", name))
+	}
+	if err := writeFiles(dir, files); err != nil {
+		return nil, err
+	}
+	return sortedKeys(files), nil
+}
+
+// Extraction describes a layout made from an existing project
+type Extraction struct {
+	Name        string `json:"name" yaml:"name"`
+	Dir         string `json:"dir" yaml:"dir"`
+	ModulePath  string `json:"module_path" yaml:"module_path"`
+	ProjectName string `json:"project_name" yaml:"project_name"`
+	BinaryName  string `json:"binary_name,omitempty" yaml:"binary_name,omitempty"`
+	// Components maps the component types found to the files their
+	// templates were made from
+	Components map[string]string `json:"components" yaml:"components"`
+	Files      []string          `json:"files" yaml:"files"`
+}
+
+// binaryVariable is the variable for a binary named other than the project
+const binaryVariable = "binary_name"
+
+// extractSkipDirs are directories of a project that are not part of a layout
+var extractSkipDirs = []string{".git", ".foundry", ".idea", ".vscode", "node_modules", "vendor"}
+
+// extractSkipFiles are lock files, which are regenerated by their tools
+// rather than rendered from templates
+var extractSkipFiles = []string{
+	"go.sum", "go.work.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.lock", "Gemfile.lock", "poetry.lock", "composer.lock",
+}
+
+// componentDirs maps the directory names that hold components to their type
+var componentDirs = map[string]string{
+	"handlers":     "handler",
+	"handler":      "handler",
+	"models":       "model",
+	"model":        "model",
+	"middleware":   "middleware",
+	"middlewares":  "middleware",
+	"services":     "service",
+	"service":      "service",
+	"repository":   "repository",
+	"repositories": "repository",
+}
+
+// ExtractLayout turns the Go project in projectDir into a layout named name
+// written to dir, which must not exist or be empty. Every text file of the
+// project becomes a template with its module path, project name and binary
+// name replaced by template variables; the module path only in import paths
+// and the module directive, as it may be an ordinary word such as api. Lock
+// files such as go.sum are left out. The first Go file in each directory
+// of handlers, models, middleware, services or repositories becomes the
+// template of that component. A test case with the settings of the project
+// is added, so foundry layout test --update records it as the golden project.
+func ExtractLayout(projectDir, dir, name string) (*Extraction, error) {
+	if !isValidLayoutName(name) {
+		return nil, fmt.Errorf("invalid layout name '%s': use letters, digits, '-' and '_'", name)
+	}
+	modulePath, goVersion, err := readGoMod(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmptyDir(dir); err != nil {
+		return nil, err
+	}
+
+	extraction := &Extraction{
+		Name:        name,
+		Dir:         dir,
+		ModulePath:  modulePath,
+		ProjectName: moduleProjectName(modulePath),
+		BinaryName:  binaryName(projectDir),
+		Components:  make(map[string]string),
+	}
+	manifest := &LayoutManifest{
+		Name:        name,
+		Version:     "0.1.0",
+		Description: "Layout extracted from " + modulePath,
+		Components:  make(map[string]ComponentTemplate),
+	}
+
+	subs := []substitution{{modulePath, "{{.ModuleName}}", importPaths}}
+	switch extraction.BinaryName {
+	case "", extraction.ProjectName:
+	default:
+		subs = append(subs, substitution{extraction.BinaryName, "{{.CustomVariables." + binaryVariable + "}}", anywhere})
+		manifest.Variables = append(manifest.Variables, LayoutVariable{
+			Name:        binaryVariable,
+			Default:     extraction.BinaryName,
+			Description: "Name of the binary built from cmd/",
+		})
+	}
+	subs = append(subs, substitution{extraction.ProjectName, "{{.ProjectName}}", names})
+
+	// Output inside the project is not part of it
+	skip, _ := filepath.Abs(dir)
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(projectDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if abs, _ := filepath.Abs(path); abs == skip {
+			return filepath.SkipDir
+		}
+
+		if entry.IsDir() {
+			if contains(extractSkipDirs, entry.Name()) {
+				return filepath.SkipDir
+			}
+			manifest.Structure.Directories = append(manifest.Structure.Directories,
+				DirectorySpec{Path: templatize(rel, subs, nil)})
+			if kind, ok := componentDirs[entry.Name()]; ok {
+				if _, found := manifest.Components[kind]; !found {
+					return extractComponent(path, rel, kind, subs, manifest, extraction, files)
+				}
+			}
+			return nil
+		}
+
+		if rel == AnswersFileName || contains(extractSkipFiles, entry.Name()) || !entry.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		if !isText(content) {
+			return nil
+		}
+		template := projectTemplatesDir + "/" + rel + ".tmpl"
+		files[template] = []byte(templatize(string(content), subs, importSpans(rel, content)))
+		manifest.Structure.Files = append(manifest.Structure.Files,
+			FileSpec{Template: template, Target: templatize(rel, subs, nil)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project: %w", err)
+	}
+	if len(manifest.Structure.Files) == 0 {
+		return nil, fmt.Errorf("no text files found in %s", projectDir)
+	}
+
+	var manifestContent bytes.Buffer
+	encoder := yaml.NewEncoder(&manifestContent)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	encoder.Close()
+	files[ManifestFileName] = manifestContent.Bytes()
+
+	answers := &Answers{
+		Layout:      name,
+		ProjectName: extraction.ProjectName,
+		Module:      modulePath,
+		GoVersion:   goVersion,
+		Year:        time.Now().Year(),
+	}
+	content, err := yaml.Marshal(answers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode answers: %w", err)
+	}
+	files[SnapshotDir+"/basic/"+AnswersFileName] = content
+
+	if err := writeFiles(dir, files); err != nil {
+		return nil, err
+	}
+	extraction.Files = sortedKeys(files)
+	return extraction, nil
+}
+
+// extractComponent makes the first Go file in a component directory the
+// template of its component type, with the name of the file replaced by
+// the component name
+func extractComponent(dir, rel, kind string, subs []substitution, manifest *LayoutManifest, extraction *Extraction, files map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") || name == "doc.go" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		stem := strings.TrimSuffix(name, ".go")
+		componentSubs := append([]substitution{}, subs...)
+		for _, sub := range []substitution{
+			{toPascalCase(stem), "{{.ComponentName | pascal}}", anywhere},
+			{toCamelCase(stem), "{{.ComponentName | camel}}", anywhere},
+			{toSnakeCase(stem), "{{.ComponentName | snake}}", anywhere},
+		} {
+			if !hasSubstitution(componentSubs, sub.text) {
+				componentSubs = append(componentSubs, sub)
+			}
+		}
+
+		template := componentTemplatesDir + "/" + kind + ".go.tmpl"
+		files[template] = []byte(templatize(string(content), componentSubs, importSpans(name, content)))
+		manifest.Components[kind] = ComponentTemplate{Template: template, TargetDir: templatize(rel, subs, nil)}
+		extraction.Components[kind] = rel + "/" + name
+		return nil
+	}
+	return nil
+}
+
+// substitution replaces a name in a project with a template expression
+type substitution struct {
+	text  string
+	repl  string
+	scope scope
+}
+
+// scope limits where a substitution applies
+type scope int
+
+const (
+	// anywhere replaces the name wherever it stands alone
+	anywhere scope = iota
+	// importPaths replaces a module path only at the start of an import
+	// path or in the module directive of go.mod
+	importPaths
+	// names replaces the name except as the first element of an absolute
+	// path such as /api/v1, which names a route rather than the project
+	names
+)
+
+// span is the range of offsets [start, end) of an import path in a file
+type span struct {
+	start, end int
+}
+
+// goModModule matches the module directive of a go.mod file
+var goModModule = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)`)
+
+// importSpans returns where the import paths of a Go file, or the module
+// path of a go.mod file, are found in content
+func importSpans(name string, content []byte) []span {
+	switch {
+	case filepath.Base(name) == "go.mod":
+		if m := goModModule.FindSubmatchIndex(content); m != nil {
+			return []span{{m[2], m[3]}}
+		}
+	case strings.HasSuffix(name, ".go"):
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, content, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		var spans []span
+		for _, spec := range file.Imports {
+			start := fset.Position(spec.Path.Pos()).Offset + 1
+			end := fset.Position(spec.Path.End()).Offset - 1
+			spans = append(spans, span{start, end})
+		}
+		return spans
+	}
+	return nil
+}
+
+// hasSubstitution reports whether subs already replace text
+func hasSubstitution(subs []substitution, text string) bool {
+	for _, sub := range subs {
+		if sub.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// templatize turns text into a template that renders it: existing template
+// delimiters are escaped and each name in subs is replaced where it stands
+// as a word of its own, or a part of a camelCase word or plural, within its
+// scope. Module paths are only replaced at the start of the import spans.
+// Earlier substitutions win.
+func templatize(text string, subs []substitution, imports []span) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "{{"):
+			b.WriteString("{{`{{`}}")
+			i += 2
+			continue
+		case strings.HasPrefix(rest, "}}"):
+			b.WriteString("{{`}}`}}")
+			i += 2
+			continue
+		}
+
+		matched := false
+		for _, sub := range subs {
+			if sub.text != "" && strings.HasPrefix(rest, sub.text) && sub.applies(text, i, imports) {
+				b.WriteString(sub.repl)
+				i += len(sub.text)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(text[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// applies reports whether the substitution replaces the occurrence of its
+// name at start of text
+func (sub substitution) applies(text string, start int, imports []span) bool {
+	end := start + len(sub.text)
+	switch sub.scope {
+	case importPaths:
+		for _, s := range imports {
+			if s.start == start && (end == s.end || end < s.end && text[end] == '/') {
+				return true
+			}
+		}
+		return false
+	case names:
+		if start > 0 && text[start-1] == '/' && (start == 1 || !isWordByte(text[start-2]) && text[start-2] != '.') {
+			return false
+		}
+	}
+	return standsAlone(text, start, end)
+}
+
+// standsAlone reports whether text[start:end] is not part of a longer word.
+// A capitalized name may follow a lowercase letter, as in GetUser, and any
+// name may be followed by a capital or a plural s, as in UserHandler or users.
+func standsAlone(text string, start, end int) bool {
+	if start > 0 && isWordByte(text[start-1]) {
+		if !isUpperByte(text[start]) || isUpperByte(text[start-1]) {
+			return false
+		}
+	}
+	if end < len(text) && isWordByte(text[end]) && !isUpperByte(text[end]) {
+		plural := text[end] == 's' && (end+1 == len(text) || !isWordByte(text[end+1]))
+		if !plural {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordByte reports whether b can be part of a name
+func isWordByte(b byte) bool {
+	return b >= utf8.RuneSelf || b == '_' || b == '-' ||
+		b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// isUpperByte reports whether b is an ASCII capital
+func isUpperByte(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
+
+// isText reports whether content looks like text rather than a binary
+func isText(content []byte) bool {
+	return utf8.Valid(content) && !strings.ContainsRune(string(content), 0)
+}
+
+// readGoMod returns the module path and Go version of the project in dir
+func readGoMod(dir string) (string, string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not a Go module: %w", dir, err)
+	}
+
+	var modulePath, goVersion string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			modulePath = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	if modulePath == "" {
+		return "", "", fmt.Errorf("module declaration not found in %s", filepath.Join(dir, "go.mod"))
+	}
+	return modulePath, goVersion, nil
+}
+
+// moduleProjectName returns the project name of a module path: its last
+// element, before any major version suffix
+func moduleProjectName(modulePath string) string {
+	parts := strings.Split(modulePath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	return name
+}
+
+// binaryName returns the name of the only command under cmd/ of a
+// project, if it has exactly one
+func binaryName(projectDir string) string {
+	entries, err := os.ReadDir(filepath.Join(projectDir, "cmd"))
+	if err != nil {
+		return ""
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) != 1 {
+		return ""
+	}
+	return names[0]
+}
+
+// checkEmptyDir fails unless dir does not exist or is empty
+func checkEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", dir)
+	}
+	return nil
+}
+
+// writeFiles writes files, by slash-separated paths relative to dir
+func writeFiles(dir string, files map[string][]byte) error {
+	for _, path := range sortedKeys(files) {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(target, files[path], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package layout
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
@@ -28,7 +29,7 @@ type CacheMetadata struct {
 }
 
 // NewCache creates a new cache instance
-func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+func NewCache(dir string, ttl time.Duration, logger *slog.Logger) (*Cache, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
@@ -43,7 +44,7 @@ func NewCache(dir string, ttl time.Duration) (*Cache, error) {
 	// Load existing cache metadata
 	if err := cache.loadMetadata(); err != nil {
 		// Non-fatal error, just start with empty cache
-		fmt.Printf("Warning: failed to load cache metadata: %v\n", err)
+		logger.Warn("failed to load cache metadata", "dir", dir, "error", err)
 	}
 
 	return cache, nil
@@ -0,0 +1,130 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DriftStatus is how a file in a project compares with what its layout
+// generates
+type DriftStatus string
+
+const (
+	// DriftUntouched files match the generated output
+	DriftUntouched DriftStatus = "untouched"
+	// DriftModified files differ from the generated output
+	DriftModified DriftStatus = "modified"
+	// DriftDeleted files were generated but are gone from the project
+	DriftDeleted DriftStatus = "deleted"
+	// DriftMissing files are generated by the layout but were never
+	// generated into the project
+	DriftMissing DriftStatus = "missing"
+)
+
+// DriftFile compares a file in a project with what its layout generates.
+// Expected is nil when the generated output is unknown and only its
+// checksum could be compared.
+type DriftFile struct {
+	Path     string      `json:"path" yaml:"path"`
+	Status   DriftStatus `json:"status" yaml:"status"`
+	Source   string      `json:"source" yaml:"source"`
+	Expected []byte      `json:"-" yaml:"-"`
+	Actual   []byte      `json:"-" yaml:"-"`
+}
+
+// ProjectDrift renders the layout and components of a project in memory,
+// with the settings recorded in its lock file, and compares the output with
+// the files in the project. Nothing is written.
+func (m *Manager) ProjectDrift(ctx context.Context, projectPath string) ([]DriftFile, *Lock, error) {
+	lock, err := LoadLock(projectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("no %s in %s; only projects generated by foundry can be compared", LockFileName, projectPath)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if lock.Settings == nil {
+		return nil, nil, fmt.Errorf("%s does not record the project settings", LockFileName)
+	}
+
+	layout, err := m.GetLayout(ctx, lock.Layout.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load layout: %w", err)
+	}
+	data := lock.Settings.ProjectData()
+	pruneSettings(layout, &data)
+	if err := m.validateProjectData(layout, &data); err != nil {
+		return nil, nil, fmt.Errorf("validation failed: %w", err)
+	}
+	rendered, renderedLock, err := m.renderProject(layout, projectPath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// What the layout generates now, then what else was generated
+	expected := make(map[string]DriftFile)
+	for _, path := range rendered.Files() {
+		content, _, _ := rendered.ReadFile(path)
+		expected[filepath.ToSlash(path)] = DriftFile{
+			Source:   "template " + renderedLock.Files[filepath.ToSlash(path)].Template,
+			Expected: content,
+		}
+	}
+	for path, locked := range lock.Files {
+		if _, ok := expected[path]; ok {
+			continue
+		}
+		expected[path] = m.expectedOutput(layout, projectPath, path, locked)
+	}
+
+	drift := make([]DriftFile, 0, len(expected))
+	for path, file := range expected {
+		file.Path = path
+		actual, err := os.ReadFile(filepath.Join(projectPath, path))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			file.Status = DriftMissing
+			if _, generated := lock.Files[path]; generated {
+				file.Status = DriftDeleted
+			}
+		case err != nil:
+			return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		default:
+			file.Actual = actual
+			file.Status = DriftModified
+			if (file.Expected == nil && lock.Pristine(path, actual)) ||
+				(file.Expected != nil && string(file.Expected) == string(actual)) {
+				file.Status = DriftUntouched
+			}
+		}
+		drift = append(drift, file)
+	}
+
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift, lock, nil
+}
+
+// expectedOutput returns what a generated file that is not part of the
+// layout's project structure should contain: the component rendered again
+// when possible, otherwise the output kept when it was generated
+func (m *Manager) expectedOutput(layout *Layout, projectPath, path string, locked LockedFile) DriftFile {
+	if c := locked.Component; c != nil {
+		if _, content, err := m.renderComponent(layout, c.Type, c.Name, c.Data); err == nil {
+			return DriftFile{Source: fmt.Sprintf("component %s %s", c.Type, c.Name), Expected: content}
+		}
+	}
+
+	source := "generated output"
+	if locked.Generator != "" {
+		source = "output of " + locked.Generator
+	}
+	if content, ok := readBase(projectPath, path); ok {
+		return DriftFile{Source: source, Expected: content}
+	}
+	return DriftFile{Source: source + " (checksum only)"}
+}
@@ -0,0 +1,148 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseExtends splits a parent reference such as "standard@1.x" into the
+// layout name and the version constraint
+func parseExtends(ref string) (string, string) {
+	name, constraint, _ := strings.Cut(strings.TrimSpace(ref), "@")
+	return name, constraint
+}
+
+// versionMatches reports whether version satisfies constraint. Constraints
+// are versions whose missing or "x" parts match anything, so "1.x" and "1"
+// both accept 1.4.2. An empty constraint accepts every version.
+func versionMatches(version, constraint string) bool {
+	constraint = strings.TrimPrefix(constraint, "v")
+	if constraint == "" || constraint == "*" {
+		return true
+	}
+
+	have := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	for i, want := range strings.Split(constraint, ".") {
+		if want == "x" || want == "X" || want == "*" {
+			continue
+		}
+		if i >= len(have) || have[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLayouts returns child laid over its parent. Templates, directories,
+// files, variables, options and components of the child replace those of the
+// parent with the same key; everything else is inherited.
+func mergeLayouts(parent, child *Layout) *Layout {
+	templates := make(map[string]string, len(parent.Templates)+len(child.Templates))
+	for path, content := range parent.Templates {
+		templates[path] = content
+	}
+	for path, content := range child.Templates {
+		templates[path] = content
+	}
+
+	return &Layout{
+		Name:      child.Name,
+		Version:   child.Version,
+		Source:    child.Source,
+		Manifest:  mergeManifests(parent.Manifest, child.Manifest),
+		Templates: templates,
+		LoadedAt:  child.LoadedAt,
+	}
+}
+
+// mergeManifests returns the manifest of child with the entries it does not
+// override inherited from parent
+func mergeManifests(parent, child *LayoutManifest) *LayoutManifest {
+	merged := *child
+	if merged.Description == "" {
+		merged.Description = parent.Description
+	}
+	if merged.Author == "" {
+		merged.Author = parent.Author
+	}
+	if merged.MinFoundryVersion == "" {
+		merged.MinFoundryVersion = parent.MinFoundryVersion
+	}
+
+	merged.Structure.Directories = mergeByKey(parent.Structure.Directories, child.Structure.Directories,
+		func(d DirectorySpec) string { return d.Path })
+	merged.Structure.Files = mergeByKey(parent.Structure.Files, child.Structure.Files,
+		func(f FileSpec) string { return f.Target })
+
+	// Variables keep the parent's description and type unless they give
+	// their own
+	inherited := make(map[string]LayoutVariable, len(parent.Variables))
+	for _, v := range parent.Variables {
+		inherited[v.Name] = v
+	}
+	variables := make([]LayoutVariable, len(child.Variables))
+	for i, v := range child.Variables {
+		base := inherited[v.Name]
+		if v.Description == "" {
+			v.Description = base.Description
+		}
+		if v.Type == "" {
+			v.Type, v.Choices, v.Pattern = base.Type, base.Choices, base.Pattern
+		}
+		variables[i] = v
+	}
+	merged.Variables = mergeByKey(parent.Variables, variables,
+		func(v LayoutVariable) string { return v.Name })
+
+	merged.Dependencies = unique(append(append([]string{}, parent.Dependencies...), child.Dependencies...))
+	merged.Features = unique(append(append([]string{}, parent.Features...), child.Features...))
+
+	merged.Options = make(map[string]LayoutOption, len(parent.Options)+len(child.Options))
+	for name, option := range parent.Options {
+		merged.Options[name] = option
+	}
+	for name, option := range child.Options {
+		merged.Options[name] = option
+	}
+
+	merged.Components = make(map[string]ComponentTemplate, len(parent.Components)+len(child.Components))
+	for name, component := range parent.Components {
+		merged.Components[name] = component
+	}
+	for name, component := range child.Components {
+		merged.Components[name] = component
+	}
+
+	return &merged
+}
+
+// mergeByKey replaces the entries of parent with the entries of child that
+// share their key, keeping the parent's order, and appends the rest
+func mergeByKey[T any](parent, child []T, key func(T) string) []T {
+	overrides := make(map[string]T, len(child))
+	for _, entry := range child {
+		overrides[key(entry)] = entry
+	}
+
+	merged := make([]T, 0, len(parent)+len(child))
+	seen := make(map[string]bool, len(parent))
+	for _, entry := range parent {
+		k := key(entry)
+		if override, ok := overrides[k]; ok {
+			entry = override
+		}
+		merged = append(merged, entry)
+		seen[k] = true
+	}
+	for _, entry := range child {
+		if !seen[key(entry)] {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// cycleError describes an inheritance cycle through the layouts in chain
+func cycleError(chain []string, name string) error {
+	return fmt.Errorf("layout inheritance cycle: %s -> %s", strings.Join(chain, " -> "), name)
+}
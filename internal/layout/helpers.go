@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -277,7 +278,7 @@ func getHomeDir() string {
 // Git helpers
 
 // cloneGitRepository clones a git repository
-func cloneGitRepository(url, ref, destDir string) error {
+func cloneGitRepository(url, ref, destDir string, logger *slog.Logger) error {
 	// Check if git is available
 	if _, err := exec.LookPath("git"); err != nil {
 		return fmt.Errorf("git is not installed or not in PATH")
@@ -319,7 +320,7 @@ func cloneGitRepository(url, ref, destDir string) error {
 	gitDir := filepath.Join(destDir, ".git")
 	if err := os.RemoveAll(gitDir); err != nil {
 		// Non-fatal error
-		fmt.Printf("Warning: failed to remove .git directory: %v\n", err)
+		logger.Warn("failed to remove .git directory", "dir", gitDir, "error", err)
 	}
 
 	return nil
@@ -363,12 +364,14 @@ func mergeMaps(maps ...map[string]string) map[string]string {
 	return result
 }
 
-// defaultString returns the default value if the string is empty
-func defaultString(s, defaultValue string) string {
-	if s == "" {
+// defaultString returns the default value if the value is empty or
+// missing. The default comes first, so templates can pipe the value:
+// {{.X | default "y"}}
+func defaultString(defaultValue string, value interface{}) string {
+	if value == nil || fmt.Sprint(value) == "" {
 		return defaultValue
 	}
-	return s
+	return fmt.Sprint(value)
 }
 
 // contains checks if a string slice contains a value
@@ -448,6 +451,26 @@ func isDir(path string) bool {
 	return err == nil && info.IsDir()
 }
 
+// optionTemplatePaths expands the {{.Options.<name>}} placeholders of a
+// template path into the paths of every choice of those options
+func optionTemplatePaths(path string, options map[string]LayoutOption) []string {
+	paths := []string{path}
+	for name, option := range options {
+		placeholder := "{{.Options." + name + "}}"
+		if !strings.Contains(path, placeholder) {
+			continue
+		}
+		var expanded []string
+		for _, p := range paths {
+			for _, choice := range option.Choices {
+				expanded = append(expanded, strings.ReplaceAll(p, placeholder, choice))
+			}
+		}
+		paths = expanded
+	}
+	return paths
+}
+
 // pluralize adds 's' to make a word plural (simple implementation)
 func pluralize(s string) string {
 	if s == "" {
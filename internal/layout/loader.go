@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/shapestone/foundry/internal/logging"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,26 +20,130 @@ type Loader struct {
 	registry *Registry
 	cache    *Cache
 	client   *http.Client
+	logger   *slog.Logger
 }
 
 // NewLoader creates a new layout loader
-func NewLoader(registry *Registry, cache *Cache) *Loader {
+func NewLoader(registry *Registry, cache *Cache, logger *slog.Logger) *Loader {
 	return &Loader{
 		registry: registry,
 		cache:    cache,
+		logger:   logger,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
-// Load loads a layout by name
+// Load loads a layout by name. Layouts that extend another layout are
+// returned merged with their parent chain.
 func (l *Loader) Load(ctx context.Context, name string) (*Layout, error) {
+	return l.load(ctx, name, nil)
+}
+
+// LoadManifest loads only the manifest of a layout, without its templates.
+// It returns nil for layouts whose manifest comes with a download.
+func (l *Loader) LoadManifest(name string) (*LayoutManifest, error) {
+	if layout, ok := l.cache.Get(name); ok {
+		return layout.Manifest, nil
+	}
+	if contains(GetEmbeddedLayouts(), name) {
+		return ParseEmbeddedManifest(name)
+	}
+
+	source, err := l.registry.GetLayoutSource(name)
+	if err != nil {
+		return nil, fmt.Errorf("layout not found: %w", err)
+	}
+	if source.Type != "local" {
+		return nil, nil
+	}
+	manifest, err := l.loadManifest(filepath.Join(expandPath(source.Location), "layout.manifest.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// load loads a layout and its parents; chain holds the layouts that extend
+// it, to detect cycles
+func (l *Loader) load(ctx context.Context, name string, chain []string) (*Layout, error) {
+	if contains(chain, name) {
+		return nil, cycleError(chain, name)
+	}
+
 	// Check cache first
 	if layout, ok := l.cache.Get(name); ok {
+		l.logger.Log(ctx, logging.LevelTrace, "layout found in cache", "layout", name)
 		return layout, nil
 	}
 
+	layout, err := l.loadSource(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	l.logger.Debug("loaded layout", "layout", name, "version", layout.Version, "templates", len(layout.Templates))
+
+	layout, err = l.resolveParent(ctx, layout, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the loaded layout
+	l.cache.Set(name, layout)
+	return layout, nil
+}
+
+// LoadVersion loads a layout at a version. Layouts from GitHub are fetched
+// at the tag of the version; other sources only offer the version they hold.
+func (l *Loader) LoadVersion(ctx context.Context, name, version string) (*Layout, error) {
+	layout, err := l.Load(ctx, name)
+	if err != nil || version == "" || layout.Version == strings.TrimPrefix(version, "v") {
+		return layout, err
+	}
+
+	source, err := l.registry.GetLayoutSource(name)
+	if err != nil || contains(GetEmbeddedLayouts(), name) || source.Type != "github" {
+		return nil, fmt.Errorf("layout '%s' version %s is not available (found %s)", name, version, layout.Version)
+	}
+
+	source.Ref = version
+	layout, err = l.loadGitHub(ctx, name, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load layout '%s' version %s: %w", name, version, err)
+	}
+	if layout.Version != strings.TrimPrefix(version, "v") {
+		return nil, fmt.Errorf("layout '%s' at %s is version %s", name, version, layout.Version)
+	}
+	return l.resolveParent(ctx, layout, nil)
+}
+
+// resolveParent merges a layout with the layout it extends, if any
+func (l *Loader) resolveParent(ctx context.Context, layout *Layout, chain []string) (*Layout, error) {
+	if layout.Manifest.Extends == "" {
+		return layout, nil
+	}
+
+	name := layout.Name
+	parentName, constraint := parseExtends(layout.Manifest.Extends)
+	parent, err := l.load(ctx, parentName, append(chain, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parent of layout '%s': %w", name, err)
+	}
+	if !versionMatches(parent.Version, constraint) {
+		return nil, fmt.Errorf("layout '%s' extends %s, but '%s' is version %s",
+			name, layout.Manifest.Extends, parentName, parent.Version)
+	}
+	return mergeLayouts(parent, layout), nil
+}
+
+// loadSource loads a single layout without resolving its parent. Embedded
+// layouts take precedence over the registry.
+func (l *Loader) loadSource(ctx context.Context, name string) (*Layout, error) {
+	if contains(GetEmbeddedLayouts(), name) {
+		return l.loadEmbedded(name)
+	}
+
 	// Find layout source
 	source, err := l.registry.GetLayoutSource(name)
 	if err != nil {
@@ -45,25 +151,60 @@ func (l *Loader) Load(ctx context.Context, name string) (*Layout, error) {
 	}
 
 	// Load based on source type
-	var layout *Layout
 	switch source.Type {
 	case "local":
-		layout, err = l.loadLocal(ctx, name, source)
+		return l.loadLocal(ctx, name, source)
 	case "remote":
-		layout, err = l.loadRemote(ctx, name, source)
+		return l.loadRemote(ctx, name, source)
 	case "github":
-		layout, err = l.loadGitHub(ctx, name, source)
+		return l.loadGitHub(ctx, name, source)
 	default:
 		return nil, fmt.Errorf("unsupported source type: %s", source.Type)
 	}
+}
 
+// loadEmbedded loads a layout from the templates built into foundry
+func (l *Loader) loadEmbedded(name string) (*Layout, error) {
+	// Parse manifest
+	manifest, err := ParseEmbeddedManifest(name)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to parse embedded manifest: %w", err)
 	}
 
-	// Cache the loaded layout
-	l.cache.Set(name, layout)
-	return layout, nil
+	// Load all template files
+	templates := make(map[string]string)
+
+	// Load project templates, including every variant of the options
+	for _, file := range manifest.Structure.Files {
+		for _, path := range optionTemplatePaths(file.Template, manifest.Options) {
+			content, err := GetEmbeddedTemplateFile(name, path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load template %s: %w", path, err)
+			}
+			templates[path] = string(content)
+		}
+	}
+
+	// Load component templates
+	for _, component := range manifest.Components {
+		content, err := GetEmbeddedTemplateFile(name, component.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load component template %s: %w", component.Template, err)
+		}
+		templates[component.Template] = string(content)
+	}
+
+	return &Layout{
+		Name:    name,
+		Version: manifest.Version,
+		Source: LayoutSource{
+			Type:     "embedded",
+			Location: "built-in",
+		},
+		Manifest:  manifest,
+		Templates: templates,
+		LoadedAt:  time.Now(),
+	}, nil
 }
 
 // loadLocal loads a layout from the local filesystem
@@ -80,10 +221,12 @@ func (l *Loader) loadLocal(ctx context.Context, name string, source LayoutSource
 	// Load all template files
 	templates := make(map[string]string)
 
-	// Load project templates
+	// Load project templates; layouts that extend another may inherit all
 	projectDir := filepath.Join(basePath, "project")
 	if err := l.loadTemplatesFromDir(projectDir, "project", templates); err != nil {
-		return nil, fmt.Errorf("failed to load project templates: %w", err)
+		if !os.IsNotExist(err) || manifest.Extends == "" {
+			return nil, fmt.Errorf("failed to load project templates: %w", err)
+		}
 	}
 
 	// Load component templates
@@ -168,7 +311,8 @@ func (l *Loader) loadGitHub(ctx context.Context, name string, source LayoutSourc
 
 	// Clone the repository
 	repoURL := fmt.Sprintf("https://github.com/%s.git", source.Location)
-	if err := cloneGitRepository(repoURL, ref, cacheDir); err != nil {
+	if err := cloneGitRepository(repoURL, ref, cacheDir, l.logger); err != nil {
+		l.logger.Debug("git clone failed, downloading the archive instead", "repository", repoURL, "error", err)
 		// Fallback to archive download
 		archiveURL := fmt.Sprintf("https://github.com/%s/archive/refs/heads/%s.tar.gz", source.Location, ref)
 		source.Location = archiveURL
@@ -238,30 +382,18 @@ func (l *Loader) loadTemplatesFromDir(dir, prefix string, templates map[string]s
 	})
 }
 
-// downloadFile downloads a file from a URL
+// downloadFile downloads a file from a URL, a file:// URL or a path
 func (l *Loader) downloadFile(ctx context.Context, url, dest string) error {
 	// Ensure destination directory exists
 	if err := ensureDir(filepath.Dir(dest)); err != nil {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return err
-	}
-
-	// Add user agent
-	req.Header.Set("User-Agent", "Foundry-CLI/1.0")
-
-	resp, err := l.client.Do(req)
+	in, err := openLocation(ctx, l.client, url)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	defer in.Close()
 
 	out, err := os.Create(dest)
 	if err != nil {
@@ -269,6 +401,6 @@ func (l *Loader) downloadFile(ctx context.Context, url, dest string) error {
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(out, in)
 	return err
 }
@@ -0,0 +1,258 @@
+package layout
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/journal"
+	"github.com/shapestone/foundry/internal/staging"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is where a project records how it was generated, relative to
+// the project root
+const LockFileName = ".foundry/lock.yaml"
+
+// BaseDir holds a copy of the files generated from the layout as they were
+// produced, relative to the project root. Upgrades merge against it, so it
+// is committed with the project like the lock file: the layout version it
+// was rendered from may no longer be available to render it again.
+const BaseDir = ".foundry/base"
+
+// IgnoreFileName keeps the local state of Foundry in a project, the journal
+// of operations for undo, out of version control
+const IgnoreFileName = ".foundry/.gitignore"
+
+// ignoreContent is the content of IgnoreFileName
+const ignoreContent = `# Written by foundry. lock.yaml and base/ record how the project was
+# generated and are committed with it; the journal for undo is local.
+journal/
+`
+
+// LockVersion is the version of the lock file format
+const LockVersion = 1
+
+// Lock records the layout a project was generated from, the settings it was
+// generated with and the checksum of every file as it was produced, so that
+// generated files can be told apart from files edited since
+type Lock struct {
+	Version  int                   `yaml:"version"`
+	Layout   LockedLayout          `yaml:"layout"`
+	Settings *Answers              `yaml:"settings,omitempty"`
+	Files    map[string]LockedFile `yaml:"files"`
+}
+
+// LockedLayout is the layout a project was generated from
+type LockedLayout struct {
+	Name    string       `yaml:"name"`
+	Version string       `yaml:"version,omitempty"`
+	Source  LayoutSource `yaml:"source"`
+}
+
+// LockedFile is a generated file. Template names the layout template it was
+// rendered from and Component the layout component; Generator names the
+// command that produced or last changed it.
+type LockedFile struct {
+	SHA256    string           `yaml:"sha256"`
+	Template  string           `yaml:"template,omitempty"`
+	Component *LockedComponent `yaml:"component,omitempty"`
+	Generator string           `yaml:"generator,omitempty"`
+}
+
+// LockedComponent is a layout component as it was rendered: its type, its
+// name and the data passed to its template
+type LockedComponent struct {
+	Type string                 `yaml:"type"`
+	Name string                 `yaml:"name"`
+	Data map[string]interface{} `yaml:"data,omitempty"`
+}
+
+// NewLock creates an empty lock for a project generated from the layout
+func NewLock(layout *Layout) *Lock {
+	source := layout.Source
+	if source.Checksum == "" {
+		source.Checksum = LayoutChecksum(layout)
+	}
+	return &Lock{
+		Version: LockVersion,
+		Layout: LockedLayout{
+			Name:    layout.Name,
+			Version: layout.Version,
+			Source:  source,
+		},
+		Files: make(map[string]LockedFile),
+	}
+}
+
+// LoadLock reads the lock file of the project at projectRoot
+func LoadLock(projectRoot string) (*Lock, error) {
+	content, err := os.ReadFile(filepath.Join(projectRoot, LockFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("invalid lock file %s: %w", LockFileName, err)
+	}
+	if lock.Files == nil {
+		lock.Files = make(map[string]LockedFile)
+	}
+	return &lock, nil
+}
+
+// Record sets the checksum of a generated file, given relative to the
+// project root
+func (l *Lock) Record(path string, content []byte, file LockedFile) {
+	file.SHA256 = Checksum(content)
+	l.Files[filepath.ToSlash(filepath.Clean(path))] = file
+}
+
+// Pristine reports whether content is what the lock recorded for the file
+func (l *Lock) Pristine(path string, content []byte) bool {
+	file, ok := l.Files[filepath.ToSlash(filepath.Clean(path))]
+	return ok && file.SHA256 == Checksum(content)
+}
+
+// Marshal encodes the lock as it is stored in the lock file
+func (l *Lock) Marshal() ([]byte, error) {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lock file: %w", err)
+	}
+	header := "# Generated by foundry. Do not edit; commit it with the project.\n"
+	return append([]byte(header), content...), nil
+}
+
+// Save writes the lock file of the project at projectRoot
+func (l *Lock) Save(projectRoot string) error {
+	content, err := l.Marshal()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(projectRoot, LockFileName)
+	if err := journal.Touch(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(LockFileName), err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// recordComponent records a rendered component in the lock file of the
+// project, if it has one
+func recordComponent(projectRoot, path string, content []byte, component LockedComponent) error {
+	lock, err := LoadLock(projectRoot)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lock.Record(path, content, LockedFile{Component: &component})
+	return lock.Save(projectRoot)
+}
+
+// plainData converts template data to plain maps, lists and values, as they
+// are stored in the lock file. Data that cannot be converted is left out.
+func plainData(data map[string]interface{}) map[string]interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var plain map[string]interface{}
+	if err := json.Unmarshal(encoded, &plain); err != nil {
+		return nil
+	}
+	return plain
+}
+
+// stageBase stages in area the copies kept in BaseDir of the files staged
+// in from
+func stageBase(area, from *staging.Area) error {
+	for _, path := range from.Files() {
+		content, _, _ := from.ReadFile(path)
+		base := filepath.Join(BaseDir, path)
+		if err := area.WriteFile(base, content, 0644); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", base, err)
+		}
+		area.Unchecked(base)
+	}
+	return nil
+}
+
+// stageIgnore stages IgnoreFileName unless the project at projectRoot has
+// one already
+func stageIgnore(area *staging.Area, projectRoot string) error {
+	if _, err := os.Stat(filepath.Join(projectRoot, IgnoreFileName)); err == nil {
+		return nil
+	}
+	if err := area.WriteFile(IgnoreFileName, []byte(ignoreContent), 0644); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", IgnoreFileName, err)
+	}
+	return nil
+}
+
+// readBase returns the copy kept in BaseDir of a generated file
+func readBase(projectRoot, path string) ([]byte, bool) {
+	content, err := os.ReadFile(filepath.Join(projectRoot, BaseDir, path))
+	return content, err == nil
+}
+
+// Baseline returns where conflict resolution finds the output the files of
+// the project at projectRoot were generated with: the copy in the base
+// directory, or the file itself while the lock shows it is unchanged
+func Baseline(projectRoot string) conflict.Baseline {
+	lock, _ := LoadLock(projectRoot)
+	return func(path string, current []byte) ([]byte, bool) {
+		if content, ok := readBase(projectRoot, path); ok {
+			return content, true
+		}
+		if lock != nil && lock.Pristine(path, current) {
+			return current, true
+		}
+		return nil, false
+	}
+}
+
+// Checksum returns the hex encoded SHA-256 of content
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LayoutChecksum returns a SHA-256 over the manifest and templates of a
+// layout, so a lock tells which revision of a layout a project came from
+func LayoutChecksum(layout *Layout) string {
+	hash := sha256.New()
+	if manifest, err := yaml.Marshal(layout.Manifest); err == nil {
+		hash.Write(manifest)
+	}
+
+	names := make([]string, 0, len(layout.Templates))
+	for name := range layout.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%d\x00%s", name, len(layout.Templates[name]), layout.Templates[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
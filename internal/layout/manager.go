@@ -1,12 +1,20 @@
 package layout
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"go/format"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/logging"
+	"github.com/shapestone/foundry/internal/staging"
 )
 
 // Manager handles layout operations
@@ -14,110 +22,65 @@ type Manager struct {
 	registry *Registry
 	cache    *Cache
 	loader   *Loader
+	logger   *slog.Logger
+}
+
+// ManagerOption configures a Manager
+type ManagerOption func(*Manager)
+
+// WithLogger sets the logger for warnings and details of layout loading;
+// the default is slog.Default()
+func WithLogger(logger *slog.Logger) ManagerOption {
+	return func(m *Manager) {
+		m.logger = logger
+	}
 }
 
 // NewManager creates a new layout manager
-func NewManager(configPath string) (*Manager, error) {
+func NewManager(configPath string, opts ...ManagerOption) (*Manager, error) {
+	manager := &Manager{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(manager)
+	}
+
 	// Create registry
-	registry, err := NewRegistry(configPath)
+	registry, err := NewRegistry(configPath, manager.logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create registry: %w", err)
 	}
 
 	// Create cache
 	config := registry.GetConfig()
-	cache, err := NewCache(config.Cache.Directory, config.Cache.TTL)
+	cache, err := NewCache(config.Cache.Directory, config.Cache.TTL, manager.logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cache: %w", err)
 	}
 
-	// Create loader
-	loader := NewLoader(registry, cache)
-
-	return &Manager{
-		registry: registry,
-		cache:    cache,
-		loader:   loader,
-	}, nil
+	manager.registry = registry
+	manager.cache = cache
+	manager.loader = NewLoader(registry, cache, manager.logger)
+	return manager, nil
 }
 
-// Replace the existing ListLayouts method in internal/layout/manager.go
-
-// In manager.go ListLayouts function, add debug:
+// ListLayouts returns the built-in layouts followed by the registry layouts
 func (m *Manager) ListLayouts() []LayoutListEntry {
-	fmt.Printf("DEBUG: Manager.ListLayouts called\n")
 	var layouts []LayoutListEntry
 
 	// Add embedded layouts first (built-in templates)
-	fmt.Printf("DEBUG: Getting embedded layouts...\n")
 	embeddedLayouts := GetEmbeddedLayoutList()
-	fmt.Printf("DEBUG: Got %d embedded layouts\n", len(embeddedLayouts))
 	layouts = append(layouts, embeddedLayouts...)
 
 	// Add registry layouts (user/external templates)
-	fmt.Printf("DEBUG: Getting registry layouts...\n")
 	registryLayouts := m.registry.ListLayouts()
-	fmt.Printf("DEBUG: Got %d registry layouts\n", len(registryLayouts))
 	layouts = append(layouts, registryLayouts...)
 
-	fmt.Printf("DEBUG: Total layouts: %d\n", len(layouts))
 	return layouts
 }
 
-// Replace the GetLayout method in internal/layout/manager.go
-
+// GetLayout loads a layout by name, built-in layouts first, merged with
+// the layouts it extends
 func (m *Manager) GetLayout(ctx context.Context, name string) (*Layout, error) {
-	// Check embedded layouts first
-	embeddedLayouts := GetEmbeddedLayouts()
-	for _, layoutName := range embeddedLayouts {
-		if layoutName == name {
-			return m.loadEmbeddedLayout(name) // ✅ This works!
-		}
-	}
-
-	// Fall back to registry/loader
-	return m.loader.Load(ctx, name) // ❌ This uses filesystem
-}
-
-// loadEmbeddedLayout loads a layout from embedded templates
-func (m *Manager) loadEmbeddedLayout(name string) (*Layout, error) {
-	// Parse manifest
-	manifest, err := ParseEmbeddedManifest(name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse embedded manifest: %w", err)
-	}
-
-	// Load all template files
-	templates := make(map[string]string)
-
-	// Load project templates
-	for _, file := range manifest.Structure.Files {
-		content, err := GetEmbeddedTemplateFile(name, file.Template)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load template %s: %w", file.Template, err)
-		}
-		templates[file.Template] = string(content)
-	}
-
-	// Load component templates
-	for _, component := range manifest.Components {
-		content, err := GetEmbeddedTemplateFile(name, component.Template)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load component template %s: %w", component.Template, err)
-		}
-		templates[component.Template] = string(content)
-	}
-
-	return &Layout{
-		Name:    name,
-		Version: manifest.Version,
-		Source: LayoutSource{
-			Type:     "embedded",
-			Location: "built-in",
-		},
-		Manifest:  manifest, // ← Just manifest, not *manifest
-		Templates: templates,
-	}, nil
+	return m.loader.Load(ctx, name)
 }
 
 // AddRemoteLayout adds a remote layout to the registry
@@ -146,9 +109,9 @@ func (m *Manager) AddRemoteLayout(url string) error {
 	return m.registry.AddLayout(entry)
 }
 
-// RefreshLayouts updates the layout registry
-func (m *Manager) RefreshLayouts() error {
-	return m.registry.RefreshRemoteRegistries()
+// RefreshLayouts fetches the indexes of the configured registries
+func (m *Manager) RefreshLayouts(ctx context.Context) []RegistryStatus {
+	return m.registry.RefreshRemoteRegistries(ctx)
 }
 
 // ProjectData represents template variables for project generation
@@ -162,10 +125,51 @@ type ProjectData struct {
 	Year            int
 	GoVersion       string
 	CustomVariables map[string]string
+	Options         map[string]string
+	Features        map[string]bool
+}
+
+// ProjectPlan lists what GenerateProject creates for a layout
+type ProjectPlan struct {
+	Directories []string
+	Files       []string
+	Skipped     []SkippedEntry
+}
+
+// SkippedEntry is a directory or file left out because its when condition
+// is false
+type SkippedEntry struct {
+	Path string
+	When string
+}
+
+// ProjectOption configures project generation
+type ProjectOption func(*projectOptions)
+
+// projectOptions holds optional settings for GenerateProject
+type projectOptions struct {
+	conflicts *conflict.Resolver
+}
+
+// WithProjectConflicts resolves generated files that already exist in the
+// project directory. Without it they are overwritten.
+func WithProjectConflicts(resolver *conflict.Resolver) ProjectOption {
+	return func(o *projectOptions) {
+		o.conflicts = resolver
+	}
 }
 
 // GenerateProject generates a project using the specified layout
-func (m *Manager) GenerateProject(ctx context.Context, layoutName string, projectPath string, data ProjectData) error {
+func (m *Manager) GenerateProject(ctx context.Context, layoutName string, projectPath string, data ProjectData, opts ...ProjectOption) error {
+	options := &projectOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if err := m.checkVariables(layoutName, data); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
 	// Load layout
 	layout, err := m.GetLayout(ctx, layoutName)
 	if err != nil {
@@ -177,16 +181,169 @@ func (m *Manager) GenerateProject(ctx context.Context, layoutName string, projec
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Render everything before writing anything, so a failure leaves the
+	// project directory untouched
+	area, lock, err := m.renderProject(layout, projectPath, data)
+	if err != nil {
+		return err
+	}
+	lock.Settings = NewAnswers(layoutName, data)
+	generated := area.Files()
+
+	// Keep the output as generated, for upgrades to merge against
+	if err := stageBase(area, area); err != nil {
+		return err
+	}
+	if err := stageIgnore(area, projectPath); err != nil {
+		return err
+	}
+
+	if options.conflicts != nil {
+		options.conflicts.SetBaseline(Baseline(projectPath))
+		if _, err := options.conflicts.Resolve(area, generated); err != nil {
+			return err
+		}
+	}
+
+	content, err := lock.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := area.WriteFile(LockFileName, content, 0644); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", LockFileName, err)
+	}
+
+	if err := area.Commit(); err != nil {
+		return fmt.Errorf("failed to write project: %w", err)
+	}
+	m.logger.Debug("generated project", "layout", layoutName, "path", projectPath, "files", len(generated))
+
+	return nil
+}
+
+// renderProject renders the directories and files of a layout into a
+// staging area for projectPath, with a lock recording the rendered files
+func (m *Manager) renderProject(layout *Layout, projectPath string, data ProjectData) (*staging.Area, *Lock, error) {
+	area := staging.New(projectPath)
+
 	// Create project directory structure
-	if err := m.createDirectories(layout, projectPath, data); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+	if err := m.createDirectories(layout, area, data); err != nil {
+		return nil, nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	// Generate files from templates, recording their checksums
+	lock := NewLock(layout)
+	if err := m.generateFiles(layout, area, data, lock); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate files: %w", err)
+	}
+
+	return area, lock, nil
+}
+
+// PlanProject returns the directories and files GenerateProject would
+// create for the layout without writing anything
+func (m *Manager) PlanProject(ctx context.Context, layoutName string, data ProjectData) (*ProjectPlan, error) {
+	if err := m.checkVariables(layoutName, data); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	layout, err := m.GetLayout(ctx, layoutName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load layout: %w", err)
+	}
+
+	if err := m.validateProjectData(layout, &data); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Generate files from templates
-	if err := m.generateFiles(layout, projectPath, data); err != nil {
-		return fmt.Errorf("failed to generate files: %w", err)
+	plan := &ProjectPlan{}
+	for _, dir := range layout.Manifest.Structure.Directories {
+		path := m.processTemplatePath(dir.Path, data)
+		include, err := m.included(dir.When, data)
+		if err != nil {
+			return nil, fmt.Errorf("directory %s: %w", path, err)
+		}
+		if include {
+			plan.Directories = append(plan.Directories, path)
+		} else {
+			plan.Skipped = append(plan.Skipped, SkippedEntry{Path: path + "/", When: dir.When})
+		}
 	}
+	for _, file := range layout.Manifest.Structure.Files {
+		path := m.processTemplatePath(file.Target, data)
+		include, err := m.included(file.When, data)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", path, err)
+		}
+		if include {
+			plan.Files = append(plan.Files, path)
+		} else {
+			plan.Skipped = append(plan.Skipped, SkippedEntry{Path: path, When: file.When})
+		}
+	}
+
+	return plan, nil
+}
 
+// ResolveProjectData returns the project data with the defaults of the
+// layout filled in, as GenerateProject uses it
+func (m *Manager) ResolveProjectData(ctx context.Context, layoutName string, data ProjectData) (ProjectData, error) {
+	if err := m.checkVariables(layoutName, data); err != nil {
+		return ProjectData{}, fmt.Errorf("validation failed: %w", err)
+	}
+
+	layout, err := m.GetLayout(ctx, layoutName)
+	if err != nil {
+		return ProjectData{}, fmt.Errorf("failed to load layout: %w", err)
+	}
+
+	if err := m.validateProjectData(layout, &data); err != nil {
+		return ProjectData{}, fmt.Errorf("validation failed: %w", err)
+	}
+	return data, nil
+}
+
+// included evaluates a when condition against the project data. Empty
+// conditions are always true; otherwise the condition is a template whose
+// output must not be empty, "false", "0" or "no".
+func (m *Manager) included(when string, data ProjectData) (bool, error) {
+	if strings.TrimSpace(when) == "" {
+		return true, nil
+	}
+
+	tmpl, err := template.New("when").Option("missingkey=zero").Parse(when)
+	if err != nil {
+		return false, fmt.Errorf("invalid when condition %q: %w", when, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("failed to evaluate when condition %q: %w", when, err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(buf.String())) {
+	case "", "false", "0", "no", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
+
+// checkVariables checks the variable values against the layout manifest
+// before the templates load, so a bad value is not hidden by a template
+// error. validateProjectData checks them again with the full layout.
+func (m *Manager) checkVariables(layoutName string, data ProjectData) error {
+	manifest, err := m.loader.LoadManifest(layoutName)
+	if err != nil || manifest == nil {
+		// Loading the layout reports the error
+		return nil
+	}
+	for _, variable := range manifest.Variables {
+		if value, ok := data.CustomVariables[variable.Name]; ok {
+			if _, err := variable.Validate(value); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -201,6 +358,20 @@ func (m *Manager) validateProjectData(layout *Layout, data *ProjectData) error {
 		data.CustomVariables = make(map[string]string)
 	}
 
+	// Reject variables the layout does not declare
+	declared := make(map[string]bool, len(layout.Manifest.Variables))
+	names := make([]string, 0, len(layout.Manifest.Variables))
+	for _, variable := range layout.Manifest.Variables {
+		declared[variable.Name] = true
+		names = append(names, variable.Name)
+	}
+	for name := range data.CustomVariables {
+		if !declared[name] {
+			return fmt.Errorf("layout '%s' has no %s variable (variables: %s)",
+				layout.Name, name, strings.Join(names, ", "))
+		}
+	}
+
 	// Apply layout variable defaults
 	for _, variable := range layout.Manifest.Variables {
 		if _, exists := data.CustomVariables[variable.Name]; !exists && variable.Default != "" {
@@ -208,30 +379,91 @@ func (m *Manager) validateProjectData(layout *Layout, data *ProjectData) error {
 		}
 
 		// Check required variables
-		if variable.Required {
-			if _, exists := data.CustomVariables[variable.Name]; !exists {
+		value, exists := data.CustomVariables[variable.Name]
+		if !exists {
+			if variable.Required {
 				return fmt.Errorf("required variable '%s' not provided", variable.Name)
 			}
+			continue
+		}
+
+		// Check the value against the variable's type
+		value, err := variable.Validate(value)
+		if err != nil {
+			return err
+		}
+		data.CustomVariables[variable.Name] = value
+	}
+
+	if err := m.validateFeatures(layout, data); err != nil {
+		return err
+	}
+
+	return m.validateOptions(layout, data)
+}
+
+// validateFeatures turns on the features the layout declares unless they
+// were turned off, which is only allowed for declared features
+func (m *Manager) validateFeatures(layout *Layout, data *ProjectData) error {
+	if data.Features == nil {
+		data.Features = make(map[string]bool)
+	}
+
+	for name, enabled := range data.Features {
+		if !enabled && !contains(layout.Manifest.Features, name) {
+			return fmt.Errorf("layout '%s' has no %s feature (features: %s)",
+				layout.Name, name, strings.Join(layout.Manifest.Features, ", "))
+		}
+	}
+
+	for _, name := range layout.Manifest.Features {
+		if _, set := data.Features[name]; !set {
+			data.Features[name] = true
 		}
 	}
 
 	return nil
 }
 
-// createDirectories creates the project directory structure
-func (m *Manager) createDirectories(layout *Layout, projectPath string, data ProjectData) error {
-	// Create project root
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
+// validateOptions checks the chosen options against the choices the layout
+// declares and fills in the defaults of options that were not chosen
+func (m *Manager) validateOptions(layout *Layout, data *ProjectData) error {
+	if data.Options == nil {
+		data.Options = make(map[string]string)
+	}
+
+	for name, value := range data.Options {
+		option, exists := layout.Manifest.Options[name]
+		if !exists {
+			return fmt.Errorf("layout '%s' has no %s option", layout.Name, name)
+		}
+		if !contains(option.Choices, value) {
+			return fmt.Errorf("invalid %s '%s' for layout '%s' (choose from %s)",
+				name, value, layout.Name, strings.Join(option.Choices, ", "))
+		}
+	}
+
+	for name, option := range layout.Manifest.Options {
+		if _, exists := data.Options[name]; !exists {
+			data.Options[name] = option.Default
+		}
 	}
 
+	return nil
+}
+
+// createDirectories creates the project directory structure
+func (m *Manager) createDirectories(layout *Layout, area *staging.Area, data ProjectData) error {
 	// Create directories from layout manifest
 	for _, dir := range layout.Manifest.Structure.Directories {
 		// Process template in directory path
 		dirPath := m.processTemplatePath(dir.Path, data)
-		fullPath := filepath.Join(projectPath, dirPath)
-
-		if err := os.MkdirAll(fullPath, 0755); err != nil {
+		if include, err := m.included(dir.When, data); err != nil {
+			return fmt.Errorf("directory %s: %w", dirPath, err)
+		} else if !include {
+			continue
+		}
+		if err := area.MkdirAll(dirPath); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dirPath, err)
 		}
 	}
@@ -239,72 +471,72 @@ func (m *Manager) createDirectories(layout *Layout, projectPath string, data Pro
 	return nil
 }
 
-// generateFiles generates files from templates
-func (m *Manager) generateFiles(layout *Layout, projectPath string, data ProjectData) error {
-	// Create template functions
-	funcMap := template.FuncMap{
-		"lower":      toLower,
-		"upper":      toUpper,
-		"capitalize": capitalize,
-		"snake":      toSnakeCase,
-		"camel":      toCamelCase,
-		"pascal":     toPascalCase,
-		"kebab":      toKebabCase,
-		"default":    defaultString,
-		"snake_case": toSnakeCase,
-		"title":      toPascalCase,
-		"plural":     pluralize,
-	}
+// generateFiles generates files from templates and records them in the lock
+func (m *Manager) generateFiles(layout *Layout, area *staging.Area, data ProjectData, lock *Lock) error {
 
 	// Generate each file
 	for _, file := range layout.Manifest.Structure.Files {
-		// Get template content
-		templateContent, exists := layout.Templates[file.Template]
+		if include, err := m.included(file.When, data); err != nil {
+			return fmt.Errorf("file %s: %w", m.processTemplatePath(file.Target, data), err)
+		} else if !include {
+			m.logger.Log(context.Background(), logging.LevelTrace, "skipped file", "path", file.Target, "when", file.When)
+			continue
+		}
+
+		// Get template content, picking the variant of the chosen options
+		templatePath := m.processTemplatePath(file.Template, data)
+		templateContent, exists := layout.Templates[templatePath]
 		if !exists {
-			return fmt.Errorf("template not found: %s", file.Template)
+			return fmt.Errorf("template not found: %s", templatePath)
 		}
 
 		// Parse template
-		tmpl, err := template.New(filepath.Base(file.Template)).Funcs(funcMap).Parse(templateContent)
+		tmpl, err := template.New(filepath.Base(templatePath)).Funcs(templateFuncs()).Parse(templateContent)
 		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", file.Template, err)
+			return fmt.Errorf("failed to parse template %s: %w", templatePath, err)
 		}
 
 		// Process target path
 		targetPath := m.processTemplatePath(file.Target, data)
-		fullPath := filepath.Join(projectPath, targetPath)
-
-		// Ensure target directory exists
-		targetDir := filepath.Dir(fullPath)
-		if err := os.MkdirAll(targetDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory for %s: %w", targetPath, err)
-		}
-
-		// Create target file
-		outFile, err := os.Create(fullPath)
-		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", targetPath, err)
-		}
-		defer outFile.Close()
 
 		// Execute template
-		if err := tmpl.Execute(outFile, data); err != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
 			return fmt.Errorf("failed to execute template for %s: %w", targetPath, err)
 		}
 
 		// Set appropriate permissions
 		mode := os.FileMode(0644)
-		if filepath.Base(fullPath) == "main.go" || filepath.Ext(fullPath) == ".sh" {
+		if filepath.Base(targetPath) == "main.go" || filepath.Ext(targetPath) == ".sh" {
 			mode = 0755
 		}
-		if err := os.Chmod(fullPath, mode); err != nil {
-			return fmt.Errorf("failed to set permissions for %s: %w", targetPath, err)
+		if err := area.WriteFile(targetPath, buf.Bytes(), mode); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", targetPath, err)
 		}
+		lock.Record(targetPath, buf.Bytes(), LockedFile{Template: templatePath})
+		m.logger.Log(context.Background(), logging.LevelTrace, "rendered file", "path", targetPath, "template", templatePath)
 	}
 
 	return nil
 }
 
+// templateFuncs returns the functions available to layout templates
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"lower":      toLower,
+		"upper":      toUpper,
+		"capitalize": capitalize,
+		"snake":      toSnakeCase,
+		"camel":      toCamelCase,
+		"pascal":     toPascalCase,
+		"kebab":      toKebabCase,
+		"default":    defaultString,
+		"snake_case": toSnakeCase,
+		"title":      toPascalCase,
+		"plural":     pluralize,
+	}
+}
+
 // processTemplatePath processes template variables in paths
 func (m *Manager) processTemplatePath(path string, data ProjectData) string {
 	// Simple variable replacement for paths
@@ -312,91 +544,146 @@ func (m *Manager) processTemplatePath(path string, data ProjectData) string {
 	result = replaceVar(result, "ProjectName", data.ProjectName)
 	result = replaceVar(result, "ModuleName", data.ModuleName)
 
-	// Also check custom variables
+	// Also check custom variables, by name or as in templates
 	for key, value := range data.CustomVariables {
 		result = replaceVar(result, key, value)
+		result = replaceVar(result, "CustomVariables."+key, value)
+	}
+	for key, value := range data.Options {
+		result = replaceVar(result, "Options."+key, value)
 	}
 
 	return result
 }
 
+// ComponentOption configures component generation
+type ComponentOption func(*componentOptions)
+
+// componentOptions holds optional settings for GenerateComponent
+type componentOptions struct {
+	data      map[string]interface{}
+	conflicts *conflict.Resolver
+}
+
+// WithComponentConflicts resolves a component file that already exists.
+// Without it the file is overwritten.
+func WithComponentConflicts(resolver *conflict.Resolver) ComponentOption {
+	return func(o *componentOptions) {
+		o.conflicts = resolver
+	}
+}
+
+// WithComponentData adds values to the data passed to component templates.
+// Keys are available in templates as top-level fields, e.g. {{.Fields}}.
+func WithComponentData(data map[string]interface{}) ComponentOption {
+	return func(o *componentOptions) {
+		for k, v := range data {
+			o.data[k] = v
+		}
+	}
+}
+
 // GenerateComponent generates a component using the layout's component templates
-func (m *Manager) GenerateComponent(ctx context.Context, layoutName string, componentType string, componentName string, projectPath string) error {
+func (m *Manager) GenerateComponent(ctx context.Context, layoutName string, componentType string, componentName string, projectPath string, opts ...ComponentOption) error {
+	options := &componentOptions{data: make(map[string]interface{})}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	// Load layout
 	layout, err := m.GetLayout(ctx, layoutName)
 	if err != nil {
 		return fmt.Errorf("failed to load layout: %w", err)
 	}
 
+	target, content, err := m.renderComponent(layout, componentType, componentName, options.data)
+	if err != nil {
+		return err
+	}
+
+	area := staging.New(projectPath)
+	if err := area.WriteFile(target, content, 0644); err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	targetFile := filepath.Join(projectPath, target)
+	if options.conflicts != nil {
+		options.conflicts.SetBaseline(Baseline(projectPath))
+		decisions, err := options.conflicts.Resolve(area, []string{target})
+		if err != nil {
+			return err
+		}
+		if len(decisions) == 1 && decisions[0].Action == conflict.Skipped {
+			m.logger.Info("Kept existing "+componentType, "path", targetFile)
+			return nil
+		}
+	}
+
+	// Create file
+	if err := area.Commit(); err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	// Record how the component was rendered, so it can be rendered again
+	if err := recordComponent(projectPath, target, content, LockedComponent{
+		Type: componentType,
+		Name: componentName,
+		Data: plainData(options.data),
+	}); err != nil {
+		return err
+	}
+
+	m.logger.Info("Generated "+componentType, "path", targetFile)
+	return nil
+}
+
+// renderComponent renders a component template, returning the path of the
+// component relative to the project root and its content
+func (m *Manager) renderComponent(layout *Layout, componentType, componentName string, extra map[string]interface{}) (string, []byte, error) {
 	// Check if component type exists
 	component, exists := layout.Manifest.Components[componentType]
 	if !exists {
-		return fmt.Errorf("component type '%s' not found in layout", componentType)
+		return "", nil, fmt.Errorf("component type '%s' not found in layout", componentType)
 	}
 
 	// Get template content
 	templateContent, exists := layout.Templates[component.Template]
 	if !exists {
-		return fmt.Errorf("template not found: %s", component.Template)
+		return "", nil, fmt.Errorf("template not found: %s", component.Template)
 	}
 
 	// Create template data
-	data := struct {
-		ComponentName string
-		ModuleName    string
-		Name          string
-		PackageName   string
-		Type          string
-	}{
-		ComponentName: componentName,
-		ModuleName:    "example.com/project", // TODO: Get from project
-		Name:          componentName,
-		PackageName:   toLower(componentName),
-		Type:          componentType,
+	data := map[string]interface{}{
+		"ComponentName": componentName,
+		"ModuleName":    "example.com/project", // TODO: Get from project
+		"Name":          componentName,
+		"PackageName":   toLower(componentName),
+		"Type":          componentType,
 	}
-
-	// Parse and execute template
-	funcMap := template.FuncMap{
-		"lower":      toLower,
-		"upper":      toUpper,
-		"capitalize": capitalize,
-		"snake":      toSnakeCase,
-		"camel":      toCamelCase,
-		"pascal":     toPascalCase,
-		"kebab":      toKebabCase,
-		"default":    defaultString,
-		"snake_case": toSnakeCase,
-		"title":      toPascalCase,
-		"plural":     pluralize,
+	for k, v := range extra {
+		data[k] = v
 	}
 
-	tmpl, err := template.New(componentName).Funcs(funcMap).Parse(templateContent)
+	// Parse and execute template
+	tmpl, err := template.New(componentName).Funcs(templateFuncs()).Parse(templateContent)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
-
-	// Determine target path
-	targetDir := filepath.Join(projectPath, component.TargetDir)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create target directory: %w", err)
+		return "", nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	targetFile := filepath.Join(targetDir, toLower(componentName)+".go")
-
-	// Create file
-	outFile, err := os.Create(targetFile)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	// Execute template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", nil, fmt.Errorf("failed to execute template: %w", err)
 	}
-	defer outFile.Close()
 
-	// Execute template
-	if err := tmpl.Execute(outFile, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	// Tidy up alignment of generated Go code; keep the raw output if it
+	// does not parse so the user can see what went wrong
+	content := buf.Bytes()
+	if formatted, err := format.Source(content); err == nil {
+		content = formatted
 	}
 
-	fmt.Printf("Generated %s: %s\n", componentType, targetFile)
-	return nil
+	return filepath.Join(component.TargetDir, toLower(componentName)+".go"), content, nil
 }
 
 // replaceVar replaces {{.Var}} with value in string
@@ -0,0 +1,358 @@
+package layout
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryIndexFile is the name of the index of a layout registry
+const RegistryIndexFile = "index.json"
+
+// registryIndexYAMLFile is the name of an index written by hand in YAML,
+// read when a registry has no index.json
+const registryIndexYAMLFile = "index.yaml"
+
+// registryIndexVersion is the version of the index format
+const registryIndexVersion = "1"
+
+// IndexEntry describes a packed version of a layout in a registry index
+type IndexEntry struct {
+	Name        string   `json:"name" yaml:"name"`
+	Version     string   `json:"version" yaml:"version"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Author      string   `json:"author,omitempty" yaml:"author,omitempty"`
+	Features    []string `json:"features,omitempty" yaml:"features,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Archive is the path of the archive relative to the index, or its URL
+	Archive string `json:"archive" yaml:"archive"`
+	// Checksum is the SHA-256 of the archive, in hex
+	Checksum    string    `json:"checksum" yaml:"checksum"`
+	Size        int64     `json:"size" yaml:"size"`
+	PublishedAt time.Time `json:"published_at,omitempty" yaml:"published_at,omitempty"`
+}
+
+// RegistryIndex lists the layouts a registry offers, one entry per version
+type RegistryIndex struct {
+	Version string       `json:"version" yaml:"version"`
+	Layouts []IndexEntry `json:"layouts" yaml:"layouts"`
+}
+
+// Package is a layout packed into an archive
+type Package struct {
+	Entry IndexEntry `json:"entry" yaml:"entry"`
+	// Path is where the archive was written
+	Path string `json:"path" yaml:"path"`
+	// ChecksumPath is where its checksum was written
+	ChecksumPath string `json:"checksum_path" yaml:"checksum_path"`
+}
+
+// packedFile reports whether a file of a layout belongs in its archive:
+// the manifest, the templates and the README and LICENSE
+func packedFile(rel string) bool {
+	if rel == ManifestFileName {
+		return true
+	}
+	first, _, nested := strings.Cut(rel, "/")
+	if nested {
+		return first == projectTemplatesDir || first == componentTemplatesDir
+	}
+	upper := strings.ToUpper(rel)
+	return strings.HasPrefix(upper, "README") || strings.HasPrefix(upper, "LICENSE")
+}
+
+// PackLayout packs the layout in dir into <name>-<version>.tar.gz in outDir,
+// with its SHA-256 next to it in a .sha256 file. Layouts that fail
+// validation are not packed. The same layout always packs to the same bytes:
+// files are stored in order, with no owners and a fixed time and modes.
+func (m *Manager) PackLayout(ctx context.Context, dir, outDir string) (*Package, error) {
+	problems, err := m.ValidateDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return nil, fmt.Errorf("layout has %d error(s); see foundry layout validate %s", errorCount, dir)
+	}
+
+	manifest, err := (&Loader{}).loadManifest(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	archive, err := packArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(archive)
+	checksum := fmt.Sprintf("%x", sum)
+
+	name := fmt.Sprintf("%s-%s.tar.gz", manifest.Name, manifest.Version)
+	pkg := &Package{
+		Entry: IndexEntry{
+			Name:        manifest.Name,
+			Version:     manifest.Version,
+			Description: manifest.Description,
+			Author:      manifest.Author,
+			Features:    manifest.Features,
+			Tags:        manifest.Tags,
+			Archive:     name,
+			Checksum:    checksum,
+			Size:        int64(len(archive)),
+		},
+		Path:         filepath.Join(outDir, name),
+		ChecksumPath: filepath.Join(outDir, name+".sha256"),
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	if err := os.WriteFile(pkg.Path, archive, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	// The format of sha256sum, so the archive can be checked with it
+	if err := os.WriteFile(pkg.ChecksumPath, []byte(checksum+"  "+name+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write checksum: %w", err)
+	}
+	m.logger.Debug("packed layout", "layout", manifest.Name, "version", manifest.Version, "bytes", len(archive))
+	return pkg, nil
+}
+
+// packArchive returns the tar.gz of the files of a layout
+func packArchive(dir string) ([]byte, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel != "." && (contains(extractSkipDirs, entry.Name()) || rel == SnapshotDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && packedFile(rel) {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(gz)
+	for _, rel := range paths {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     rel,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  time.Unix(0, 0),
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %w", rel, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %w", rel, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack layout: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack layout: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PublishLayout packs the layout in dir into the registry, a directory or
+// file:// URL, under <name>/ and adds it to the index of the registry.
+// A version that was published with other contents is only replaced with
+// force; publishing the same contents again changes nothing.
+func (m *Manager) PublishLayout(ctx context.Context, dir, registry string, force bool) (*IndexEntry, bool, error) {
+	registryDir, err := registryPath(registry)
+	if err != nil {
+		return nil, false, err
+	}
+	index, err := LoadRegistryIndex(registryDir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	staging, err := os.MkdirTemp("", "foundry-publish-")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	pkg, err := m.PackLayout(ctx, dir, staging)
+	if err != nil {
+		return nil, false, err
+	}
+	entry := pkg.Entry
+	entry.Archive = path.Join(entry.Name, entry.Archive)
+
+	position := -1
+	for i, existing := range index.Layouts {
+		if existing.Name == entry.Name && existing.Version == entry.Version {
+			position = i
+		}
+	}
+	if position >= 0 {
+		existing := index.Layouts[position]
+		if existing.Checksum == entry.Checksum {
+			return &existing, false, nil
+		}
+		if !force {
+			return nil, false, fmt.Errorf("%s %s is already published with other contents; bump the version or use --force", entry.Name, entry.Version)
+		}
+	}
+
+	target := filepath.Join(registryDir, filepath.FromSlash(entry.Archive))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	for _, file := range [][2]string{{pkg.Path, target}, {pkg.ChecksumPath, target + ".sha256"}} {
+		if err := copyFile(file[0], file[1]); err != nil {
+			return nil, false, fmt.Errorf("failed to copy archive to registry: %w", err)
+		}
+	}
+
+	entry.PublishedAt = time.Now().UTC().Truncate(time.Second)
+	if position >= 0 {
+		index.Layouts[position] = entry
+	} else {
+		index.Layouts = append(index.Layouts, entry)
+	}
+	if err := index.Save(registryDir); err != nil {
+		return nil, false, err
+	}
+	m.logger.Debug("published layout", "layout", entry.Name, "version", entry.Version, "registry", registryDir)
+	return &entry, true, nil
+}
+
+// registryPath returns the directory of a registry given as a path or a
+// file:// URL. Other registries are served by a server of their own and are
+// published to through the directory it serves.
+func registryPath(registry string) (string, error) {
+	if !strings.Contains(registry, "://") {
+		return expandPath(registry), nil
+	}
+	u, err := url.Parse(registry)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry URL %s: %w", registry, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("cannot publish to %s registry %s; publish to the directory it serves instead", u.Scheme, registry)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// LoadRegistryIndex reads the index of the registry in dir, index.json or
+// else index.yaml; a registry without one has no layouts yet
+func LoadRegistryIndex(dir string) (*RegistryIndex, error) {
+	for _, name := range []string{RegistryIndexFile, registryIndexYAMLFile} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read registry index: %w", err)
+		}
+		return parseRegistryIndex(filepath.Join(dir, name), content)
+	}
+	return &RegistryIndex{Version: registryIndexVersion, Layouts: []IndexEntry{}}, nil
+}
+
+// parseRegistryIndex parses an index read from location, as YAML if its
+// name says so and as JSON otherwise
+func parseRegistryIndex(location string, content []byte) (*RegistryIndex, error) {
+	var index RegistryIndex
+	var err error
+	switch path.Ext(location) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &index)
+	default:
+		err = json.Unmarshal(content, &index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry index %s: %w", location, err)
+	}
+	return &index, nil
+}
+
+// Save writes the index to the registry in dir, sorted by layout and
+// version. The index is replaced at once, so readers never see half of it.
+func (i *RegistryIndex) Save(dir string) error {
+	sort.SliceStable(i.Layouts, func(a, b int) bool {
+		if i.Layouts[a].Name != i.Layouts[b].Name {
+			return i.Layouts[a].Name < i.Layouts[b].Name
+		}
+		return i.Layouts[a].Version < i.Layouts[b].Version
+	})
+	i.Version = registryIndexVersion
+
+	content, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registry index: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create registry %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".index-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, RegistryIndexFile)); err != nil {
+		return fmt.Errorf("failed to write registry index: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package layout
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
@@ -16,14 +18,20 @@ type Registry struct {
 	configPath string
 	config     *LayoutRegistry
 	layouts    map[string]LayoutListEntry
+	logger     *slog.Logger
+	client     *http.Client
 	mu         sync.RWMutex
 }
 
 // NewRegistry creates a new registry instance
-func NewRegistry(configPath string) (*Registry, error) {
+func NewRegistry(configPath string, logger *slog.Logger) (*Registry, error) {
 	registry := &Registry{
 		configPath: configPath,
 		layouts:    make(map[string]LayoutListEntry),
+		logger:     logger,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
 	}
 
 	// Load or create default config
@@ -38,7 +46,7 @@ func NewRegistry(configPath string) (*Registry, error) {
 	// Load layout index
 	if err := registry.loadLayouts(); err != nil {
 		// Non-fatal, start with empty index
-		fmt.Printf("Warning: failed to load layout index: %v\n", err)
+		logger.Warn("failed to load layout index", "error", err)
 	}
 
 	return registry, nil
@@ -291,16 +299,6 @@ func (r *Registry) saveIndex() error {
 	return nil
 }
 
-// RefreshRemoteRegistries fetches latest layout lists from remote registries
-func (r *Registry) RefreshRemoteRegistries() error {
-	// TODO: Implement fetching from remote registries
-	// This would:
-	// 1. Fetch layout lists from each configured registry
-	// 2. Merge with local layouts
-	// 3. Update the index
-	return nil
-}
-
 // GetConfig returns the current registry configuration
 func (r *Registry) GetConfig() *LayoutRegistry {
 	r.mu.RLock()
@@ -0,0 +1,401 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// registriesCacheDir is the directory of the cache that holds the last
+// index fetched from each registry, in <name>/index.json
+const registriesCacheDir = "registries"
+
+// RegistryStatus is the outcome of refreshing the index of a registry
+type RegistryStatus struct {
+	Name    string `json:"name" yaml:"name"`
+	URL     string `json:"url" yaml:"url"`
+	Layouts int    `json:"layouts" yaml:"layouts"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SearchResult is a layout found in the index of a registry. Its archive
+// is a path or URL of its own, no longer relative to the index.
+type SearchResult struct {
+	Registry   string `json:"registry" yaml:"registry"`
+	IndexEntry `yaml:",inline"`
+}
+
+// RefreshRemoteRegistries fetches the index of each configured registry
+// into the cache. Registries are directories, file:// URLs or HTTP servers;
+// one that cannot be read keeps the index fetched from it last.
+func (r *Registry) RefreshRemoteRegistries(ctx context.Context) []RegistryStatus {
+	config := r.GetConfig()
+	statuses := make([]RegistryStatus, 0, len(config.Registries))
+	for _, name := range sortedKeys(config.Registries) {
+		status := RegistryStatus{Name: name, URL: config.Registries[name].URL}
+		index, err := fetchRegistryIndex(ctx, r.client, status.URL)
+		if err == nil {
+			err = index.Save(r.registryCachePath(name))
+		}
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Layouts = len(index.Layouts)
+			r.logger.Debug("refreshed registry", "registry", name, "layouts", status.Layouts)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// registryCachePath returns the directory of the cached index of a registry
+func (r *Registry) registryCachePath(name string) string {
+	return filepath.Join(r.GetConfig().Cache.Directory, registriesCacheDir, name)
+}
+
+// Search returns the newest version of each layout in the cached registry
+// indexes whose name, description, tags or features contain term and that
+// has all of tags. Matching ignores case; an empty term matches every layout.
+func (r *Registry) Search(term string, tags []string) ([]SearchResult, error) {
+	term = strings.ToLower(term)
+	results := []SearchResult{}
+	err := r.eachCachedLayout(func(result SearchResult) {
+		if matchesTerm(result.IndexEntry, term) && hasTags(result.IndexEntry, tags) {
+			results = append(results, result)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Name != results[b].Name {
+			return results[a].Name < results[b].Name
+		}
+		return results[a].Registry < results[b].Registry
+	})
+	return results, nil
+}
+
+// FindRegistryLayout returns the newest version of a layout in the cached
+// registry indexes that satisfies constraint. Trusted registries are
+// searched before the others.
+func (r *Registry) FindRegistryLayout(name, constraint string) (*SearchResult, error) {
+	config := r.GetConfig()
+	var found *SearchResult
+	err := r.eachCachedVersion(func(result SearchResult) {
+		if result.Name != name || !versionMatches(result.Version, constraint) {
+			return
+		}
+		if found == nil {
+			found = &result
+			return
+		}
+		trusted, foundTrusted := config.Registries[result.Registry].Trusted, config.Registries[found.Registry].Trusted
+		if trusted != foundTrusted {
+			if trusted {
+				found = &result
+			}
+			return
+		}
+		if found.Registry == result.Registry && compareVersions(result.Version, found.Version) > 0 {
+			found = &result
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		if constraint != "" {
+			return nil, fmt.Errorf("layout '%s@%s' not found in any registry", name, constraint)
+		}
+		return nil, fmt.Errorf("layout '%s' not found in any registry", name)
+	}
+	return found, nil
+}
+
+// eachCachedLayout calls fn with the newest version of each layout of each
+// registry
+func (r *Registry) eachCachedLayout(fn func(SearchResult)) error {
+	newest := make(map[[2]string]SearchResult)
+	var order [][2]string
+	err := r.eachCachedVersion(func(result SearchResult) {
+		key := [2]string{result.Registry, result.Name}
+		current, seen := newest[key]
+		if !seen {
+			order = append(order, key)
+		}
+		if !seen || compareVersions(result.Version, current.Version) > 0 {
+			newest[key] = result
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range order {
+		fn(newest[key])
+	}
+	return nil
+}
+
+// eachCachedVersion calls fn with every entry of the cached indexes of the
+// configured registries, in order of registry name
+func (r *Registry) eachCachedVersion(fn func(SearchResult)) error {
+	for _, name := range sortedKeys(r.GetConfig().Registries) {
+		index, err := LoadRegistryIndex(r.registryCachePath(name))
+		if err != nil {
+			return err
+		}
+		for _, entry := range index.Layouts {
+			fn(SearchResult{Registry: name, IndexEntry: entry})
+		}
+	}
+	return nil
+}
+
+// matchesTerm reports whether the name, description, tags or features of
+// an entry contain term, which is in lower case
+func matchesTerm(entry IndexEntry, term string) bool {
+	fields := append([]string{entry.Name, entry.Description}, entry.Tags...)
+	fields = append(fields, entry.Features...)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether an entry has all of tags, ignoring case
+func hasTags(entry IndexEntry, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, have := range entry.Tags {
+			if strings.EqualFold(have, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares two versions part by part, numerically where
+// both parts are numbers
+func compareVersions(a, b string) int {
+	left := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	right := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(left) || i < len(right); i++ {
+		var x, y string
+		if i < len(left) {
+			x = left[i]
+		}
+		if i < len(right) {
+			y = right[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil && nx != ny:
+			if nx < ny {
+				return -1
+			}
+			return 1
+		case (errX != nil || errY != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// fetchRegistryIndex reads the index of a registry: the index file the
+// location names, or index.json or else index.yaml in it. Archive paths
+// of the entries are resolved against the index.
+func fetchRegistryIndex(ctx context.Context, client *http.Client, location string) (*RegistryIndex, error) {
+	candidates := []string{location}
+	switch path.Ext(location) {
+	case ".json", ".yaml", ".yml":
+	default:
+		candidates = []string{
+			joinLocation(location, RegistryIndexFile),
+			joinLocation(location, registryIndexYAMLFile),
+		}
+	}
+
+	var err error
+	for _, candidate := range candidates {
+		var content []byte
+		content, err = readLocation(ctx, client, candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := parseRegistryIndex(candidate, content)
+		if err != nil {
+			return nil, err
+		}
+		for i, entry := range index.Layouts {
+			if index.Layouts[i].Archive, err = resolveLocation(candidate, entry.Archive); err != nil {
+				return nil, err
+			}
+		}
+		return index, nil
+	}
+	return nil, fmt.Errorf("no registry index at %s: %w", location, err)
+}
+
+// joinLocation joins a name to a directory given as a path or URL
+func joinLocation(base, name string) string {
+	if strings.Contains(base, "://") {
+		return strings.TrimSuffix(base, "/") + "/" + name
+	}
+	return filepath.Join(expandPath(base), name)
+}
+
+// resolveLocation resolves ref, a path or URL, against the location of the
+// index that names it
+func resolveLocation(index, ref string) (string, error) {
+	if !strings.Contains(index, "://") {
+		if strings.Contains(ref, "://") || filepath.IsAbs(ref) {
+			return ref, nil
+		}
+		return filepath.Join(filepath.Dir(index), filepath.FromSlash(ref)), nil
+	}
+
+	base, err := url.Parse(index)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry URL %s: %w", index, err)
+	}
+	target, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid archive location %s: %w", ref, err)
+	}
+	return base.ResolveReference(target).String(), nil
+}
+
+// readLocation reads a file given as a path, file:// URL or HTTP URL
+func readLocation(ctx context.Context, client *http.Client, location string) ([]byte, error) {
+	in, err := openLocation(ctx, client, location)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return io.ReadAll(in)
+}
+
+// openLocation opens a file given as a path, file:// URL or HTTP URL. Files
+// that do not exist, including those a server answers 404 for, give an
+// error that matches fs.ErrNotExist.
+func openLocation(ctx context.Context, client *http.Client, location string) (io.ReadCloser, error) {
+	if !strings.Contains(location, "://") {
+		return os.Open(expandPath(location))
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", location, err)
+	}
+	switch u.Scheme {
+	case "file":
+		return os.Open(filepath.FromSlash(u.Path))
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %s in %s", u.Scheme, location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Foundry-CLI/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", location, fs.ErrNotExist)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, location)
+	}
+}
+
+// AddRegistryLayout resolves a layout such as "company-std" or
+// "company-std@1.x" through the registries, downloads the newest version
+// that matches and registers it as alias, or under its own name without
+// one. The indexes are refreshed first if no cached index lists the layout.
+func (m *Manager) AddRegistryLayout(ctx context.Context, ref, alias string) (*SearchResult, error) {
+	name, constraint := parseExtends(ref)
+	result, err := m.registry.FindRegistryLayout(name, constraint)
+	if err != nil {
+		m.registry.RefreshRemoteRegistries(ctx)
+		if result, err = m.registry.FindRegistryLayout(name, constraint); err != nil {
+			return nil, err
+		}
+	}
+
+	config := m.registry.GetConfig()
+	if !config.Registries[result.Registry].Trusted && !config.Security.AllowUntrusted {
+		return nil, fmt.Errorf("registry %s is not trusted; mark it trusted in %s or set security.allow_untrusted",
+			result.Registry, m.registry.configPath)
+	}
+	if alias == "" {
+		alias = result.Name
+	}
+	if contains(GetEmbeddedLayouts(), alias) {
+		return nil, fmt.Errorf("'%s' is a built-in layout; add the layout under another name with --name", alias)
+	}
+
+	source := LayoutSource{
+		Type:     "remote",
+		Location: result.Archive,
+		Ref:      result.Version,
+	}
+	if config.Security.VerifyChecksums {
+		source.Checksum = result.Checksum
+	}
+	if _, err := m.loader.loadRemote(ctx, alias, source); err != nil {
+		return nil, err
+	}
+
+	entry := LayoutListEntry{
+		Name:        alias,
+		Version:     result.Version,
+		Description: result.Description,
+		Source:      source,
+		Installed:   true,
+		UpdatedAt:   time.Now(),
+	}
+	if err := m.registry.AddLayout(entry); err != nil {
+		return nil, err
+	}
+	m.logger.Debug("added layout", "layout", alias, "registry", result.Registry, "version", result.Version)
+	return result, nil
+}
+
+// SearchLayouts searches the cached registry indexes; see Registry.Search
+func (m *Manager) SearchLayouts(term string, tags []string) ([]SearchResult, error) {
+	return m.registry.Search(term, tags)
+}
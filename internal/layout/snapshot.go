@@ -0,0 +1,265 @@
+package layout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shapestone/foundry/internal/diff"
+)
+
+const (
+	// SnapshotDir is the directory of a layout holding its snapshot tests,
+	// one subdirectory per case
+	SnapshotDir = "testdata"
+	// GoldenDir is the directory of a case holding the project the layout
+	// is expected to generate for the answers of the case
+	GoldenDir = "golden"
+)
+
+// SnapshotStatus is the outcome of a snapshot case
+type SnapshotStatus string
+
+const (
+	// SnapshotPassed cases generate their golden project
+	SnapshotPassed SnapshotStatus = "passed"
+	// SnapshotFailed cases generate something else, invalid Go or nothing
+	SnapshotFailed SnapshotStatus = "failed"
+	// SnapshotUpdated cases had their golden project rewritten
+	SnapshotUpdated SnapshotStatus = "updated"
+)
+
+// Mismatch kinds of the files of a snapshot case
+const (
+	// MismatchMissing files are golden but no longer generated
+	MismatchMissing = "missing"
+	// MismatchUnexpected files are generated but not golden
+	MismatchUnexpected = "unexpected"
+	// MismatchChanged files are generated with other content
+	MismatchChanged = "changed"
+	// MismatchInvalidGo files are Go files that do not parse
+	MismatchInvalidGo = "invalid_go"
+)
+
+// SnapshotMismatch is a file a case does not generate as expected
+type SnapshotMismatch struct {
+	Path    string `json:"path" yaml:"path"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// Change turns the golden file into the generated one
+	Change diff.Change `json:"-" yaml:"-"`
+}
+
+// SnapshotCase is the outcome of rendering a layout with the answers of a
+// case and comparing it with its golden project
+type SnapshotCase struct {
+	Name       string             `json:"name" yaml:"name"`
+	Status     SnapshotStatus     `json:"status" yaml:"status"`
+	Files      int                `json:"files" yaml:"files"`
+	Error      string             `json:"error,omitempty" yaml:"error,omitempty"`
+	Mismatches []SnapshotMismatch `json:"mismatches" yaml:"mismatches"`
+}
+
+// TestLayout renders the layout in dir with the answers file of each case
+// under its testdata directory and compares the output with the golden
+// project of the case. With update the golden projects are rewritten
+// instead. Generated Go files must parse either way.
+func (m *Manager) TestLayout(ctx context.Context, dir string, update bool) ([]SnapshotCase, error) {
+	layout, err := m.loader.loadLocal(ctx, "", LayoutSource{Type: "local", Location: dir})
+	if err != nil {
+		return nil, err
+	}
+	layout.Name = layout.Manifest.Name
+	if layout, err = m.loader.resolveParent(ctx, layout, nil); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, SnapshotDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s has no %s directory with cases to test", dir, SnapshotDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test cases: %w", err)
+	}
+
+	var cases []SnapshotCase
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(dir, SnapshotDir, entry.Name())
+		result := m.testCase(layout, caseDir, update)
+		result.Name = entry.Name()
+		m.logger.Debug("tested layout case", "case", result.Name, "status", result.Status, "files", result.Files)
+		cases = append(cases, result)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("%s has no cases; add %s/<case>/%s", filepath.Join(dir, SnapshotDir), SnapshotDir, AnswersFileName)
+	}
+	return cases, nil
+}
+
+// testCase renders a layout for one case and checks the output
+func (m *Manager) testCase(layout *Layout, caseDir string, update bool) SnapshotCase {
+	result := SnapshotCase{Status: SnapshotFailed, Mismatches: []SnapshotMismatch{}}
+
+	answers, err := LoadAnswers(filepath.Join(caseDir, AnswersFileName))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	data := answers.ProjectData()
+	if err := m.validateProjectData(layout, &data); err != nil {
+		result.Error = fmt.Sprintf("validation failed: %v", err)
+		return result
+	}
+	area, _, err := m.renderProject(layout, caseDir, data)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	generated := make(map[string][]byte)
+	for _, path := range area.Files() {
+		content, _, _ := area.ReadFile(path)
+		generated[filepath.ToSlash(path)] = content
+	}
+	result.Files = len(generated)
+
+	goldenDir := filepath.Join(caseDir, GoldenDir)
+	golden, err := readTree(goldenDir)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Golden projects are never updated to invalid Go
+	if update {
+		if result.Mismatches = invalidGo(generated); len(result.Mismatches) > 0 {
+			return result
+		}
+		result.Status = SnapshotPassed
+		if !sameTree(golden, generated) {
+			if err := writeTree(goldenDir, generated); err != nil {
+				result.Status = SnapshotFailed
+				result.Error = err.Error()
+				return result
+			}
+			result.Status = SnapshotUpdated
+		}
+		return result
+	}
+
+	result.Mismatches = append(compareTrees(golden, generated), invalidGo(generated)...)
+	sort.SliceStable(result.Mismatches, func(i, j int) bool {
+		return result.Mismatches[i].Path < result.Mismatches[j].Path
+	})
+	if len(result.Mismatches) == 0 {
+		result.Status = SnapshotPassed
+	}
+	return result
+}
+
+// compareTrees returns the files that differ between the golden and the
+// generated files
+func compareTrees(golden, generated map[string][]byte) []SnapshotMismatch {
+	var mismatches []SnapshotMismatch
+	for _, path := range sortedKeys(generated) {
+		want, ok := golden[path]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, SnapshotMismatch{
+				Path: path, Kind: MismatchUnexpected, Message: "generated but not in the golden project",
+				Change: diff.Change{Path: path, After: generated[path]},
+			})
+		case !bytes.Equal(want, generated[path]):
+			mismatches = append(mismatches, SnapshotMismatch{
+				Path: path, Kind: MismatchChanged, Message: "differs from the golden file",
+				Change: diff.Change{Path: path, Before: want, After: generated[path]},
+			})
+		}
+	}
+	for _, path := range sortedKeys(golden) {
+		if _, ok := generated[path]; !ok {
+			mismatches = append(mismatches, SnapshotMismatch{
+				Path: path, Kind: MismatchMissing, Message: "in the golden project but no longer generated",
+				Change: diff.Change{Path: path, Before: golden[path]},
+			})
+		}
+	}
+	return mismatches
+}
+
+// invalidGo returns the generated Go files that do not parse
+func invalidGo(generated map[string][]byte) []SnapshotMismatch {
+	mismatches := []SnapshotMismatch{}
+	for _, path := range sortedKeys(generated) {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), path, generated[path], parser.AllErrors); err != nil {
+			mismatches = append(mismatches, SnapshotMismatch{Path: path, Kind: MismatchInvalidGo, Message: err.Error()})
+		}
+	}
+	return mismatches
+}
+
+// sameTree reports whether two sets of files are equal
+func sameTree(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, content := range a {
+		if other, ok := b[path]; !ok || !bytes.Equal(content, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// readTree reads the files under dir by their slash-separated paths
+// relative to it; a missing dir has no files
+func readTree(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden project: %w", err)
+	}
+	return files, nil
+}
+
+// writeTree replaces dir with the given files
+func writeTree(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove golden project: %w", err)
+	}
+	return writeFiles(dir, files)
+}
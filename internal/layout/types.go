@@ -11,10 +11,13 @@ type LayoutManifest struct {
 	Author            string                       `yaml:"author,omitempty" json:"author,omitempty"`
 	Description       string                       `yaml:"description" json:"description"`
 	MinFoundryVersion string                       `yaml:"min_foundry_version,omitempty" json:"min_foundry_version,omitempty"`
+	Extends           string                       `yaml:"extends,omitempty" json:"extends,omitempty"` // parent layout, e.g. standard@1.x
 	Structure         LayoutStructure              `yaml:"structure" json:"structure"`
 	Dependencies      []string                     `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
 	Features          []string                     `yaml:"features,omitempty" json:"features,omitempty"`
+	Tags              []string                     `yaml:"tags,omitempty" json:"tags,omitempty"`
 	Variables         []LayoutVariable             `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Options           map[string]LayoutOption      `yaml:"options,omitempty" json:"options,omitempty"`
 	Components        map[string]ComponentTemplate `yaml:"components,omitempty" json:"components,omitempty"`
 }
 
@@ -28,20 +31,36 @@ type LayoutStructure struct {
 type DirectorySpec struct {
 	Path        string `yaml:"path" json:"path"`
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	When        string `yaml:"when,omitempty" json:"when,omitempty"`
 }
 
 // FileSpec defines a file to generate from a template
 type FileSpec struct {
 	Template string `yaml:"template" json:"template"`
 	Target   string `yaml:"target" json:"target"`
+	When     string `yaml:"when,omitempty" json:"when,omitempty"`
 }
 
-// LayoutVariable defines a configurable variable for the layout
+// LayoutVariable defines a configurable variable for the layout. Type is one
+// of string (the default), int, bool, enum, regex or port; enum variables
+// list their Choices and regex variables their Pattern.
 type LayoutVariable struct {
-	Name        string `yaml:"name" json:"name"`
-	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
-	Description string `yaml:"description,omitempty" json:"description,omitempty"`
-	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type,omitempty" json:"type,omitempty"`
+	Default     string   `yaml:"default,omitempty" json:"default,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool     `yaml:"required,omitempty" json:"required,omitempty"`
+	Choices     []string `yaml:"choices,omitempty" json:"choices,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+}
+
+// LayoutOption defines a stack choice made when a project is created, such
+// as its router. The chosen value is available to templates as
+// {{.Options.<name>}}, in template paths as well as in their content.
+type LayoutOption struct {
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     string   `yaml:"default" json:"default"`
+	Choices     []string `yaml:"choices" json:"choices"`
 }
 
 // ComponentTemplate defines a component that can be added to the project
@@ -0,0 +1,221 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/staging"
+)
+
+// UpgradeAction is what an upgrade does with a file
+type UpgradeAction string
+
+const (
+	// UpgradeAdded files are new in the layout
+	UpgradeAdded UpgradeAction = "added"
+	// UpgradeUpdated files were not edited and get the new output
+	UpgradeUpdated UpgradeAction = "updated"
+	// UpgradeMerged files were edited and get the layout changes merged in
+	UpgradeMerged UpgradeAction = "merged"
+	// UpgradeConflict files were edited where the layout changed them too
+	UpgradeConflict UpgradeAction = "conflict"
+	// UpgradeKept files are left alone: deleted in the project, or no
+	// longer part of the layout
+	UpgradeKept UpgradeAction = "kept"
+)
+
+// UpgradeFile is the outcome of an upgrade for one file
+type UpgradeFile struct {
+	Path      string        `json:"path" yaml:"path"`
+	Action    UpgradeAction `json:"action" yaml:"action"`
+	Conflicts int           `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+	Reason    string        `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Change is what the upgrade writes to the file, unless it is kept
+	Change diff.Change `json:"-" yaml:"-"`
+}
+
+// UpgradeReport describes an upgrade of a project to a layout version.
+// Files the upgrade leaves as they are are not listed.
+type UpgradeReport struct {
+	Layout      string        `json:"layout" yaml:"layout"`
+	FromVersion string        `json:"from_version" yaml:"from_version"`
+	ToVersion   string        `json:"to_version" yaml:"to_version"`
+	Files       []UpgradeFile `json:"files" yaml:"files"`
+}
+
+// Conflicts returns the number of files left with conflict markers
+func (r *UpgradeReport) Conflicts() int {
+	count := 0
+	for _, file := range r.Files {
+		if file.Action == UpgradeConflict {
+			count++
+		}
+	}
+	return count
+}
+
+// UpgradeProject renders the layout of a project again, at version or the
+// latest available version, with the settings recorded in its lock file. Each
+// file is merged three ways between the output it was generated with, the
+// file in the project and the new output: clean merges are applied and the
+// rest are left with conflict markers. With dryRun nothing is written.
+func (m *Manager) UpgradeProject(ctx context.Context, projectPath, version string, dryRun bool) (*UpgradeReport, error) {
+	lock, err := LoadLock(projectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no %s in %s; only projects generated by foundry can be upgraded", LockFileName, projectPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lock.Settings == nil {
+		return nil, fmt.Errorf("%s does not record the project settings", LockFileName)
+	}
+
+	layout, err := m.loader.LoadVersion(ctx, lock.Layout.Name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load layout: %w", err)
+	}
+
+	// Settings the new version no longer declares are dropped
+	data := lock.Settings.ProjectData()
+	pruneSettings(layout, &data)
+	if err := m.validateProjectData(layout, &data); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	rendered, newLock, err := m.renderProject(layout, projectPath, data)
+	if err != nil {
+		return nil, err
+	}
+	newLock.Settings = NewAnswers(lock.Layout.Name, data)
+
+	report := &UpgradeReport{
+		Layout:      lock.Layout.Name,
+		FromVersion: lock.Layout.Version,
+		ToVersion:   layout.Version,
+	}
+	area := staging.New(projectPath)
+	label := fmt.Sprintf("%s %s", lock.Layout.Name, layout.Version)
+
+	for _, path := range rendered.Files() {
+		theirs, mode, _ := rendered.ReadFile(path)
+		file := UpgradeFile{Path: path}
+
+		ours, err := os.ReadFile(filepath.Join(projectPath, path))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if _, generated := lock.Files[filepath.ToSlash(path)]; generated {
+				file.Action, file.Reason = UpgradeKept, "deleted in the project"
+			} else {
+				file.Action = UpgradeAdded
+				file.Change = diff.Change{Path: filepath.ToSlash(path), After: theirs}
+				if err := area.WriteFile(path, theirs, mode); err != nil {
+					return nil, err
+				}
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		case string(ours) == string(theirs):
+			continue
+		default:
+			base, ok := readBase(projectPath, path)
+			if !ok && lock.Pristine(path, ours) {
+				base, ok = ours, true
+			}
+			merged := diff.Merge3(string(base), string(ours), string(theirs), "current", label)
+			if merged.Text == string(ours) {
+				continue
+			}
+
+			switch {
+			case !ok:
+				file.Action, file.Conflicts = UpgradeConflict, merged.Conflicts
+				file.Reason = "no original output recorded"
+				area.Unchecked(path)
+			case merged.Conflicts > 0:
+				file.Action, file.Conflicts = UpgradeConflict, merged.Conflicts
+				area.Unchecked(path)
+			case string(ours) == string(base):
+				file.Action = UpgradeUpdated
+			default:
+				file.Action = UpgradeMerged
+			}
+			file.Change = diff.Change{Path: filepath.ToSlash(path), Before: ours, After: []byte(merged.Text)}
+			if err := area.WriteFile(path, []byte(merged.Text), mode); err != nil {
+				return nil, err
+			}
+		}
+		report.Files = append(report.Files, file)
+	}
+
+	// Files generated from the old version that the new one no longer has
+	var removed []string
+	for path, locked := range lock.Files {
+		if _, ok := newLock.Files[path]; ok {
+			continue
+		}
+		if locked.Template != "" {
+			removed = append(removed, path)
+		}
+		newLock.Files[path] = locked
+	}
+	sort.Strings(removed)
+	for _, path := range removed {
+		report.Files = append(report.Files, UpgradeFile{Path: path, Action: UpgradeKept, Reason: "no longer in the layout"})
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	// Record the new output as the base of the next upgrade
+	if err := stageBase(area, rendered); err != nil {
+		return nil, err
+	}
+	if err := stageIgnore(area, projectPath); err != nil {
+		return nil, err
+	}
+	content, err := newLock.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := area.WriteFile(LockFileName, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create file %s: %w", LockFileName, err)
+	}
+
+	if err := area.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to write project: %w", err)
+	}
+	return report, nil
+}
+
+// pruneSettings drops the variables, options and features the layout does
+// not declare
+func pruneSettings(layout *Layout, data *ProjectData) {
+	for name := range data.CustomVariables {
+		declared := false
+		for _, variable := range layout.Manifest.Variables {
+			declared = declared || variable.Name == name
+		}
+		if !declared {
+			delete(data.CustomVariables, name)
+		}
+	}
+	for name := range data.Options {
+		if _, ok := layout.Manifest.Options[name]; !ok {
+			delete(data.Options, name)
+		}
+	}
+	for name := range data.Features {
+		if !contains(layout.Manifest.Features, name) {
+			delete(data.Features, name)
+		}
+	}
+}
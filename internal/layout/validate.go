@@ -0,0 +1,626 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/shapestone/foundry/internal/templating"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFileName is the manifest at the root of every layout
+const ManifestFileName = "layout.manifest.yaml"
+
+// Severity is how serious a problem in a layout is
+type Severity string
+
+const (
+	// SeverityError problems break generation or the generated files
+	SeverityError Severity = "error"
+	// SeverityWarning problems are likely mistakes that do no harm
+	SeverityWarning Severity = "warning"
+)
+
+// Problem codes reported by ValidateDir and ValidateLayout
+const (
+	ProblemManifestInvalid = "MANIFEST_INVALID"
+	ProblemFieldMissing    = "FIELD_MISSING"
+	ProblemParentMissing   = "PARENT_MISSING"
+	ProblemTemplateMissing = "TEMPLATE_MISSING"
+	ProblemTemplateUnused  = "TEMPLATE_UNUSED"
+	ProblemTemplateInvalid = "TEMPLATE_INVALID"
+	ProblemVariableUnknown = "VARIABLE_UNKNOWN"
+)
+
+// Directories of a layout that hold templates, and the fields of project
+// data that hold the values of its variables and options
+const (
+	projectTemplatesDir   = "project"
+	componentTemplatesDir = "components"
+	customVariablesField  = "CustomVariables"
+	optionsField          = "Options"
+)
+
+// sampleName is the project and component name templates are rendered with
+const sampleName = "example"
+
+// Problem is something wrong in a layout, at a line of one of its files.
+// Line is 0 when the problem is with the file as a whole.
+type Problem struct {
+	File     string   `json:"file" yaml:"file"`
+	Line     int      `json:"line,omitempty" yaml:"line,omitempty"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Code     string   `json:"code" yaml:"code"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// Location returns where the problem is, as file:line
+func (p Problem) Location() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Location(), p.Severity, p.Message)
+}
+
+// componentFields are the top-level fields of the data component templates
+// get: those renderComponent sets, and those the generators add with
+// WithComponentData
+var componentFields = []string{
+	"ComponentName", "ModuleName", "Name", "PackageName", "Type",
+	"Fields", "Router", "RouterImport", "IDParam", "Routes",
+}
+
+// layoutFiles are the manifest and templates of a layout being validated
+type layoutFiles struct {
+	// root is the directory shown in the locations of problems
+	root     string
+	manifest []byte
+	// templates holds the content of the templates under project/ and
+	// components/, by their slash separated path in the layout
+	templates map[string]string
+}
+
+// ValidateDir checks the layout in dir, as its author would before
+// publishing it. Problems are returned sorted by file and line; an error is
+// returned only when the layout cannot be read at all.
+func (m *Manager) ValidateDir(ctx context.Context, dir string) ([]Problem, error) {
+	files, err := readLayoutDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return m.validate(ctx, files), nil
+}
+
+// ValidateLayout checks a built-in layout or a registry layout stored on
+// this machine
+func (m *Manager) ValidateLayout(ctx context.Context, name string) ([]Problem, error) {
+	if contains(GetEmbeddedLayouts(), name) {
+		files, err := readEmbeddedLayout(name)
+		if err != nil {
+			return nil, err
+		}
+		return m.validate(ctx, files), nil
+	}
+
+	source, err := m.registry.GetLayoutSource(name)
+	if err != nil {
+		return nil, fmt.Errorf("layout not found: %w", err)
+	}
+	if source.Type != "local" {
+		return nil, fmt.Errorf("layout '%s' is a %s layout; validate a local copy of it by directory", name, source.Type)
+	}
+	return m.ValidateDir(ctx, expandPath(source.Location))
+}
+
+// validate runs every check on the files of a layout
+func (m *Manager) validate(ctx context.Context, files *layoutFiles) []Problem {
+	v := &validator{files: files, manifestFile: files.location(ManifestFileName)}
+	manifest, lines, ok := v.parseManifest()
+	if !ok {
+		return v.sorted()
+	}
+
+	// Templates and references may come from the layout it extends
+	available := make(map[string]bool, len(files.templates))
+	for path := range files.templates {
+		available[path] = true
+	}
+	merged := manifest
+	if manifest.Extends != "" {
+		parentName, _ := parseExtends(manifest.Extends)
+		parent, err := m.GetLayout(ctx, parentName)
+		if err != nil {
+			v.add(v.manifestFile, lines.extends, SeverityError, ProblemParentMissing,
+				fmt.Sprintf("cannot load the layout it extends, '%s': %v", manifest.Extends, err))
+		} else {
+			for path := range parent.Templates {
+				available[path] = true
+			}
+			merged = mergeManifests(parent.Manifest, manifest)
+		}
+	}
+
+	referenced := v.checkReferences(manifest, lines, available)
+	for _, path := range sortedKeys(files.templates) {
+		if !referenced[path] {
+			v.add(files.location(path), 0, SeverityWarning, ProblemTemplateUnused,
+				"template is not used by any file or component in the manifest")
+		}
+		v.checkTemplate(path, files.templates[path], merged)
+	}
+	return v.sorted()
+}
+
+// validator collects the problems found in a layout
+type validator struct {
+	files        *layoutFiles
+	manifestFile string
+	problems     []Problem
+}
+
+// add records a problem, once for each line it is found on
+func (v *validator) add(file string, line int, severity Severity, code, message string) {
+	problem := Problem{File: file, Line: line, Severity: severity, Code: code, Message: message}
+	for _, existing := range v.problems {
+		if existing == problem {
+			return
+		}
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// sorted returns the problems sorted by file and line
+func (v *validator) sorted() []Problem {
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	if v.problems == nil {
+		return []Problem{}
+	}
+	return v.problems
+}
+
+// manifestLines holds the lines of the manifest entries problems refer to
+type manifestLines struct {
+	extends    int
+	files      []fileLines
+	components map[string]int
+}
+
+// fileLines holds the lines of a file entry and of its template
+type fileLines struct {
+	entry    int
+	template int
+}
+
+// yamlLine matches the line number in YAML errors
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// parseManifest parses the manifest and checks the fields the loader
+// requires, as loadManifest does
+func (v *validator) parseManifest() (*LayoutManifest, manifestLines, bool) {
+	var (
+		manifest LayoutManifest
+		lines    manifestLines
+	)
+	if err := yaml.Unmarshal(v.files.manifest, &manifest); err != nil {
+		line := 0
+		if match := yamlLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		v.add(v.manifestFile, line, SeverityError, ProblemManifestInvalid, fmt.Sprintf("manifest does not parse: %v", err))
+		return nil, lines, false
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(v.files.manifest, &root); err == nil && len(root.Content) > 0 {
+		lines = findManifestLines(root.Content[0])
+	}
+
+	if manifest.Name == "" {
+		v.add(v.manifestFile, 1, SeverityError, ProblemFieldMissing, "manifest missing required field: name")
+	}
+	if manifest.Version == "" {
+		v.add(v.manifestFile, 1, SeverityError, ProblemFieldMissing, "manifest missing required field: version")
+	}
+	return &manifest, lines, true
+}
+
+// findManifestLines finds the lines of the entries in a parsed manifest
+func findManifestLines(doc *yaml.Node) manifestLines {
+	lines := manifestLines{components: make(map[string]int)}
+	if key, value := mappingValue(doc, "extends"); value != nil {
+		lines.extends = key.Line
+	}
+	if _, structure := mappingValue(doc, "structure"); structure != nil {
+		if _, files := mappingValue(structure, "files"); files != nil && files.Kind == yaml.SequenceNode {
+			for _, entry := range files.Content {
+				fl := fileLines{entry: entry.Line, template: entry.Line}
+				if _, template := mappingValue(entry, "template"); template != nil {
+					fl.template = template.Line
+				}
+				lines.files = append(lines.files, fl)
+			}
+		}
+	}
+	if _, components := mappingValue(doc, "components"); components != nil && components.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(components.Content); i += 2 {
+			line := components.Content[i].Line
+			if _, template := mappingValue(components.Content[i+1], "template"); template != nil {
+				line = template.Line
+			}
+			lines.components[components.Content[i].Value] = line
+		}
+	}
+	return lines
+}
+
+// mappingValue returns the key and value nodes of a key in a YAML mapping
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// checkReferences reports the files and components of the manifest whose
+// templates do not exist, and returns the templates they use
+func (v *validator) checkReferences(manifest *LayoutManifest, lines manifestLines, available map[string]bool) map[string]bool {
+	referenced := make(map[string]bool)
+	for i, file := range manifest.Structure.Files {
+		var fl fileLines
+		if i < len(lines.files) {
+			fl = lines.files[i]
+		}
+		if file.Target == "" {
+			v.add(v.manifestFile, fl.entry, SeverityError, ProblemFieldMissing, "file entry missing required field: target")
+		}
+		if file.Template == "" {
+			v.add(v.manifestFile, fl.entry, SeverityError, ProblemFieldMissing,
+				fmt.Sprintf("file %s missing required field: template", file.Target))
+			continue
+		}
+		for _, path := range optionTemplatePaths(file.Template, manifest.Options) {
+			referenced[path] = true
+			if !available[path] {
+				v.add(v.manifestFile, fl.template, SeverityError, ProblemTemplateMissing,
+					fmt.Sprintf("template %s of file %s not found", path, file.Target))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(manifest.Components) {
+		component := manifest.Components[name]
+		line := lines.components[name]
+		if component.Template == "" {
+			v.add(v.manifestFile, line, SeverityError, ProblemFieldMissing,
+				fmt.Sprintf("component %s missing required field: template", name))
+			continue
+		}
+		referenced[component.Template] = true
+		if !available[component.Template] {
+			v.add(v.manifestFile, line, SeverityError, ProblemTemplateMissing,
+				fmt.Sprintf("template %s of component %s not found", component.Template, name))
+		}
+	}
+	return referenced
+}
+
+// templateLine matches the line in text/template errors, such as
+// "template: main.go.tmpl:12:4: executing ..."
+var templateLine = regexp.MustCompile(`^template: [^:]*:(\d+)`)
+
+// checkTemplate renders a template with sample data, and reports the
+// fields it uses that the data it gets does not have
+func (v *validator) checkTemplate(path, content string, manifest *LayoutManifest) {
+	file := v.files.location(path)
+	component := strings.HasPrefix(path, componentTemplatesDir+"/")
+
+	data := sampleProjectData(manifest)
+	if component {
+		data = sampleComponentData()
+	}
+	renderer := templating.NewDefaultRenderer(
+		templating.WithFuncs(templateFuncs()),
+		templating.WithSampleData(data),
+	)
+	tmpl := &templating.Template{Name: path, Content: content, Layout: manifest.Name}
+	if err := renderer.ValidateTemplate(tmpl); err != nil {
+		cause := err
+		var templateErr *templating.TemplateError
+		if errors.As(err, &templateErr) {
+			cause = templateErr.Cause
+		}
+		line := 0
+		if match := templateLine.FindStringSubmatch(cause.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		v.add(file, line, SeverityError, ProblemTemplateInvalid, cause.Error())
+	}
+	if tmpl.Parsed == nil {
+		return
+	}
+
+	known := fieldNames(reflect.TypeOf(ProjectData{}))
+	if component {
+		known = componentFields
+	}
+	variables := make([]string, 0, len(manifest.Variables))
+	for _, variable := range manifest.Variables {
+		variables = append(variables, variable.Name)
+	}
+
+	for _, t := range tmpl.Parsed.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		walkFields(t.Tree.Root, true, func(node parse.Node, fields []string) {
+			var message string
+			switch {
+			case !contains(known, fields[0]):
+				message = fmt.Sprintf("unknown field .%s", fields[0])
+			case component || len(fields) < 2:
+				return
+			case fields[0] == customVariablesField && !contains(variables, fields[1]):
+				message = fmt.Sprintf("unknown variable .%s.%s: the manifest declares no variable '%s'",
+					customVariablesField, fields[1], fields[1])
+			case fields[0] == optionsField && !hasKey(manifest.Options, fields[1]):
+				message = fmt.Sprintf("unknown option .%s.%s: the manifest declares no option '%s'",
+					optionsField, fields[1], fields[1])
+			default:
+				return
+			}
+			location, _ := t.Tree.ErrorContext(node)
+			v.add(file, locationLine(location), SeverityError, ProblemVariableUnknown, message)
+		})
+	}
+}
+
+// walkFields calls fn for every field the template reads from its data:
+// fields of the dot where the dot is the data, and fields of $ anywhere
+func walkFields(node parse.Node, root bool, fn func(node parse.Node, fields []string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, root, fn)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, root, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkFields(cmd, root, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkFields(arg, root, fn)
+		}
+	case *parse.FieldNode:
+		if root {
+			fn(n, n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			fn(n, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		walkFields(n.Node, root, fn)
+	case *parse.IfNode:
+		walkFields(n.Pipe, root, fn)
+		walkFields(n.List, root, fn)
+		walkFields(n.ElseList, root, fn)
+	case *parse.RangeNode:
+		// The dot is an element inside range, and a value inside with
+		walkFields(n.Pipe, root, fn)
+		walkFields(n.List, false, fn)
+		walkFields(n.ElseList, root, fn)
+	case *parse.WithNode:
+		walkFields(n.Pipe, root, fn)
+		walkFields(n.List, false, fn)
+		walkFields(n.ElseList, root, fn)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, root, fn)
+	}
+}
+
+// locationLine returns the line of a "name:line:col" template location
+func locationLine(location string) int {
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
+
+// fieldNames returns the names of the fields of a struct type
+func fieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Name)
+	}
+	return names
+}
+
+// sampleProjectData returns project data with the defaults of the layout,
+// and sample values where it has none, to render project templates with
+func sampleProjectData(manifest *LayoutManifest) templating.TemplateData {
+	variables := make(map[string]string, len(manifest.Variables))
+	for _, variable := range manifest.Variables {
+		variables[variable.Name] = sampleValue(variable)
+	}
+	options := make(map[string]string, len(manifest.Options))
+	for name, option := range manifest.Options {
+		options[name] = option.Default
+		if options[name] == "" && len(option.Choices) > 0 {
+			options[name] = option.Choices[0]
+		}
+	}
+	features := make(map[string]bool, len(manifest.Features))
+	for _, feature := range manifest.Features {
+		features[feature] = true
+	}
+
+	return templating.TemplateData{
+		"ProjectName":     sampleName,
+		"ModuleName":      "github.com/example/example",
+		"Author":          "Example Author",
+		"License":         "MIT",
+		"Description":     "An example project",
+		"GitHubUsername":  "example",
+		"Year":            2024,
+		"GoVersion":       "1.22",
+		"CustomVariables": variables,
+		"Options":         options,
+		"Features":        features,
+	}
+}
+
+// sampleValue returns the default of a variable, or a value of its type
+func sampleValue(variable LayoutVariable) string {
+	if variable.Default != "" {
+		return variable.Default
+	}
+	switch variable.Type {
+	case VariableInt:
+		return "1"
+	case VariableBool:
+		return "true"
+	case VariablePort:
+		return "8080"
+	case VariableEnum:
+		if len(variable.Choices) > 0 {
+			return variable.Choices[0]
+		}
+	}
+	return sampleName
+}
+
+// sampleComponentData returns the data a component template gets, with
+// sample values
+func sampleComponentData() templating.TemplateData {
+	return templating.TemplateData{
+		"ComponentName": sampleName,
+		"ModuleName":    "github.com/example/example",
+		"Name":          sampleName,
+		"PackageName":   sampleName,
+		"Type":          "handler",
+		"Fields":        []interface{}{},
+		"Router":        "chi",
+		"RouterImport":  "github.com/go-chi/chi/v5",
+		"IDParam":       `chi.URLParam(r, "id")`,
+		"Routes":        []string{},
+	}
+}
+
+// readLayoutDir reads the manifest and templates of the layout in dir
+func readLayoutDir(dir string) (*layoutFiles, error) {
+	manifest, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	files := &layoutFiles{root: dir, manifest: manifest, templates: make(map[string]string)}
+	loader := &Loader{}
+	for _, sub := range []string{projectTemplatesDir, componentTemplatesDir} {
+		err := loader.loadTemplatesFromDir(filepath.Join(dir, sub), sub, files.templates)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readEmbeddedLayout reads the manifest and templates of a built-in layout
+func readEmbeddedLayout(name string) (*layoutFiles, error) {
+	manifest, err := GetEmbeddedLayoutManifest(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest for layout %s: %w", name, err)
+	}
+
+	files := &layoutFiles{root: path.Join("templates", name), manifest: manifest, templates: make(map[string]string)}
+	for _, sub := range []string{projectTemplatesDir, componentTemplatesDir} {
+		if err := readEmbeddedTemplates(name, sub, files.templates); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readEmbeddedTemplates reads the templates in a directory of a built-in
+// layout and its subdirectories
+func readEmbeddedTemplates(name, dir string, templates map[string]string) error {
+	entries, err := embeddedTemplates.ReadDir(path.Join("templates", name, dir))
+	if err != nil {
+		// Layouts need not have every directory
+		return nil
+	}
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if err := readEmbeddedTemplates(name, entryPath, templates); err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+		content, err := GetEmbeddedTemplateFile(name, entryPath)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", entryPath, err)
+		}
+		templates[entryPath] = string(content)
+	}
+	return nil
+}
+
+// location returns the path of a file of the layout as problems show it
+func (files *layoutFiles) location(name string) string {
+	return filepath.ToSlash(filepath.Join(files.root, filepath.FromSlash(name)))
+}
+
+// hasKey reports whether a map has a key
+func hasKey[T any](m map[string]T, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package layout
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Variable types a layout can declare
+const (
+	VariableString = "string"
+	VariableInt    = "int"
+	VariableBool   = "bool"
+	VariableEnum   = "enum"
+	VariableRegex  = "regex"
+	VariablePort   = "port"
+)
+
+// Validate checks value against the type of the variable and returns it in
+// canonical form
+func (variable LayoutVariable) Validate(value string) (string, error) {
+	switch variable.Type {
+	case "", VariableString:
+		return value, nil
+
+	case VariableInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("invalid %s '%s': must be an integer", variable.Name, value)
+		}
+		return value, nil
+
+	case VariableBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s '%s': must be true or false", variable.Name, value)
+		}
+		return strconv.FormatBool(b), nil
+
+	case VariableEnum:
+		if !contains(variable.Choices, value) {
+			return "", fmt.Errorf("invalid %s '%s' (choose from %s)",
+				variable.Name, value, strings.Join(variable.Choices, ", "))
+		}
+		return value, nil
+
+	case VariableRegex:
+		re, err := regexp.Compile(variable.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("variable '%s' has an invalid pattern: %w", variable.Name, err)
+		}
+		if !re.MatchString(value) {
+			return "", fmt.Errorf("invalid %s '%s': must match %s", variable.Name, value, variable.Pattern)
+		}
+		return value, nil
+
+	case VariablePort:
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return "", fmt.Errorf("invalid %s '%s': must be a port between 1 and 65535", variable.Name, value)
+		}
+		return strconv.Itoa(port), nil
+
+	default:
+		return "", fmt.Errorf("variable '%s' has unknown type '%s'", variable.Name, variable.Type)
+	}
+}
@@ -0,0 +1,251 @@
+// Package logging builds the logger Foundry writes its diagnostics with:
+// warnings, errors that do not stop a command, and with -v or -vv the
+// details of what commands do. Logs are text for people or JSON for tools.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelTrace is below debug, for the details -vv shows
+const LevelTrace = slog.LevelDebug - 4
+
+// Format is how log records are written
+type Format string
+
+const (
+	// FormatText writes one line per record for people
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record
+	FormatJSON Format = "json"
+)
+
+// ParseFormat returns the log format with the given name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown log format '%s' (use text or json)", name)
+}
+
+// Level returns the lowest level logged for the number of -v flags, or for
+// --quiet, which leaves warnings and errors
+func Level(verbosity int, quiet bool) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelWarn
+	case verbosity >= 2:
+		return LevelTrace
+	case verbosity == 1:
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// Options configures a logger
+type Options struct {
+	Level  slog.Level
+	Format Format
+	// Plain leaves emoji out of text records
+	Plain bool
+}
+
+// New returns a logger that writes to w
+func New(w io.Writer, options Options) *slog.Logger {
+	if options.Format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level:       options.Level,
+			ReplaceAttr: replaceJSONAttr,
+		}))
+	}
+	return slog.New(&textHandler{
+		out:   &lockedWriter{w: w},
+		level: options.Level,
+		plain: options.Plain,
+	})
+}
+
+// replaceJSONAttr names the trace level and keeps emoji out of messages
+func replaceJSONAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return attr
+	}
+	switch attr.Key {
+	case slog.LevelKey:
+		if level, ok := attr.Value.Any().(slog.Level); ok && level <= LevelTrace {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	case slog.MessageKey:
+		attr.Value = slog.StringValue(Strip(attr.Value.String()))
+	}
+	return attr
+}
+
+// lockedWriter serializes the writes of handlers that share it
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// textHandler writes a record as its message after a mark for its level,
+// such as "⚠️  Warning: failed to load layout index", followed by its
+// attributes as key=value pairs
+type textHandler struct {
+	out   *lockedWriter
+	level slog.Level
+	plain bool
+	attrs []slog.Attr
+	group string
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	b.WriteString(h.prefix(record.Level))
+	b.WriteString(record.Message)
+	for _, attr := range h.attrs {
+		writeAttr(&b, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, h.group, attr)
+		return true
+	})
+	b.WriteByte('\n')
+
+	line := b.String()
+	if h.plain {
+		line = Strip(line)
+	}
+
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	_, err := io.WriteString(h.out.w, line)
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	clone.attrs = append(clone.attrs, h.attrs...)
+	for _, attr := range attrs {
+		if h.group != "" {
+			attr.Key = h.group + attr.Key
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group = h.group + name + "."
+	return &clone
+}
+
+// prefix returns the mark of a level. Info records are messages such as
+// "✅ Middleware auth wired successfully!" that carry their own.
+func (h *textHandler) prefix(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "❌ Error: "
+	case level >= slog.LevelWarn:
+		return "⚠️  Warning: "
+	case level >= slog.LevelInfo:
+		return ""
+	case level >= slog.LevelDebug:
+		return "[debug] "
+	}
+	return "[trace] "
+}
+
+// Text returns the message of a record followed by its attributes, as the
+// text format writes them without the mark for its level
+func Text(record slog.Record) string {
+	var b strings.Builder
+	b.WriteString(record.Message)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, "", attr)
+		return true
+	})
+	return b.String()
+}
+
+// writeAttr writes an attribute as " key=value", quoting values with spaces
+func writeAttr(b *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			writeAttr(b, prefix, member)
+		}
+		return
+	}
+
+	var value string
+	switch attr.Value.Kind() {
+	case slog.KindTime:
+		value = attr.Value.Time().Format(time.RFC3339)
+	default:
+		value = attr.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", group, attr.Key, value)
+}
+
+// messageWriter logs each line written to it as an info record
+type messageWriter struct {
+	mu      sync.Mutex
+	logger  *slog.Logger
+	partial []byte
+}
+
+// MessageWriter returns a writer that logs each line of the messages a
+// command prints as an info record, for --quiet and JSON logs. Blank lines
+// are dropped.
+func MessageWriter(logger *slog.Logger) io.Writer {
+	return &messageWriter{logger: logger}
+}
+
+func (mw *messageWriter) Write(p []byte) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	text := append(mw.partial, p...)
+	for {
+		end := bytes.IndexByte(text, '\n')
+		if end < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(text[:end])); line != "" {
+			mw.logger.Info(line)
+		}
+		text = text[end+1:]
+	}
+	mw.partial = append([]byte(nil), text...)
+	return len(p), nil
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// variationSelector asks for the emoji form of the symbol before it
+	variationSelector = '\uFE0F'
+	// zeroWidthJoiner joins emoji into one
+	zeroWidthJoiner = '\u200D'
+)
+
+// isEmoji reports whether r is one of the pictographs and symbols that mark
+// Foundry's messages, such as ✅, ⚠ or 📁
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	case r >= 0x23E9 && r <= 0x23FA:
+		return true
+	}
+	return false
+}
+
+// Strip removes the emoji from s, with the spaces that follow them, so
+// "⚠️  Warning: ..." becomes "Warning: ..."
+func Strip(s string) string {
+	var b strings.Builder
+	stripper := &stripper{}
+	stripper.strip(&b, s)
+	return b.String()
+}
+
+// stripper removes emoji from text that can arrive in pieces
+type stripper struct {
+	// skipSpaces drops the spaces after an emoji
+	skipSpaces bool
+}
+
+// strip writes s without its emoji
+func (s *stripper) strip(b *strings.Builder, text string) {
+	for i, r := range text {
+		next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+		switch {
+		case r == variationSelector || r == zeroWidthJoiner:
+			continue
+		case isEmoji(r) || next == variationSelector:
+			s.skipSpaces = true
+			continue
+		case r == ' ' && s.skipSpaces:
+			continue
+		}
+		s.skipSpaces = false
+		b.WriteRune(r)
+	}
+}
+
+// plainWriter writes text without emoji
+type plainWriter struct {
+	w        io.Writer
+	stripper stripper
+	// partial holds the start of a character cut off by the last write
+	partial []byte
+}
+
+// PlainWriter returns a writer that removes the emoji from what it writes
+// to w, for --no-emoji
+func PlainWriter(w io.Writer) io.Writer {
+	return &plainWriter{w: w}
+}
+
+func (pw *plainWriter) Write(p []byte) (int, error) {
+	text := append(pw.partial, p...)
+	end := len(text)
+	start := len(text) - 1
+	for start > 0 && start > len(text)-utf8.UTFMax && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	if start >= 0 && !utf8.FullRune(text[start:]) {
+		end = start
+	}
+	pw.partial = append([]byte(nil), text[end:]...)
+
+	var b strings.Builder
+	pw.stripper.strip(&b, string(text[:end]))
+	if _, err := io.WriteString(pw.w, b.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
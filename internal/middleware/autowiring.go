@@ -2,23 +2,29 @@
 package middleware
 
 import (
-	"bufio"
 	"fmt"
+	"go/ast"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/shapestone/foundry/internal/astedit"
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/interactive"
 	"github.com/shapestone/foundry/internal/project"
+	"github.com/shapestone/foundry/internal/routes"
 )
 
 // RouterPattern represents different router frameworks
-type RouterPattern string
+type RouterPattern = routes.Router
 
 const (
-	RouterChi     RouterPattern = "chi"
-	RouterGin     RouterPattern = "gin"
-	RouterGorilla RouterPattern = "gorilla"
-	RouterHTTP    RouterPattern = "http"
+	RouterChi     = routes.RouterChi
+	RouterGin     = routes.RouterGin
+	RouterEcho    = routes.RouterEcho
+	RouterGorilla = routes.RouterGorilla
+	RouterHTTP    = routes.RouterHTTP
 )
 
 // MiddlewarePosition defines where middleware should be inserted
@@ -30,351 +36,355 @@ const (
 	PositionLate                             // Auth, Rate limiting
 )
 
+// routerConstructors lists the calls that create a router for each pattern
+var routerConstructors = map[RouterPattern][]string{
+	RouterChi:     {"NewRouter", "NewMux"},
+	RouterGin:     {"New", "Default"},
+	RouterEcho:    {"New"},
+	RouterGorilla: {"NewRouter"},
+}
+
 // AutoWirer handles automatic middleware wiring
 type AutoWirer struct {
 	projectPath string
 	moduleName  string
+	mainFile    string
+	force       bool
+	diffOptions *diff.Options
+	logger      *slog.Logger
 }
 
-// NewAutoWirer creates a new middleware auto-wirer
-func NewAutoWirer(projectPath string) *AutoWirer {
-	return &AutoWirer{
-		projectPath: projectPath,
-		moduleName:  project.GetCurrentModule(),
+// Option configures an AutoWirer
+type Option func(*AutoWirer)
+
+// WithMainFile sets the file that creates the router, relative to the project
+func WithMainFile(path string) Option {
+	return func(aw *AutoWirer) {
+		aw.mainFile = path
 	}
 }
 
-// WireMiddleware automatically wires middleware into the project
-func (aw *AutoWirer) WireMiddleware(middlewareType string, dryRun bool) error {
-	// Find main.go file
-	mainFile, err := aw.findMainFile()
-	if err != nil {
-		return fmt.Errorf("failed to find main.go: %w", err)
+// WithForce replaces existing middleware registrations instead of failing
+func WithForce(force bool) Option {
+	return func(aw *AutoWirer) {
+		aw.force = force
 	}
+}
 
-	// Detect router pattern
-	pattern, err := aw.detectRouterPattern(mainFile)
-	if err != nil {
-		return fmt.Errorf("failed to detect router pattern: %w", err)
+// WithDiffOptions sets how the preview renders the diff of main.go
+func WithDiffOptions(options diff.Options) Option {
+	return func(aw *AutoWirer) {
+		aw.diffOptions = &options
 	}
+}
 
-	// Read current content
-	content, err := os.ReadFile(mainFile)
-	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", mainFile, err)
+// WithLogger sets the logger for the progress of wiring; the default is
+// slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(aw *AutoWirer) {
+		aw.logger = logger
 	}
+}
 
-	originalContent := string(content)
-
-	// Check if middleware is already wired
-	if aw.isMiddlewareAlreadyWired(originalContent, middlewareType) {
-		return fmt.Errorf("middleware %s appears to already be wired", middlewareType)
+// NewAutoWirer creates a new middleware auto-wirer
+func NewAutoWirer(projectPath string, opts ...Option) *AutoWirer {
+	aw := &AutoWirer{
+		projectPath: projectPath,
+		moduleName:  project.GetCurrentModule(),
+		logger:      slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(aw)
 	}
+	return aw
+}
 
-	// Generate the wired content
-	newContent, err := aw.wireMiddlewareInContent(originalContent, middlewareType, pattern)
+// WireMiddleware automatically wires middleware into the project
+func (aw *AutoWirer) WireMiddleware(middlewareType string, dryRun bool) error {
+	update, err := aw.PlanMiddleware(middlewareType)
 	if err != nil {
-		return fmt.Errorf("failed to wire middleware: %w", err)
+		return err
 	}
 
 	// Show preview
-	if !aw.showPreview(mainFile, originalContent, newContent, middlewareType) {
+	prompter := interactive.NewConsolePrompter()
+	if aw.diffOptions != nil {
+		prompter.SetDiffOptions(*aw.diffOptions)
+	}
+	message := fmt.Sprintf("This will add the %s middleware to your router", middlewareType)
+	if !prompter.ShowChanges(fmt.Sprintf("Preview changes to %s:", update.Path), []diff.Change{update.Diff()}, message) {
 		return fmt.Errorf("wiring cancelled by user")
 	}
 
 	if dryRun {
-		fmt.Println("🔍 Dry run complete - no changes made")
+		aw.logger.Info("🔍 Dry run complete - no changes made")
 		return nil
 	}
 
-	// Write the updated content
-	if err := os.WriteFile(mainFile, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", mainFile, err)
+	if err := routes.ApplyUpdate(update, routes.NewFileGenerator()); err != nil {
+		return fmt.Errorf("failed to wire middleware: %w", err)
 	}
 
-	fmt.Printf("✅ Middleware %s wired successfully!\n", middlewareType)
+	aw.logger.Info("✅ Middleware wired successfully", "middleware", middlewareType)
 	return nil
 }
 
-// findMainFile locates the main.go file in the project
-func (aw *AutoWirer) findMainFile() (string, error) {
-	// Common locations for main.go (check root first since it's most common)
-	candidates := []string{
-		"main.go", // Root (most common)
-		filepath.Join("cmd", project.GetProjectName(), "main.go"), // Standard layout
-		"cmd/main.go", // Generic cmd
+// PlanMiddleware calculates the changes needed to register middleware with
+// the router created in main.go. The file is edited through its syntax tree,
+// so the router can be set up with any formatting.
+func (aw *AutoWirer) PlanMiddleware(middlewareType string) (*routes.Update, error) {
+	if middlewareType == "" {
+		return nil, fmt.Errorf("middleware type is required")
 	}
 
-	for _, candidate := range candidates {
-		fullPath := filepath.Join(aw.projectPath, candidate)
-		if _, err := os.Stat(fullPath); err == nil {
-			return fullPath, nil
-		}
+	// Only middleware the project declares is wired, so main.go keeps
+	// compiling
+	importPath := aw.moduleName + "/internal/middleware"
+	name := middlewareName(middlewareType)
+	found, dir, err := routes.FindFunc(aw.projectPath, aw.moduleName, importPath, name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("middleware %s not found: no %s function in %s", middlewareType, name, dir)
 	}
 
-	return "", fmt.Errorf("main.go not found in expected locations")
-}
+	mainFile, err := aw.findMainFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find main.go: %w", err)
+	}
 
-// detectRouterPattern analyzes the main.go file to detect the router pattern
-func (aw *AutoWirer) detectRouterPattern(mainFile string) (RouterPattern, error) {
-	content, err := os.ReadFile(mainFile)
+	file, err := astedit.ParseFile(mainFile)
 	if err != nil {
-		return "", err
-	}
-
-	contentStr := string(content)
-
-	// Check imports and usage patterns
-	patterns := map[RouterPattern][]string{
-		RouterChi: {
-			"github.com/go-chi/chi",
-			"chi.NewRouter",
-			".Use(",
-		},
-		RouterGin: {
-			"github.com/gin-gonic/gin",
-			"gin.Default",
-			"gin.New",
-		},
-		RouterGorilla: {
-			"github.com/gorilla/mux",
-			"mux.NewRouter",
-		},
-		RouterHTTP: {
-			"net/http",
-			"http.HandleFunc",
-			"http.ListenAndServe",
-		},
-	}
-
-	for pattern, indicators := range patterns {
-		matches := 0
-		for _, indicator := range indicators {
-			if strings.Contains(contentStr, indicator) {
-				matches++
-			}
-		}
-		// If we find multiple indicators, this is likely the pattern
-		if matches >= 2 || (pattern == RouterHTTP && matches >= 1) {
-			return pattern, nil
-		}
+		return nil, err
 	}
 
-	// Default to Chi if no clear pattern
-	return RouterChi, nil
-}
+	pattern, routerPkg := aw.detectRouterPattern(file)
+	if pattern == RouterHTTP {
+		return nil, fmt.Errorf("%s uses net/http without a middleware chain; wrap your handler with middleware.%s manually", mainFile, name)
+	}
 
-// isMiddlewareAlreadyWired checks if middleware is already configured
-func (aw *AutoWirer) isMiddlewareAlreadyWired(content, middlewareType string) bool {
-	middlewareName := strings.Title(middlewareType) + "Middleware"
-	return strings.Contains(content, middlewareName)
-}
+	block, index, routerVar := aw.findRouter(file.AST, pattern, routerPkg)
+	if block == nil {
+		return nil, fmt.Errorf("could not find where the %s router is created in %s", pattern, mainFile)
+	}
+	aw.logger.Debug("found router", "file", mainFile, "router", pattern, "variable", routerVar)
 
-// wireMiddlewareInContent adds middleware to the router configuration
-func (aw *AutoWirer) wireMiddlewareInContent(content, middlewareType string, pattern RouterPattern) (string, error) {
-	lines := strings.Split(content, "\n")
-	result := make([]string, 0, len(lines)+5) // Pre-allocate with extra space
+	changes := []string{}
 
-	// Add import if not present
-	importAdded := false
-	middlewareAdded := false
+	pkg, added := file.EnsureImport(importPath, "appmiddleware")
+	if added {
+		changes = append(changes, fmt.Sprintf("Add import: %q", importPath))
+	}
 
-	middlewareImport := fmt.Sprintf("\"%s/internal/middleware\"", aw.moduleName)
+	if existing := aw.findUseStmts(block, routerVar, pkg, name); len(existing) > 0 {
+		if !aw.force {
+			return nil, fmt.Errorf("middleware %s is already wired in %s (use --force to replace it)", middlewareType, mainFile)
+		}
+		for _, stmt := range existing {
+			for i, s := range block.List {
+				if s == stmt {
+					block.List = append(block.List[:i], block.List[i+1:]...)
+					if i < index {
+						index--
+					}
+					break
+				}
+			}
+		}
+		changes = append(changes, fmt.Sprintf("Remove existing %s middleware registration", middlewareType))
+	}
 
-	// Get middleware position
+	// Keep the recommended ordering relative to middleware already in place
 	position := aw.getMiddlewarePosition(middlewareType)
-
-	for i, line := range lines {
-		result = append(result, line)
-
-		// Add import after other imports
-		if !importAdded && aw.isImportSection(line, i, lines) {
-			// Look for the closing of import block or add after current import
-			if strings.Contains(line, ")") && strings.TrimSpace(line) == ")" {
-				// Insert before closing parenthesis
-				result[len(result)-1] = fmt.Sprintf("\t%s", middlewareImport)
-				result = append(result, line)
-			} else if strings.Contains(line, "import") && !strings.Contains(line, "(") {
-				// Single import line, add after it
-				result = append(result, fmt.Sprintf("import %s", middlewareImport))
-			}
-			importAdded = true
+	insertAt := index + 1
+	for insertAt < len(block.List) {
+		existingType, ok := useStmtType(block.List[insertAt], routerVar)
+		if !ok || aw.getMiddlewarePosition(existingType) > position {
+			break
 		}
+		insertAt++
+	}
 
-		// Add middleware use statement
-		if !middlewareAdded && aw.isRouterSetupLocation(line, pattern, position, lines, i) {
-			middlewareCall := aw.generateMiddlewareCall(middlewareType, pattern)
-			result = append(result, middlewareCall)
-			middlewareAdded = true
+	call := aw.generateMiddlewareCall(middlewareType, pkg)
+	if strings.Contains(call, "time.") {
+		if _, added := file.EnsureImport("time", "time"); added {
+			changes = append(changes, `Add import: "time"`)
 		}
 	}
+	if pattern == RouterEcho {
+		// Echo middleware has its own signature; adapt the net/http one
+		call = fmt.Sprintf("%s.WrapMiddleware(%s)", routerPkg, call)
+	}
 
-	if !middlewareAdded {
-		return "", fmt.Errorf("could not find suitable location to add middleware")
+	stmts, err := astedit.ParseStmts(fmt.Sprintf("%s.Use(%s)", routerVar, call), file.LineEnd(block.List[insertAt-1].End()))
+	if err != nil {
+		return nil, err
 	}
+	block.List = astedit.InsertStmts(block.List, insertAt, stmts...)
+	changes = append(changes, fmt.Sprintf("Add %s middleware: %s.Use(%s)", middlewareType, routerVar, call))
 
-	return strings.Join(result, "\n"), nil
-}
+	modified, err := file.Render()
+	if err != nil {
+		return nil, err
+	}
 
-// isImportSection determines if we're in the import section
-func (aw *AutoWirer) isImportSection(line string, index int, lines []string) bool {
-	trimmed := strings.TrimSpace(line)
+	return &routes.Update{
+		Path:     mainFile,
+		Original: file.Original,
+		Modified: modified,
+		Changes:  changes,
+	}, nil
+}
 
-	// Single import line
-	if strings.HasPrefix(trimmed, "import ") && !strings.Contains(trimmed, "(") {
-		return true
+// findMainFile locates the main.go file in the project
+func (aw *AutoWirer) findMainFile() (string, error) {
+	if aw.mainFile != "" {
+		fullPath := filepath.Join(aw.projectPath, aw.mainFile)
+		if _, err := os.Stat(fullPath); err != nil {
+			return "", err
+		}
+		return fullPath, nil
 	}
 
-	// Multi-line import block - look for the import statement
-	if strings.Contains(trimmed, "import (") {
-		return true
+	// Common locations for main.go (check root first since it's most common)
+	candidates := []string{
+		"main.go", // Root (most common)
+		filepath.Join("cmd", project.GetProjectName(), "main.go"), // Standard layout
+		"cmd/main.go", // Generic cmd
 	}
 
-	// We're inside an import block - check if we haven't hit the closing )
-	if index > 0 {
-		// Look backwards to find the import block start
-		for i := index - 1; i >= 0; i-- {
-			prevLine := strings.TrimSpace(lines[i])
-			if strings.Contains(prevLine, "import (") {
-				// We're in an import block, check if current line is before the closing )
-				return !strings.Contains(trimmed, ")") || (strings.Contains(trimmed, ")") && strings.Contains(trimmed, "\""))
-			}
-			// If we find a non-empty, non-comment line that's not an import, we're not in imports
-			if prevLine != "" && !strings.HasPrefix(prevLine, "//") && !strings.Contains(prevLine, "import") {
-				break
-			}
+	for _, candidate := range candidates {
+		fullPath := filepath.Join(aw.projectPath, candidate)
+		if _, err := os.Stat(fullPath); err == nil {
+			return fullPath, nil
 		}
 	}
 
-	return false
+	return "", fmt.Errorf("main.go not found in expected locations")
 }
 
-// isRouterSetupLocation determines if this is where we should add middleware
-func (aw *AutoWirer) isRouterSetupLocation(line string, pattern RouterPattern, position MiddlewarePosition, lines []string, index int) bool {
-	trimmed := strings.TrimSpace(line)
-
-	switch pattern {
-	case RouterChi:
-		// Look for router creation or existing middleware
-		if strings.Contains(trimmed, "chi.NewRouter()") || strings.Contains(trimmed, ":= chi.NewRouter()") {
-			return position == PositionEarly
+// detectRouterPattern inspects the imports of main.go to detect the router
+// pattern and the name the router package is imported under
+func (aw *AutoWirer) detectRouterPattern(file *astedit.File) (RouterPattern, string) {
+	for importPath, pattern := range routes.RouterImports {
+		if name := file.ImportName(importPath); name != "" {
+			return pattern, name
 		}
+	}
+	return RouterHTTP, ""
+}
 
-		// Insert based on position relative to existing middleware
-		if strings.Contains(trimmed, ".Use(") {
-			return aw.shouldInsertAtPosition(trimmed, position)
-		}
+// findRouter locates the statement that creates the router and returns the
+// enclosing block, the statement index and the router variable name
+func (aw *AutoWirer) findRouter(file *ast.File, pattern RouterPattern, routerPkg string) (*ast.BlockStmt, int, string) {
+	var block *ast.BlockStmt
+	var index int
+	var routerVar string
 
-		// Before route definitions
-		if strings.Contains(trimmed, ".Route(") || strings.Contains(trimmed, ".Get(") ||
-			strings.Contains(trimmed, ".Post(") || strings.Contains(trimmed, ".Mount(") {
-			return true
+	ast.Inspect(file, func(n ast.Node) bool {
+		if block != nil {
+			return false
 		}
-
-	case RouterGin:
-		if strings.Contains(trimmed, "gin.Default()") || strings.Contains(trimmed, "gin.New()") {
-			return position == PositionEarly
+		b, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
 		}
-
-		if strings.Contains(trimmed, ".Use(") {
-			return aw.shouldInsertAtPosition(trimmed, position)
+		for i, stmt := range b.List {
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			ident, ok := assign.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			for _, constructor := range routerConstructors[pattern] {
+				if astedit.IsSelector(call.Fun, routerPkg, constructor) {
+					block, index, routerVar = b, i, ident.Name
+					return false
+				}
+			}
 		}
+		return true
+	})
 
-	case RouterGorilla:
-		// Look for router creation - insert immediately after for early middleware
-		if strings.Contains(trimmed, "mux.NewRouter()") || strings.Contains(trimmed, ":= mux.NewRouter()") {
-			return position == PositionEarly
-		}
+	return block, index, routerVar
+}
 
-		// Look for existing middleware usage - insert based on position
-		if strings.Contains(trimmed, "router.Use(") {
-			return aw.shouldInsertAtPosition(trimmed, position)
+// findUseStmts returns the router.Use statements that register the middleware
+func (aw *AutoWirer) findUseStmts(block *ast.BlockStmt, routerVar, pkg, name string) []ast.Stmt {
+	var found []ast.Stmt
+	for _, stmt := range block.List {
+		if _, ok := useStmtType(stmt, routerVar); !ok {
+			continue
 		}
-
-		// Insert before route setup for early/middle middleware if no existing middleware
-		if position <= PositionMiddle {
-			if strings.Contains(trimmed, "router.HandleFunc(") ||
-				strings.Contains(trimmed, ".PathPrefix(") ||
-				strings.Contains(trimmed, "setupRoutes(") {
-				return true
+		match := false
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if expr, ok := n.(ast.Expr); ok && astedit.IsSelector(expr, pkg, name) {
+				match = true
 			}
-		}
-
-		// Insert before server configuration for late middleware
-		if strings.Contains(trimmed, "&http.Server{") || strings.Contains(trimmed, "srv := &http.Server") {
-			return true
-		}
-
-	case RouterHTTP:
-		// For net/http, add before http.ListenAndServe
-		if strings.Contains(trimmed, "http.ListenAndServe") {
-			return true
+			return !match
+		})
+		if match {
+			found = append(found, stmt)
 		}
 	}
-
-	return false
+	return found
 }
 
-// shouldInsertAtPosition determines if middleware should be inserted at this position
-func (aw *AutoWirer) shouldInsertAtPosition(line string, position MiddlewarePosition) bool {
-	line = strings.ToLower(line)
-
-	switch position {
-	case PositionEarly:
-		// Insert before any middleware, or at the very beginning
-		return true
-
-	case PositionMiddle:
-		// Insert after early middleware, before auth/rate limiting
-		hasEarly := strings.Contains(line, "recovery") || strings.Contains(line, "cors")
-		hasLate := strings.Contains(line, "auth") || strings.Contains(line, "ratelimit")
-		return hasEarly || !hasLate
-
-	case PositionLate:
-		// Insert after all other middleware
-		return true
+// useStmtType reports whether stmt is a routerVar.Use(...) call and returns
+// the middleware type it appears to register
+func useStmtType(stmt ast.Stmt, routerVar string) (string, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return "", false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok || !astedit.IsSelector(call.Fun, routerVar, "Use") {
+		return "", false
 	}
 
-	return false
-}
-
-// generateMiddlewareCall creates the appropriate middleware call for the router pattern
-func (aw *AutoWirer) generateMiddlewareCall(middlewareType string, pattern RouterPattern) string {
-	middlewareName := strings.Title(middlewareType) + "Middleware"
-
-	switch pattern {
-	case RouterChi, RouterGin:
-		if middlewareType == "ratelimit" {
-			return fmt.Sprintf("\tr.Use(middleware.RateLimitMiddleware(100, time.Minute))")
-		}
-		if middlewareType == "timeout" {
-			return fmt.Sprintf("\tr.Use(middleware.TimeoutMiddleware(30 * time.Second))")
-		}
-		return fmt.Sprintf("\tr.Use(middleware.%s)", middlewareName)
-
-	case RouterGorilla:
-		if middlewareType == "ratelimit" {
-			return fmt.Sprintf("\trouter.Use(middleware.RateLimitMiddleware(100, time.Minute))")
-		}
-		if middlewareType == "timeout" {
-			return fmt.Sprintf("\trouter.Use(middleware.TimeoutMiddleware(30 * time.Second))")
+	middlewareType := ""
+	ast.Inspect(call, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel != call.Fun && sel.Sel.Name != "WrapMiddleware" && middlewareType == "" {
+			middlewareType = strings.ToLower(strings.TrimSuffix(sel.Sel.Name, "Middleware"))
 		}
-		return fmt.Sprintf("\trouter.Use(middleware.%s)", middlewareName)
+		return middlewareType == ""
+	})
+	return middlewareType, true
+}
 
-	case RouterHTTP:
-		// For net/http, we'd need to wrap handlers
-		return fmt.Sprintf("\t// Add middleware.%s to your handler chain", middlewareName)
+// middlewareName returns the exported function name for a middleware type
+func middlewareName(middlewareType string) string {
+	switch middlewareType {
+	case "ratelimit":
+		return "RateLimitMiddleware"
 	}
+	return strings.ToUpper(middlewareType[:1]) + middlewareType[1:] + "Middleware"
+}
 
-	return fmt.Sprintf("\trouter.Use(middleware.%s)", middlewareName)
+// generateMiddlewareCall creates the expression passed to router.Use
+func (aw *AutoWirer) generateMiddlewareCall(middlewareType, pkg string) string {
+	switch middlewareType {
+	case "ratelimit":
+		return fmt.Sprintf("%s.RateLimitMiddleware(100, time.Minute)", pkg)
+	case "timeout":
+		return fmt.Sprintf("%s.TimeoutMiddleware(30 * time.Second)", pkg)
+	}
+	return fmt.Sprintf("%s.%s", pkg, middlewareName(middlewareType))
 }
 
 // getMiddlewarePosition returns the recommended position for middleware type
 func (aw *AutoWirer) getMiddlewarePosition(middlewareType string) MiddlewarePosition {
 	switch middlewareType {
-	case "recovery", "cors":
+	case "recovery", "cors", "recoverer":
 		return PositionEarly
-	case "logging", "compression":
+	case "logging", "logger", "compression", "requestid":
 		return PositionMiddle
 	case "auth", "ratelimit", "timeout":
 		return PositionLate
@@ -382,42 +392,3 @@ func (aw *AutoWirer) getMiddlewarePosition(middlewareType string) MiddlewarePosi
 		return PositionMiddle
 	}
 }
-
-// showPreview displays the changes and asks for confirmation
-func (aw *AutoWirer) showPreview(filename, oldContent, newContent, middlewareType string) bool {
-	fmt.Printf("🔍 Preview changes to %s:\n\n", filename)
-
-	// Show diff-like output
-	oldLines := strings.Split(oldContent, "\n")
-	newLines := strings.Split(newContent, "\n")
-
-	// Simple diff - find added lines
-	addedLines := []string{}
-	for _, newLine := range newLines {
-		found := false
-		for _, oldLine := range oldLines {
-			if newLine == oldLine {
-				found = true
-				break
-			}
-		}
-		if !found && strings.TrimSpace(newLine) != "" {
-			addedLines = append(addedLines, newLine)
-		}
-	}
-
-	for _, line := range addedLines {
-		fmt.Printf("+ %s\n", line)
-	}
-
-	fmt.Printf("\nThis will add the %s middleware to your router.\n", middlewareType)
-	fmt.Print("Apply these changes? (y/n): ")
-
-	scanner := bufio.NewScanner(os.Stdin)
-	if scanner.Scan() {
-		response := strings.ToLower(strings.TrimSpace(scanner.Text()))
-		return response == "y" || response == "yes"
-	}
-
-	return false
-}
@@ -0,0 +1,373 @@
+// Package openapi reads OpenAPI 3 documents and turns their operations into
+// handler specifications for the scaffolder. It also describes an existing
+// project's routes as a document.
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the subset of an OpenAPI 3 document used for code generation
+type Document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       Info                 `yaml:"info"`
+	Servers    []Server             `yaml:"servers,omitempty"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components,omitempty"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+// Info holds the document metadata
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// Components holds the reusable objects that can be referenced with $ref
+type Components struct {
+	Schemas       SchemaMap               `yaml:"schemas,omitempty"`
+	Parameters    map[string]*Parameter   `yaml:"parameters,omitempty"`
+	RequestBodies map[string]*RequestBody `yaml:"requestBodies,omitempty"`
+	Responses     map[string]*Response    `yaml:"responses,omitempty"`
+}
+
+// PathItem describes the operations available on a single path
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters,omitempty"`
+	Get        *Operation   `yaml:"get,omitempty"`
+	Put        *Operation   `yaml:"put,omitempty"`
+	Post       *Operation   `yaml:"post,omitempty"`
+	Delete     *Operation   `yaml:"delete,omitempty"`
+	Options    *Operation   `yaml:"options,omitempty"`
+	Head       *Operation   `yaml:"head,omitempty"`
+	Patch      *Operation   `yaml:"patch,omitempty"`
+	Trace      *Operation   `yaml:"trace,omitempty"`
+}
+
+// Operations returns the operations of the path keyed by HTTP method, in a
+// stable order
+func (p *PathItem) Operations() ([]string, []*Operation) {
+	methods := []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "TRACE"}
+	all := []*Operation{p.Get, p.Post, p.Put, p.Patch, p.Delete, p.Head, p.Options, p.Trace}
+
+	var outMethods []string
+	var outOps []*Operation
+	for i, op := range all {
+		if op != nil {
+			outMethods = append(outMethods, methods[i])
+			outOps = append(outOps, op)
+		}
+	}
+	return outMethods, outOps
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	OperationID string               `yaml:"operationId,omitempty"`
+	Summary     string               `yaml:"summary,omitempty"`
+	Description string               `yaml:"description,omitempty"`
+	Tags        []string             `yaml:"tags,omitempty"`
+	Parameters  []*Parameter         `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses,omitempty"`
+}
+
+// Parameter describes a single operation parameter
+type Parameter struct {
+	Ref         string  `yaml:"$ref,omitempty"`
+	Name        string  `yaml:"name,omitempty"`
+	In          string  `yaml:"in,omitempty"`
+	Description string  `yaml:"description,omitempty"`
+	Required    bool    `yaml:"required,omitempty"`
+	Schema      *Schema `yaml:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Ref         string                `yaml:"$ref,omitempty"`
+	Description string                `yaml:"description,omitempty"`
+	Required    bool                  `yaml:"required,omitempty"`
+	Content     map[string]*MediaType `yaml:"content,omitempty"`
+}
+
+// Response describes a single response of an operation
+type Response struct {
+	Ref         string                `yaml:"$ref,omitempty"`
+	Description string                `yaml:"description,omitempty"`
+	Content     map[string]*MediaType `yaml:"content,omitempty"`
+}
+
+// MediaType holds the schema of a request or response body
+type MediaType struct {
+	Schema *Schema `yaml:"schema,omitempty"`
+}
+
+// Schema is the subset of JSON Schema used to generate Go types
+type Schema struct {
+	Ref                  string        `yaml:"$ref,omitempty"`
+	Type                 SchemaType    `yaml:"type,omitempty"`
+	Format               string        `yaml:"format,omitempty"`
+	Description          string        `yaml:"description,omitempty"`
+	Properties           SchemaMap     `yaml:"properties,omitempty"`
+	Required             []string      `yaml:"required,omitempty"`
+	Items                *Schema       `yaml:"items,omitempty"`
+	AdditionalProperties *Schema       `yaml:"-"`
+	Enum                 []interface{} `yaml:"enum,omitempty"`
+	AllOf                []*Schema     `yaml:"allOf,omitempty"`
+	OneOf                []*Schema     `yaml:"oneOf,omitempty"`
+	AnyOf                []*Schema     `yaml:"anyOf,omitempty"`
+	Minimum              *float64      `yaml:"minimum,omitempty"`
+	Maximum              *float64      `yaml:"maximum,omitempty"`
+	MinLength            *int          `yaml:"minLength,omitempty"`
+	MaxLength            *int          `yaml:"maxLength,omitempty"`
+}
+
+// UnmarshalYAML decodes a schema, accepting additionalProperties as either a
+// boolean or a schema
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "additionalProperties" {
+			continue
+		}
+		value := node.Content[i+1]
+		if value.Kind == yaml.ScalarNode {
+			if value.Value == "true" {
+				s.AdditionalProperties = &Schema{}
+			}
+			continue
+		}
+		s.AdditionalProperties = &Schema{}
+		if err := value.Decode(s.AdditionalProperties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalYAML encodes a schema, including additionalProperties
+func (s *Schema) MarshalYAML() (interface{}, error) {
+	type plain Schema
+	node := &yaml.Node{}
+	if err := node.Encode((*plain)(s)); err != nil {
+		return nil, err
+	}
+
+	if s.AdditionalProperties != nil {
+		value := &yaml.Node{}
+		if err := value.Encode(s.AdditionalProperties); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "additionalProperties"}, value)
+	}
+	return node, nil
+}
+
+// SchemaType is the type keyword of a schema. OpenAPI 3.1 allows a list of
+// types such as [string, "null"]; the first non-null type is used.
+type SchemaType string
+
+// UnmarshalYAML decodes the type keyword from a string or a list
+func (t *SchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = SchemaType(node.Value)
+		return nil
+	}
+
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	for _, typ := range types {
+		if typ != "null" {
+			*t = SchemaType(typ)
+			return nil
+		}
+	}
+	return nil
+}
+
+// SchemaMap is a map of schemas that remembers the order of its keys
+type SchemaMap struct {
+	Keys    []string
+	Schemas map[string]*Schema
+}
+
+// UnmarshalYAML decodes a mapping of schemas in document order
+func (m *SchemaMap) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of schemas", node.Line)
+	}
+
+	m.Schemas = make(map[string]*Schema)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		schema := &Schema{}
+		if err := node.Content[i+1].Decode(schema); err != nil {
+			return err
+		}
+		m.Keys = append(m.Keys, key)
+		m.Schemas[key] = schema
+	}
+	return nil
+}
+
+// IsZero reports whether the map is empty, so omitempty skips it
+func (m SchemaMap) IsZero() bool {
+	return len(m.Keys) == 0
+}
+
+// Set adds or replaces a schema, keeping the original position of the key
+func (m *SchemaMap) Set(key string, schema *Schema) {
+	if m.Schemas == nil {
+		m.Schemas = make(map[string]*Schema)
+	}
+	if _, ok := m.Schemas[key]; !ok {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Schemas[key] = schema
+}
+
+// MarshalYAML encodes the schemas in key order
+func (m SchemaMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range m.Keys {
+		value := &yaml.Node{}
+		if err := value.Encode(m.Schemas[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return node, nil
+}
+
+// Load reads an OpenAPI 3 document in YAML or JSON format
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes an OpenAPI 3 document in YAML or JSON format
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		if doc.OpenAPI == "" {
+			return nil, fmt.Errorf("not an OpenAPI 3 document: missing openapi version")
+		}
+		return nil, fmt.Errorf("unsupported OpenAPI version %s (expected 3.x)", doc.OpenAPI)
+	}
+
+	if len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("OpenAPI document defines no paths")
+	}
+
+	return doc, nil
+}
+
+// refName returns the component name referenced by ref, which must point
+// into the given components section of the same document
+func refName(ref, section string) (string, error) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		if !strings.HasPrefix(ref, "#/") {
+			return "", fmt.Errorf("external reference %q is not supported; bundle the document into a single file first", ref)
+		}
+		return "", fmt.Errorf("reference %q does not point to components/%s", ref, section)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+// ResolveSchema follows a schema reference and returns the component name
+// and the referenced schema
+func (d *Document) ResolveSchema(ref string) (string, *Schema, error) {
+	name, err := refName(ref, "schemas")
+	if err != nil {
+		return "", nil, err
+	}
+	schema, ok := d.Components.Schemas.Schemas[name]
+	if !ok {
+		return "", nil, fmt.Errorf("schema %q is not defined", ref)
+	}
+	return name, schema, nil
+}
+
+// ResolveParameter follows a parameter reference
+func (d *Document) ResolveParameter(p *Parameter) (*Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("parameter %q is not defined", p.Ref)
+	}
+	return resolved, nil
+}
+
+// ResolveRequestBody follows a request body reference
+func (d *Document) ResolveRequestBody(b *RequestBody) (*RequestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, err := refName(b.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.RequestBodies[name]
+	if !ok {
+		return nil, fmt.Errorf("request body %q is not defined", b.Ref)
+	}
+	return resolved, nil
+}
+
+// ResolveResponse follows a response reference
+func (d *Document) ResolveResponse(r *Response) (*Response, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+	name, err := refName(r.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("response %q is not defined", r.Ref)
+	}
+	return resolved, nil
+}
+
+// jsonSchema returns the schema of the JSON content of a body, if any
+func jsonSchema(content map[string]*MediaType) *Schema {
+	if media, ok := content["application/json"]; ok && media != nil {
+		return media.Schema
+	}
+	for contentType, media := range content {
+		if strings.HasSuffix(contentType, "+json") && media != nil {
+			return media.Schema
+		}
+	}
+	return nil
+}
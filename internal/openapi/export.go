@@ -0,0 +1,1222 @@
+// internal/openapi/export.go
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	projectparser "github.com/shapestone/foundry/internal/parser"
+)
+
+// statusCodes maps net/http status constants to their codes
+var statusCodes = map[string]int{
+	"StatusOK":                  200,
+	"StatusCreated":             201,
+	"StatusAccepted":            202,
+	"StatusNoContent":           204,
+	"StatusMovedPermanently":    301,
+	"StatusFound":               302,
+	"StatusNotModified":         304,
+	"StatusBadRequest":          400,
+	"StatusUnauthorized":        401,
+	"StatusForbidden":           403,
+	"StatusNotFound":            404,
+	"StatusConflict":            409,
+	"StatusUnprocessableEntity": 422,
+	"StatusInternalServerError": 500,
+}
+
+// chiMethods maps chi router methods to HTTP methods
+var chiMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH", "Delete": "DELETE",
+	"Head": "HEAD", "Options": "OPTIONS", "Trace": "TRACE", "Connect": "CONNECT",
+}
+
+// groupMethods maps the route methods of gin and echo groups to HTTP methods
+var groupMethods = map[string]string{
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH", "DELETE": "DELETE",
+	"HEAD": "HEAD", "OPTIONS": "OPTIONS", "TRACE": "TRACE", "CONNECT": "CONNECT",
+}
+
+// paramPattern matches chi path parameters with an optional regexp, {id:[0-9]+}
+var paramPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// colonParamPattern matches gin and echo path parameters, :id and *path
+var colonParamPattern = regexp.MustCompile(`[:*]([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ExportOptions controls how a project is described
+type ExportOptions struct {
+	Title   string
+	Version string
+	// Server is the route group handlers are mounted under, e.g. "/api/v1".
+	// It becomes the server URL and is left out of the paths.
+	Server string
+}
+
+// Export builds an OpenAPI 3.1 document describing the handlers mounted in
+// the routes of the project at projectRoot. Request and response bodies are
+// described with the structs in internal/handlers and internal/models.
+func Export(projectRoot string, opts ExportOptions) (*Document, error) {
+	components, err := projectparser.NewProjectAnalyzerAdapter().GetExistingComponents(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+	if len(components.Routes) == 0 {
+		return nil, fmt.Errorf("no route files found in internal/routes")
+	}
+
+	e := &exporter{
+		fset:    token.NewFileSet(),
+		structs: make(map[string]*goStruct),
+		funcs:   make(map[string]*ast.FuncDecl),
+		doc: &Document{
+			OpenAPI: "3.1.0",
+			Info:    Info{Title: opts.Title, Version: opts.Version},
+			Paths:   make(map[string]*PathItem),
+		},
+	}
+	if opts.Server != "" && opts.Server != "/" {
+		e.server = strings.TrimSuffix(opts.Server, "/")
+		e.doc.Servers = []Server{{URL: e.server}}
+	}
+
+	if err := e.loadPackage("handlers", filepath.Join(projectRoot, "internal", "handlers"), components.Handlers); err != nil {
+		return nil, err
+	}
+	if err := e.loadPackage("models", filepath.Join(projectRoot, "internal", "models"), components.Models); err != nil {
+		return nil, err
+	}
+
+	var mounts []mount
+	for _, name := range components.Routes {
+		routesPath := filepath.Join(projectRoot, "internal", "routes", name+".go")
+		file, err := parser.ParseFile(e.fset, routesPath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", routesPath, err)
+		}
+		found, err := e.findMounts(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", routesPath, err)
+		}
+		mounts = append(mounts, found...)
+	}
+
+	for _, m := range mounts {
+		if err := e.exportHandler(m); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every model is documented, even if no handler refers to it yet
+	for _, key := range e.order {
+		if s := e.structs[key]; s.pkg == "models" {
+			e.schemaRef(s)
+		}
+	}
+
+	return e.doc, nil
+}
+
+// goStruct is a struct type declared in the handlers or models package
+type goStruct struct {
+	pkg  string
+	name string
+	doc  string
+	typ  *ast.StructType
+}
+
+// mount is a handler mounted at a path in routes.go
+type mount struct {
+	path    string
+	handler string // handler type name, e.g. "UserHandler"
+	method  string // method that registers the routes: Routes or RegisterRoutes
+}
+
+// exporter collects the declarations of a project and builds the document
+type exporter struct {
+	fset   *token.FileSet
+	doc    *Document
+	server string
+
+	structs map[string]*goStruct     // keyed by "pkg.Name"
+	order   []string                 // struct keys in declaration order
+	funcs   map[string]*ast.FuncDecl // keyed by "Recv.Name" or "Name"
+}
+
+// loadPackage indexes the structs and functions declared in files of dir
+func (e *exporter) loadPackage(pkg, dir string, files []string) error {
+	sort.Strings(files)
+	for _, name := range files {
+		filePath := filepath.Join(dir, name+".go")
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		file, err := parser.ParseFile(e.fset, filePath, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil {
+						doc = d.Doc
+					}
+					key := pkg + "." + ts.Name.Name
+					e.structs[key] = &goStruct{pkg: pkg, name: ts.Name.Name, doc: docSummary(ts.Name.Name, doc.Text()), typ: st}
+					e.order = append(e.order, key)
+				}
+			case *ast.FuncDecl:
+				if pkg == "handlers" {
+					e.funcs[funcKey(d)] = d
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// funcKey returns "Recv.Name" for methods and "Name" for functions
+func funcKey(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// findMounts returns the handlers mounted in a routes file. Handlers are
+// recognised by their constructor, handlers.NewXHandler, either called
+// inline or assigned to a variable first.
+func (e *exporter) findMounts(file *ast.File) ([]mount, error) {
+	pkg := ""
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if strings.HasSuffix(p, "/internal/handlers") {
+			pkg = "handlers"
+			if spec.Name != nil {
+				pkg = spec.Name.Name
+			}
+		}
+	}
+	if pkg == "" {
+		return nil, nil
+	}
+
+	var mounts []mount
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			found, err := e.walkRoutes(fn.Body.List, "", pkg, make(map[string]string), make(map[string]string))
+			if err != nil {
+				return nil, err
+			}
+			mounts = append(mounts, found...)
+		}
+	}
+	return mounts, nil
+}
+
+// walkRoutes finds mounts in stmts. prefix is the path of the enclosing
+// route groups, vars maps variables to the handler types they hold and
+// groups maps variables to the paths of the route groups they hold.
+func (e *exporter) walkRoutes(stmts []ast.Stmt, prefix, pkg string, vars, groups map[string]string) ([]mount, error) {
+	var mounts []mount
+	for _, stmt := range stmts {
+		if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == len(assign.Rhs) {
+			for i, lhs := range assign.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if handler := e.constructedHandler(assign.Rhs[i], pkg); handler != "" {
+					vars[ident.Name] = handler
+				}
+				// api := r.Group("/api/v1") or r.PathPrefix("/api/v1").Subrouter()
+				if _, isCall := assign.Rhs[i].(*ast.CallExpr); isCall {
+					if groupPath, ok := groupPath(assign.Rhs[i], prefix, groups); ok {
+						groups[ident.Name] = groupPath
+					}
+				}
+			}
+			continue
+		}
+
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		switch sel.Sel.Name {
+		case "Route":
+			if len(call.Args) == 2 {
+				if lit, ok := call.Args[1].(*ast.FuncLit); ok {
+					found, err := e.walkRoutes(lit.Body.List, joinPath(prefix, stringLit(call.Args[0])), pkg, vars, groups)
+					if err != nil {
+						return nil, err
+					}
+					mounts = append(mounts, found...)
+				}
+			}
+		case "Group":
+			if len(call.Args) == 1 {
+				if lit, ok := call.Args[0].(*ast.FuncLit); ok {
+					found, err := e.walkRoutes(lit.Body.List, prefix, pkg, vars, groups)
+					if err != nil {
+						return nil, err
+					}
+					mounts = append(mounts, found...)
+				}
+			}
+		case "Mount":
+			if len(call.Args) != 2 {
+				continue
+			}
+			routes, ok := call.Args[1].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			routesSel, ok := routes.Fun.(*ast.SelectorExpr)
+			if !ok || routesSel.Sel.Name != "Routes" {
+				continue
+			}
+			if handler := e.handlerOf(routesSel.X, pkg, vars); handler != "" {
+				base, _ := groupPath(sel.X, prefix, groups)
+				mounts = append(mounts, mount{path: joinPath(base, stringLit(call.Args[0])), handler: handler, method: "Routes"})
+			}
+		case "RegisterRoutes":
+			handler := e.handlerOf(sel.X, pkg, vars)
+			if handler == "" {
+				continue
+			}
+			mountPath, err := registerPath(call, prefix, groups)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.fset.Position(call.Pos()), err)
+			}
+			mounts = append(mounts, mount{path: mountPath, handler: handler, method: "RegisterRoutes"})
+		}
+	}
+	return mounts, nil
+}
+
+// registerPath returns the path a RegisterRoutes call mounts a handler at:
+// that of the route group it is given for gin, echo and gorilla/mux, joined
+// with the path prefix given next to the ServeMux for net/http
+func registerPath(call *ast.CallExpr, prefix string, groups map[string]string) (string, error) {
+	if len(call.Args) == 0 {
+		return prefix, nil
+	}
+	base, ok := groupPath(call.Args[0], prefix, groups)
+	if !ok {
+		return "", fmt.Errorf("cannot tell the path of the route group %s", exprName(call.Args[0]))
+	}
+	if len(call.Args) == 1 {
+		return base, nil
+	}
+	elem, ok := concatString(call.Args[1], nil)
+	if !ok {
+		return "", fmt.Errorf("the path prefix given to RegisterRoutes must be a string literal")
+	}
+	return joinPath(base, elem), nil
+}
+
+// groupPath returns the path of the route group expr holds: a router
+// variable, r.Group("/users") for gin and echo or
+// r.PathPrefix("/users").Subrouter() for gorilla/mux. Variables that hold
+// no known group are the router of the enclosing group, at prefix.
+func groupPath(expr ast.Expr, prefix string, groups map[string]string) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if p, ok := groups[x.Name]; ok {
+			return p, true
+		}
+		return prefix, true
+	case *ast.CallExpr:
+		sel, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", false
+		}
+		switch sel.Sel.Name {
+		case "Group":
+			// gin and echo take middleware after the path
+			if len(x.Args) == 0 {
+				return "", false
+			}
+			elem, ok := concatString(x.Args[0], nil)
+			if !ok {
+				return "", false
+			}
+			base, ok := groupPath(sel.X, prefix, groups)
+			return joinPath(base, elem), ok
+		case "Subrouter":
+			inner, ok := sel.X.(*ast.CallExpr)
+			if !ok || len(inner.Args) != 1 {
+				return "", false
+			}
+			innerSel, ok := inner.Fun.(*ast.SelectorExpr)
+			if !ok || innerSel.Sel.Name != "PathPrefix" {
+				return "", false
+			}
+			elem, ok := concatString(inner.Args[0], nil)
+			if !ok {
+				return "", false
+			}
+			base, ok := groupPath(innerSel.X, prefix, groups)
+			return joinPath(base, elem), ok
+		}
+	}
+	return "", false
+}
+
+// handlerOf returns the handler type held by expr, a variable or a constructor call
+func (e *exporter) handlerOf(expr ast.Expr, pkg string, vars map[string]string) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return vars[ident.Name]
+	}
+	return e.constructedHandler(expr, pkg)
+}
+
+// constructedHandler returns the type created by a handlers.NewX(...) call
+func (e *exporter) constructedHandler(expr ast.Expr, pkg string) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != pkg || !strings.HasPrefix(sel.Sel.Name, "New") {
+		return ""
+	}
+
+	// Prefer the declared result type, fall back to the naming convention
+	if fn, ok := e.funcs[sel.Sel.Name]; ok && fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+		result := fn.Type.Results.List[0].Type
+		if star, ok := result.(*ast.StarExpr); ok {
+			result = star.X
+		}
+		if ident, ok := result.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return strings.TrimPrefix(sel.Sel.Name, "New")
+}
+
+// route is a single method and path served by a handler method
+type route struct {
+	method  string
+	path    string
+	handler string
+}
+
+// exportHandler adds the operations of a mounted handler to the document
+func (e *exporter) exportHandler(m mount) error {
+	fn, ok := e.funcs[m.handler+"."+m.method]
+	if !ok || fn.Body == nil {
+		return fmt.Errorf("handler %s is mounted, but its %s method was not found in internal/handlers", m.handler, m.method)
+	}
+
+	var routes []route
+	if m.method == "Routes" {
+		routes = e.chiRoutes(fn.Body.List, "")
+	} else {
+		routes = e.registeredRoutes(fn)
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("no routes found in %s.%s; routes are recognised for chi, gin, echo, gorilla/mux and net/http", m.handler, m.method)
+	}
+
+	resource := strings.TrimSuffix(m.handler, "Handler")
+	for _, r := range routes {
+		fullPath := joinPath(m.path, r.path)
+		if e.server != "" && strings.HasPrefix(fullPath, e.server+"/") {
+			fullPath = strings.TrimPrefix(fullPath, e.server)
+		}
+		fullPath = paramPattern.ReplaceAllString(fullPath, "{$1}")
+
+		item, ok := e.doc.Paths[fullPath]
+		if !ok {
+			item = &PathItem{}
+			e.doc.Paths[fullPath] = item
+		}
+
+		operation := e.operation(m.handler, resource, r, fullPath)
+		switch r.method {
+		case "GET":
+			item.Get = operation
+		case "POST":
+			item.Post = operation
+		case "PUT":
+			item.Put = operation
+		case "PATCH":
+			item.Patch = operation
+		case "DELETE":
+			item.Delete = operation
+		case "HEAD":
+			item.Head = operation
+		case "OPTIONS":
+			item.Options = operation
+		case "TRACE":
+			item.Trace = operation
+		}
+	}
+	return nil
+}
+
+// chiRoutes collects the routes registered on a chi router in stmts
+func (e *exporter) chiRoutes(stmts []ast.Stmt, prefix string) []route {
+	var routes []route
+	for _, stmt := range stmts {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		name := sel.Sel.Name
+		switch {
+		case chiMethods[name] != "" && len(call.Args) == 2:
+			if handler := handlerMethod(call.Args[1]); handler != "" {
+				routes = append(routes, route{method: chiMethods[name], path: joinPath(prefix, stringLit(call.Args[0])), handler: handler})
+			}
+		case (name == "Method" || name == "MethodFunc") && len(call.Args) == 3:
+			if handler := handlerMethod(call.Args[2]); handler != "" {
+				routes = append(routes, route{method: strings.ToUpper(stringLit(call.Args[0])), path: joinPath(prefix, stringLit(call.Args[1])), handler: handler})
+			}
+		case name == "Route" && len(call.Args) == 2:
+			if lit, ok := call.Args[1].(*ast.FuncLit); ok {
+				routes = append(routes, e.chiRoutes(lit.Body.List, joinPath(prefix, stringLit(call.Args[0])))...)
+			}
+		case name == "Group" && len(call.Args) == 1:
+			if lit, ok := call.Args[0].(*ast.FuncLit); ok {
+				routes = append(routes, e.chiRoutes(lit.Body.List, prefix)...)
+			}
+		}
+	}
+	return routes
+}
+
+// registeredRoutes collects the routes a RegisterRoutes method registers:
+//
+//	r.HandleFunc("/{id}", h.Get).Methods(http.MethodGet)   gorilla/mux
+//	r.GET("/:id", h.handle(h.Get))                         gin and echo
+//	mux.HandleFunc("GET "+prefix+"/{id}", h.Get)           net/http
+func (e *exporter) registeredRoutes(fn *ast.FuncDecl) []route {
+	// The path prefix parameter of net/http handlers is the mount path,
+	// which the routes are joined to
+	params := make(map[string]bool)
+	for _, field := range fn.Type.Params.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "string" {
+			for _, name := range field.Names {
+				params[name.Name] = true
+			}
+		}
+	}
+
+	prefixes := make(map[string]string)
+	var routes []route
+	for _, stmt := range fn.Body.List {
+		// sub := r.PathPrefix("/users").Subrouter()
+		if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+			if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+				if _, isCall := assign.Rhs[0].(*ast.CallExpr); isCall {
+					if p, ok := groupPath(assign.Rhs[0], "", prefixes); ok {
+						prefixes[ident.Name] = p
+					}
+				}
+			}
+			continue
+		}
+
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		switch name := sel.Sel.Name; {
+		case name == "Methods":
+			handle, ok := sel.X.(*ast.CallExpr)
+			if !ok || len(handle.Args) != 2 {
+				continue
+			}
+			handleSel, ok := handle.Fun.(*ast.SelectorExpr)
+			if !ok || (handleSel.Sel.Name != "HandleFunc" && handleSel.Sel.Name != "Handle") {
+				continue
+			}
+			handler := handlerMethod(handle.Args[1])
+			if handler == "" {
+				continue
+			}
+			routePath := joinPath(prefixes[exprName(handleSel.X)], stringLit(handle.Args[0]))
+			for _, arg := range call.Args {
+				if method := methodName(arg); method != "" {
+					routes = append(routes, route{method: method, path: routePath, handler: handler})
+				}
+			}
+		case groupMethods[name] != "" && len(call.Args) >= 2:
+			handler := handlerMethod(call.Args[1])
+			if handler == "" {
+				continue
+			}
+			routePath := colonParamPattern.ReplaceAllString(stringLit(call.Args[0]), "{$1}")
+			routes = append(routes, route{method: groupMethods[name], path: joinPath(prefixes[exprName(sel.X)], routePath), handler: handler})
+		case (name == "HandleFunc" || name == "Handle") && len(call.Args) == 2 && len(params) > 0:
+			pattern, ok := concatString(call.Args[0], params)
+			handler := handlerMethod(call.Args[1])
+			method, routePath, hasMethod := strings.Cut(pattern, " ")
+			if !ok || handler == "" || !hasMethod {
+				continue
+			}
+			routePath = strings.ReplaceAll(strings.TrimSuffix(strings.TrimSpace(routePath), "{$}"), "...}", "}")
+			routes = append(routes, route{method: strings.ToUpper(method), path: joinPath("", routePath), handler: handler})
+		}
+	}
+	return routes
+}
+
+// methodName returns the HTTP method named by a string literal or a
+// net/http constant such as http.MethodGet
+func methodName(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "http" && strings.HasPrefix(sel.Sel.Name, "Method") {
+			return strings.ToUpper(strings.TrimPrefix(sel.Sel.Name, "Method"))
+		}
+		return ""
+	}
+	return strings.ToUpper(stringLit(expr))
+}
+
+// concatString evaluates a string literal or a concatenation of string
+// literals; the variables in empty stand for the empty string
+func concatString(expr ast.Expr, empty map[string]bool) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(x.Value)
+		return value, err == nil
+	case *ast.Ident:
+		return "", empty[x.Name]
+	case *ast.ParenExpr:
+		return concatString(x.X, empty)
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+		left, ok := concatString(x.X, empty)
+		if !ok {
+			return "", false
+		}
+		right, ok := concatString(x.Y, empty)
+		return left + right, ok
+	}
+	return "", false
+}
+
+// operation describes the handler method serving a route
+func (e *exporter) operation(handlerType, resource string, r route, fullPath string) *Operation {
+	operation := &Operation{
+		OperationID: operationID(resource, r.handler),
+		Tags:        []string{strings.ToLower(resource)},
+		Responses:   make(map[string]*Response),
+	}
+
+	fn := e.funcs[handlerType+"."+r.handler]
+	if fn != nil {
+		operation.Summary = docSummary(r.handler, fn.Doc.Text())
+	}
+
+	// Path parameters are always present; their types come from the handler
+	info := e.analyzeHandler(fn)
+	for _, match := range paramPattern.FindAllStringSubmatch(fullPath, -1) {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: SchemaType(info.paramTypes["path:"+match[1]])},
+		})
+	}
+	for _, name := range info.query {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:   name,
+			In:     "query",
+			Schema: &Schema{Type: SchemaType(info.paramTypes["query:"+name])},
+		})
+	}
+
+	if info.request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: info.request}},
+		}
+	}
+
+	response := &Response{Description: http200Description(info.status)}
+	if info.response != nil {
+		response.Content = map[string]*MediaType{"application/json": {Schema: info.response}}
+	}
+	operation.Responses[strconv.Itoa(info.status)] = response
+
+	return operation
+}
+
+// handlerInfo is what can be learned from the body of a handler method
+type handlerInfo struct {
+	query      []string
+	paramTypes map[string]string // "path:id" or "query:limit" to schema type
+	request    *Schema
+	response   *Schema
+	status     int
+}
+
+// analyzeHandler inspects a handler method for parameters, the decoded
+// request body, the encoded response and the success status. Calls to a
+// Bind method on a local parameters struct are followed.
+func (e *exporter) analyzeHandler(fn *ast.FuncDecl) *handlerInfo {
+	info := &handlerInfo{paramTypes: make(map[string]string), status: 200}
+	if fn == nil || fn.Body == nil {
+		return info
+	}
+
+	locals := localTypes(fn.Body)
+	e.scanParams(fn.Body, info)
+
+	statusSet := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		switch sel.Sel.Name {
+		case "Bind":
+			// params.Bind(r) on a generated parameters struct
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if typ, ok := locals[ident.Name].(*ast.Ident); ok {
+					if bind := e.funcs[typ.Name+".Bind"]; bind != nil && bind.Body != nil {
+						e.scanParams(bind.Body, info)
+					}
+				}
+			}
+		case "Decode":
+			// json.NewDecoder(r.Body).Decode(&body)
+			if len(call.Args) == 1 {
+				if unary, ok := call.Args[0].(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					if ident, ok := unary.X.(*ast.Ident); ok && locals[ident.Name] != nil {
+						info.request = e.schemaFor(locals[ident.Name], "handlers")
+					}
+				}
+			}
+		case "Encode":
+			// json.NewEncoder(w).Encode(response)
+			if len(call.Args) == 1 && info.response == nil {
+				info.response = e.valueSchema(call.Args[0], locals)
+			}
+		case "WriteHeader":
+			if len(call.Args) == 1 && !statusSet {
+				if code := statusCode(call.Args[0]); code >= 200 && code < 300 {
+					info.status, statusSet = code, true
+				}
+			}
+		default:
+			// Helpers such as h.respond(w, http.StatusCreated, response)
+			if len(call.Args) == 3 && !statusSet {
+				if code := statusCode(call.Args[1]); code >= 200 && code < 300 {
+					info.status, statusSet = code, true
+					info.response = e.valueSchema(call.Args[2], locals)
+				}
+			}
+		}
+		return true
+	})
+
+	if info.status == 204 {
+		info.response = nil
+	}
+	return info
+}
+
+// scanParams records the path and query parameters read in body and infers
+// their types from the strconv calls that parse them
+func (e *exporter) scanParams(body *ast.BlockStmt, info *handlerInfo) {
+	vars := make(map[string]string) // variable name to "in:name"
+
+	paramOf := func(expr ast.Expr) string {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			if ident, ok := expr.(*ast.Ident); ok {
+				return vars[ident.Name]
+			}
+			if index, ok := expr.(*ast.IndexExpr); ok {
+				name := stringLit(index.Index)
+				if name == "" {
+					return ""
+				}
+				// r.URL.Query()["tag"] or mux.Vars(r)["id"]
+				if inner, ok := index.X.(*ast.CallExpr); ok {
+					if innerSel, ok := inner.Fun.(*ast.SelectorExpr); ok && innerSel.Sel.Name == "Query" {
+						return "query:" + name
+					}
+				}
+				return "path:" + name
+			}
+			return ""
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || len(call.Args) == 0 {
+			return ""
+		}
+		switch sel.Sel.Name {
+		case "URLParam":
+			if len(call.Args) == 2 {
+				return "path:" + stringLit(call.Args[1])
+			}
+		case "PathValue":
+			// r.PathValue("id") on net/http, and on gin and echo through h.handle
+			return "path:" + stringLit(call.Args[0])
+		case "Get":
+			// r.URL.Query().Get("limit")
+			if inner, ok := sel.X.(*ast.CallExpr); ok {
+				if innerSel, ok := inner.Fun.(*ast.SelectorExpr); ok && innerSel.Sel.Name == "Query" {
+					return "query:" + stringLit(call.Args[0])
+				}
+			}
+		}
+		return ""
+	}
+
+	record := func(param string) {
+		if param == "" || strings.HasSuffix(param, ":") {
+			return
+		}
+		if _, ok := info.paramTypes[param]; !ok {
+			info.paramTypes[param] = "string"
+			if name, ok := strings.CutPrefix(param, "query:"); ok {
+				info.query = append(info.query, name)
+			}
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if len(x.Lhs) == 1 && len(x.Rhs) == 1 {
+				if ident, ok := x.Lhs[0].(*ast.Ident); ok {
+					if param := paramOf(x.Rhs[0]); param != "" {
+						vars[ident.Name] = param
+						record(param)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if param := paramOf(x); param != "" {
+				record(param)
+			}
+			sel, ok := x.Fun.(*ast.SelectorExpr)
+			if !ok || len(x.Args) == 0 {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "strconv" {
+				return true
+			}
+			param := paramOf(x.Args[0])
+			if param == "" {
+				return true
+			}
+			record(param)
+			switch sel.Sel.Name {
+			case "Atoi", "ParseInt", "ParseUint":
+				info.paramTypes[param] = "integer"
+			case "ParseFloat":
+				info.paramTypes[param] = "number"
+			case "ParseBool":
+				info.paramTypes[param] = "boolean"
+			}
+		}
+		return true
+	})
+}
+
+// localTypes returns the declared types of the local variables in body
+func localTypes(body *ast.BlockStmt) map[string]ast.Expr {
+	types := make(map[string]ast.Expr)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ValueSpec:
+			if x.Type != nil {
+				for _, name := range x.Names {
+					types[name.Name] = x.Type
+				}
+			}
+		case *ast.AssignStmt:
+			if x.Tok != token.DEFINE || len(x.Lhs) != len(x.Rhs) {
+				return true
+			}
+			for i, lhs := range x.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				rhs := x.Rhs[i]
+				if unary, ok := rhs.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					rhs = unary.X
+				}
+				if lit, ok := rhs.(*ast.CompositeLit); ok && lit.Type != nil {
+					types[ident.Name] = lit.Type
+				}
+			}
+		}
+		return true
+	})
+	return types
+}
+
+// valueSchema describes the value written as a response
+func (e *exporter) valueSchema(expr ast.Expr, locals map[string]ast.Expr) *Schema {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if typ, ok := locals[x.Name]; ok {
+			return e.schemaFor(typ, "handlers")
+		}
+	case *ast.UnaryExpr:
+		return e.valueSchema(x.X, locals)
+	case *ast.CompositeLit:
+		// map[string]interface{}{"data": users} documents its keys
+		if _, ok := x.Type.(*ast.MapType); ok {
+			schema := &Schema{Type: "object"}
+			for _, elt := range x.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key := stringLit(kv.Key); key != "" {
+					schema.Properties.Set(key, e.valueSchema(kv.Value, locals))
+				}
+			}
+			return schema
+		}
+		if x.Type != nil {
+			return e.schemaFor(x.Type, "handlers")
+		}
+	}
+	return &Schema{}
+}
+
+// schemaFor converts a Go type expression from package pkg to a schema
+func (e *exporter) schemaFor(expr ast.Expr, pkg string) *Schema {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		switch x.Name {
+		case "string":
+			return &Schema{Type: "string"}
+		case "bool":
+			return &Schema{Type: "boolean"}
+		case "int", "int8", "int16", "uint", "uint8", "uint16", "uint32":
+			return &Schema{Type: "integer"}
+		case "int32":
+			return &Schema{Type: "integer", Format: "int32"}
+		case "int64", "uint64":
+			return &Schema{Type: "integer", Format: "int64"}
+		case "float32":
+			return &Schema{Type: "number", Format: "float"}
+		case "float64":
+			return &Schema{Type: "number", Format: "double"}
+		}
+		if s, ok := e.structs[pkg+"."+x.Name]; ok {
+			return e.schemaRef(s)
+		}
+	case *ast.SelectorExpr:
+		typeName := exprName(x)
+		switch typeName {
+		case "time.Time":
+			return &Schema{Type: "string", Format: "date-time"}
+		case "uuid.UUID":
+			return &Schema{Type: "string", Format: "uuid"}
+		case "json.RawMessage":
+			return &Schema{}
+		}
+		if pkgIdent, ok := x.X.(*ast.Ident); ok {
+			if s, ok := e.structs[pkgIdent.Name+"."+x.Sel.Name]; ok {
+				return e.schemaRef(s)
+			}
+		}
+	case *ast.StarExpr:
+		return e.schemaFor(x.X, pkg)
+	case *ast.ArrayType:
+		if ident, ok := x.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: e.schemaFor(x.Elt, pkg)}
+	case *ast.MapType:
+		return &Schema{Type: "object", AdditionalProperties: e.schemaFor(x.Value, pkg)}
+	case *ast.StructType:
+		return e.structSchema(x, pkg)
+	}
+	return &Schema{}
+}
+
+// schemaRef adds a struct to components/schemas and returns a reference to it
+func (e *exporter) schemaRef(s *goStruct) *Schema {
+	ref := &Schema{Ref: "#/components/schemas/" + s.name}
+	if _, ok := e.doc.Components.Schemas.Schemas[s.name]; ok {
+		return ref
+	}
+
+	// Reserve the name first so recursive types terminate
+	placeholder := &Schema{}
+	e.doc.Components.Schemas.Set(s.name, placeholder)
+	*placeholder = *e.structSchema(s.typ, s.pkg)
+	placeholder.Description = s.doc
+	return ref
+}
+
+// structSchema describes the JSON encoding of a struct
+func (e *exporter) structSchema(st *ast.StructType, pkg string) *Schema {
+	schema := &Schema{Type: "object"}
+
+	for _, field := range st.Fields.List {
+		name, omitempty, skip := jsonField(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs contribute their fields
+		if len(field.Names) == 0 && name == "" {
+			embedded := field.Type
+			if star, ok := embedded.(*ast.StarExpr); ok {
+				embedded = star.X
+			}
+			if ident, ok := embedded.(*ast.Ident); ok {
+				if s, ok := e.structs[pkg+"."+ident.Name]; ok {
+					inner := e.structSchema(s.typ, pkg)
+					for _, key := range inner.Properties.Keys {
+						schema.Properties.Set(key, inner.Properties.Schemas[key])
+					}
+					schema.Required = append(schema.Required, inner.Required...)
+				}
+			}
+			continue
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(exprName(field.Type))}
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			key := name
+			if key == "" {
+				key = ident.Name
+			}
+
+			property := e.schemaFor(field.Type, pkg)
+			if comment := firstLine(field.Doc.Text() + field.Comment.Text()); comment != "" && property.Ref == "" {
+				property.Description = comment
+			}
+			schema.Properties.Set(key, property)
+			if !omitempty {
+				schema.Required = append(schema.Required, key)
+			}
+		}
+	}
+
+	return schema
+}
+
+// jsonField reads the json tag of a struct field
+func jsonField(field *ast.Field) (name string, omitempty, skip bool) {
+	if field.Tag == nil {
+		return "", false, false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false, false
+	}
+	value, ok := reflectTag(tag, "json")
+	if !ok {
+		return "", false, false
+	}
+	if value == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(value, ",")
+	return name, strings.Contains(","+options+",", ",omitempty,"), false
+}
+
+// reflectTag looks up key in a struct tag like reflect.StructTag.Lookup
+func reflectTag(tag, key string) (string, bool) {
+	for _, part := range strings.Fields(tag) {
+		k, v, ok := strings.Cut(part, ":")
+		if ok && k == key {
+			value, err := strconv.Unquote(v)
+			return value, err == nil
+		}
+	}
+	return "", false
+}
+
+// docSummary returns the line of a doc comment that best summarises name.
+// Comments written by foundry open with a line restating the name, e.g.
+// "GetUser handles GET /users/{id}", followed by the summary from the
+// document they were generated from; that summary is preferred.
+func docSummary(name, text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 || strings.HasPrefix(lines[0], name+" is defined by the OpenAPI document") {
+		if len(lines) > 1 {
+			return lines[1]
+		}
+		return ""
+	}
+	if len(lines) > 1 && strings.HasPrefix(lines[0], name+" handles ") {
+		return lines[1]
+	}
+	return lines[0]
+}
+
+// handlerMethod returns the method name of a handler expression such as
+// h.List or http.HandlerFunc(h.List)
+func handlerMethod(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.CallExpr:
+		if len(x.Args) == 1 {
+			return handlerMethod(x.Args[0])
+		}
+	}
+	return ""
+}
+
+// operationID derives an operation ID from the handler method, adding the
+// resource name when the method name does not mention it
+func operationID(resource, method string) string {
+	id := strings.ToLower(method[:1]) + method[1:]
+	singular := strings.TrimSuffix(resource, "s")
+	if singular == "" || strings.Contains(strings.ToLower(method), strings.ToLower(singular)) {
+		return id
+	}
+	return id + resource
+}
+
+// statusCode returns the status of an http.StatusX constant or integer literal
+func statusCode(expr ast.Expr) int {
+	switch x := expr.(type) {
+	case *ast.SelectorExpr:
+		if ident, ok := x.X.(*ast.Ident); ok && ident.Name == "http" {
+			return statusCodes[x.Sel.Name]
+		}
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			code, _ := strconv.Atoi(x.Value)
+			return code
+		}
+	}
+	return 0
+}
+
+// http200Description returns the response description for a status
+func http200Description(status int) string {
+	switch status {
+	case 201:
+		return "Created"
+	case 202:
+		return "Accepted"
+	case 204:
+		return "No Content"
+	}
+	return "OK"
+}
+
+// stringLit returns the value of a string literal, or an empty string
+func stringLit(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// exprName renders identifiers and selectors such as time.Time
+func exprName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprName(x.X) + "." + x.Sel.Name
+	case *ast.StarExpr:
+		return exprName(x.X)
+	}
+	return ""
+}
+
+// joinPath joins URL path segments without leaving a trailing slash
+func joinPath(base, elem string) string {
+	joined := path.Join("/", base, elem)
+	if joined == "." {
+		return "/"
+	}
+	return joined
+}
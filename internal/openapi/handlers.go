@@ -0,0 +1,674 @@
+// internal/openapi/handlers.go
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/shapestone/foundry/internal/scaffolder"
+)
+
+// commonInitialisms are written in upper case in Go identifiers
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SKU": true, "SQL": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// Options controls which operations are turned into handlers
+type Options struct {
+	// Tag limits generation to the operations with this tag
+	Tag string
+	// PathPrefix is removed from operation paths, e.g. the "/api/v1" route
+	// group handlers are mounted under
+	PathPrefix string
+	// ExistingTypes are types already declared in the handlers package. They
+	// are referenced but not generated again.
+	ExistingTypes map[string]bool
+}
+
+// HandlerSpecs groups the operations of doc by tag and returns a handler
+// specification for each group. Operations without a tag are grouped by the
+// first segment of their path.
+func HandlerSpecs(doc *Document, opts Options) ([]scaffolder.HandlerSpec, error) {
+	c := &converter{
+		doc:     doc,
+		types:   make(map[string]*scaffolder.TypeSpec),
+		emitted: make(map[string]bool),
+	}
+	for name := range opts.ExistingTypes {
+		c.emitted[name] = true
+	}
+
+	groups, order, err := groupOperations(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) == 0 {
+		if opts.Tag != "" {
+			return nil, fmt.Errorf("no operations are tagged %q", opts.Tag)
+		}
+		return nil, fmt.Errorf("OpenAPI document defines no operations")
+	}
+
+	specs := make([]scaffolder.HandlerSpec, 0, len(order))
+	for _, key := range order {
+		spec, err := c.handlerSpec(key, groups[key])
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, *spec)
+	}
+
+	return specs, nil
+}
+
+// operationRef is an operation together with where it was declared
+type operationRef struct {
+	method    string
+	path      string
+	item      *PathItem
+	operation *Operation
+}
+
+// groupOperations collects the operations of doc by handler group
+func groupOperations(doc *Document, opts Options) (map[string][]operationRef, []string, error) {
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	groups := make(map[string][]operationRef)
+	var order []string
+	for _, rawPath := range paths {
+		item := doc.Paths[rawPath]
+		if item == nil {
+			continue
+		}
+
+		path := rawPath
+		if opts.PathPrefix != "" && opts.PathPrefix != "/" && strings.HasPrefix(path, opts.PathPrefix+"/") {
+			path = strings.TrimPrefix(path, opts.PathPrefix)
+		}
+
+		methods, operations := item.Operations()
+		for i, operation := range operations {
+			key := ""
+			switch {
+			case opts.Tag != "":
+				if !containsFold(operation.Tags, opts.Tag) {
+					continue
+				}
+				key = opts.Tag
+			case len(operation.Tags) > 0:
+				key = operation.Tags[0]
+			default:
+				key = firstStaticSegment(path)
+			}
+			if key == "" {
+				return nil, nil, fmt.Errorf("%s %s has no tag and no static path segment to group it by", methods[i], rawPath)
+			}
+
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], operationRef{
+				method:    methods[i],
+				path:      path,
+				item:      item,
+				operation: operation,
+			})
+		}
+	}
+
+	sort.Strings(order)
+	return groups, order, nil
+}
+
+// converter turns OpenAPI operations and schemas into scaffolder specs
+type converter struct {
+	doc *Document
+
+	// types holds every struct derived from the document, keyed by name
+	types map[string]*scaffolder.TypeSpec
+	// emitted records types that are already declared by a handler
+	emitted map[string]bool
+	// current collects the types declared by the handler being converted
+	current []scaffolder.TypeSpec
+}
+
+// handlerSpec converts a group of operations into a handler specification
+func (c *converter) handlerSpec(group string, refs []operationRef) (*scaffolder.HandlerSpec, error) {
+	name := handlerName(group)
+	if len(name) < 2 {
+		return nil, fmt.Errorf("cannot derive a handler name from %q", group)
+	}
+
+	basePath := commonBasePath(refs)
+	if basePath == "" {
+		return nil, fmt.Errorf("operations in %q do not share a common path prefix; use --tag to generate them separately", group)
+	}
+
+	c.current = nil
+	spec := &scaffolder.HandlerSpec{
+		Name:     name,
+		Type:     "openapi",
+		BasePath: basePath,
+		Metadata: map[string]string{"tag": group},
+	}
+
+	seen := make(map[string]string)
+	for _, ref := range refs {
+		operation, err := c.operationSpec(ref, basePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", ref.method, ref.path, err)
+		}
+		if previous, ok := seen[operation.Name]; ok {
+			return nil, fmt.Errorf("%s %s and %s both generate the method %s; set a unique operationId", ref.method, ref.path, previous, operation.Name)
+		}
+		seen[operation.Name] = ref.method + " " + ref.path
+		spec.Operations = append(spec.Operations, *operation)
+	}
+
+	spec.Types = c.current
+	return spec, nil
+}
+
+// operationSpec converts a single operation
+func (c *converter) operationSpec(ref operationRef, basePath string) (*scaffolder.OperationSpec, error) {
+	op := ref.operation
+
+	name := goName(op.OperationID)
+	if name == "" {
+		name = goName(strings.ToLower(ref.method) + " " + strings.NewReplacer("{", " by ", "}", " ").Replace(ref.path))
+	}
+
+	routePath := strings.TrimPrefix(ref.path, basePath)
+	if routePath == "" {
+		routePath = "/"
+	}
+
+	spec := &scaffolder.OperationSpec{
+		Name:    name,
+		Method:  ref.method,
+		Path:    routePath,
+		Summary: firstLine(op.Summary),
+		Status:  200,
+	}
+
+	if err := c.bindParameters(spec, ref); err != nil {
+		return nil, err
+	}
+
+	if op.RequestBody != nil {
+		body, err := c.doc.ResolveRequestBody(op.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		if schema := jsonSchema(body.Content); schema != nil {
+			requestType, err := c.goType(schema, name+"Request")
+			if err != nil {
+				return nil, err
+			}
+			spec.Request = requestType
+			if t, ok := c.types[requestType]; ok && t.HasValidation() {
+				spec.ValidateRequest = true
+			}
+		}
+	}
+
+	status, response, err := c.successResponse(op)
+	if err != nil {
+		return nil, err
+	}
+	spec.Status = status
+	if response != nil {
+		if schema := jsonSchema(response.Content); schema != nil {
+			responseType, err := c.goType(schema, name+"Response")
+			if err != nil {
+				return nil, err
+			}
+			spec.Response = responseType
+		}
+	}
+
+	return spec, nil
+}
+
+// bindParameters adds the path and query parameters of an operation
+func (c *converter) bindParameters(spec *scaffolder.OperationSpec, ref operationRef) error {
+	// Operation parameters override path item parameters with the same name
+	var params []*Parameter
+	index := make(map[string]int)
+	for _, raw := range append(append([]*Parameter{}, ref.item.Parameters...), ref.operation.Parameters...) {
+		param, err := c.doc.ResolveParameter(raw)
+		if err != nil {
+			return err
+		}
+		key := param.In + ":" + param.Name
+		if i, ok := index[key]; ok {
+			params[i] = param
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, param)
+	}
+
+	fields := make(map[string]bool)
+	for _, param := range params {
+		if param.In != "path" && param.In != "query" {
+			continue
+		}
+
+		field := scaffolder.ParamSpec{
+			FieldSpec: scaffolder.FieldSpec{
+				Name:     goName(param.Name),
+				Type:     paramType(param.Schema),
+				Required: param.Required || param.In == "path",
+			},
+			Key: param.Name,
+		}
+		if field.Name == "" || fields[field.Name] {
+			return fmt.Errorf("parameter %q cannot be mapped to a unique Go field", param.Name)
+		}
+		fields[field.Name] = true
+		applyConstraints(&field.FieldSpec, param.Schema)
+
+		if param.In == "path" {
+			if !strings.Contains(ref.path, "{"+param.Name+"}") {
+				return fmt.Errorf("path parameter %q does not appear in the path", param.Name)
+			}
+			spec.PathParams = append(spec.PathParams, field)
+		} else {
+			spec.QueryParams = append(spec.QueryParams, field)
+		}
+	}
+
+	if len(spec.PathParams)+len(spec.QueryParams) > 0 {
+		spec.Params = spec.Name + "Params"
+	}
+	return nil
+}
+
+// successResponse returns the status and response of the first 2xx response,
+// falling back to the default response
+func (c *converter) successResponse(op *Operation) (int, *Response, error) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			status = 200 // wildcard such as 2XX
+		}
+		response, err := c.doc.ResolveResponse(op.Responses[code])
+		return status, response, err
+	}
+
+	if response, ok := op.Responses["default"]; ok {
+		resolved, err := c.doc.ResolveResponse(response)
+		return 200, resolved, err
+	}
+	return 200, nil, nil
+}
+
+// goType returns the Go type for schema. Object schemas become named structs;
+// hint names inline objects.
+func (c *converter) goType(schema *Schema, hint string) (string, error) {
+	if schema == nil {
+		return "any", nil
+	}
+
+	if schema.Ref != "" {
+		ref, target, err := c.doc.ResolveSchema(schema.Ref)
+		if err != nil {
+			return "", err
+		}
+		if isObject(target) {
+			return c.ensureStruct(goName(ref), target)
+		}
+		return c.goType(target, goName(ref))
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "json.RawMessage", nil
+	}
+
+	if isObject(schema) {
+		return c.ensureStruct(hint, schema)
+	}
+
+	switch schema.Type {
+	case "string":
+		if schema.Format == "date-time" {
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := c.goType(schema.Items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if schema.AdditionalProperties != nil {
+			value, err := c.goType(schema.AdditionalProperties, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + value, nil
+		}
+		return "map[string]any", nil
+	}
+
+	return "any", nil
+}
+
+// ensureStruct registers the struct for an object schema and declares it in
+// the current handler unless another handler already does
+func (c *converter) ensureStruct(name string, schema *Schema) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("cannot name an inline object schema")
+	}
+	if _, ok := c.types[name]; ok {
+		return name, nil
+	}
+
+	// Register before converting fields so recursive schemas terminate
+	typeSpec := &scaffolder.TypeSpec{Name: name, Description: firstLine(schema.Description)}
+	c.types[name] = typeSpec
+
+	// Declare the struct ahead of the structs its fields introduce
+	index := -1
+	if !c.emitted[name] {
+		c.emitted[name] = true
+		index = len(c.current)
+		c.current = append(c.current, scaffolder.TypeSpec{})
+	}
+
+	keys, properties, required, err := c.collectProperties(schema)
+	if err != nil {
+		return "", err
+	}
+
+	for _, key := range keys {
+		property := properties[key]
+		field := scaffolder.FieldSpec{
+			Name:        goName(key),
+			Required:    required[key],
+			Description: firstLine(property.Description),
+			Tags:        map[string]string{"json": key},
+		}
+		if field.Name == "" {
+			return "", fmt.Errorf("property %q of %s cannot be mapped to a Go field", key, name)
+		}
+		if !field.Required {
+			field.Tags["json"] = key + ",omitempty"
+		}
+
+		fieldType, err := c.goType(property, name+field.Name)
+		if err != nil {
+			return "", err
+		}
+		field.Type = fieldType
+
+		target := property
+		if property.Ref != "" {
+			if _, resolved, err := c.doc.ResolveSchema(property.Ref); err == nil {
+				target = resolved
+			}
+		}
+		applyConstraints(&field, target)
+
+		typeSpec.Fields = append(typeSpec.Fields, field)
+	}
+
+	if index >= 0 {
+		c.current[index] = *typeSpec
+	}
+	return name, nil
+}
+
+// collectProperties returns the properties of an object schema, merging the
+// members of allOf
+func (c *converter) collectProperties(schema *Schema) ([]string, map[string]*Schema, map[string]bool, error) {
+	var keys []string
+	properties := make(map[string]*Schema)
+	required := make(map[string]bool)
+
+	var collect func(s *Schema) error
+	collect = func(s *Schema) error {
+		if s.Ref != "" {
+			_, target, err := c.doc.ResolveSchema(s.Ref)
+			if err != nil {
+				return err
+			}
+			s = target
+		}
+		for _, part := range s.AllOf {
+			if err := collect(part); err != nil {
+				return err
+			}
+		}
+		for _, key := range s.Properties.Keys {
+			if _, ok := properties[key]; !ok {
+				keys = append(keys, key)
+			}
+			properties[key] = s.Properties.Schemas[key]
+		}
+		for _, key := range s.Required {
+			required[key] = true
+		}
+		return nil
+	}
+
+	if err := collect(schema); err != nil {
+		return nil, nil, nil, err
+	}
+	return keys, properties, required, nil
+}
+
+// applyConstraints copies the validation keywords of schema to field
+func applyConstraints(field *scaffolder.FieldSpec, schema *Schema) {
+	if schema == nil {
+		return
+	}
+
+	if field.Type == "string" {
+		for _, value := range schema.Enum {
+			field.Enum = append(field.Enum, fmt.Sprint(value))
+		}
+		field.MinLength = schema.MinLength
+		field.MaxLength = schema.MaxLength
+	}
+
+	integer := strings.HasPrefix(field.Type, "int")
+	if schema.Minimum != nil && (integer || strings.HasPrefix(field.Type, "float")) {
+		value := *schema.Minimum
+		if integer {
+			value = math.Ceil(value)
+		}
+		field.Minimum = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if schema.Maximum != nil && (integer || strings.HasPrefix(field.Type, "float")) {
+		value := *schema.Maximum
+		if integer {
+			value = math.Floor(value)
+		}
+		field.Maximum = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+// paramType returns the Go type of a path or query parameter
+func paramType(schema *Schema) string {
+	if schema == nil {
+		return "string"
+	}
+	switch schema.Type {
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]string"
+	}
+	return "string"
+}
+
+// isObject reports whether schema describes a struct
+func isObject(schema *Schema) bool {
+	return len(schema.Properties.Keys) > 0 || len(schema.AllOf) > 0
+}
+
+// commonBasePath returns the static path segments shared by all operations
+func commonBasePath(refs []operationRef) string {
+	var common []string
+	for i, ref := range refs {
+		var static []string
+		for _, segment := range strings.Split(strings.Trim(ref.path, "/"), "/") {
+			if segment == "" || strings.Contains(segment, "{") {
+				break
+			}
+			static = append(static, segment)
+		}
+
+		if i == 0 {
+			common = static
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(static) && common[n] == static[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	if len(common) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(common, "/")
+}
+
+// firstStaticSegment returns the first path segment that is not a parameter
+func firstStaticSegment(path string) string {
+	segment, _, _ := strings.Cut(strings.Trim(path, "/"), "/")
+	if strings.Contains(segment, "{") {
+		return ""
+	}
+	return segment
+}
+
+// handlerName derives a handler name such as "users" from a tag. Handler
+// names are lower case so that file, type and route names agree.
+func handlerName(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// goName converts an identifier from the document, such as "list_users" or
+// "userId", to an exported Go name
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(upper[:1] + strings.ToLower(word[1:]))
+	}
+
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "N" + name
+	}
+	return name
+}
+
+// splitWords splits s at non-alphanumeric characters and case changes
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := current[len(current)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(current))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// firstLine returns the first line of a description
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+// internal/parser/fields.go
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/shapestone/foundry/internal/scaffolder"
+)
+
+// fieldModifiers lists the modifiers accepted after a field type
+var fieldModifiers = map[string]bool{
+	"required": true,
+	"unique":   true,
+}
+
+// reservedFields are generated for every model and cannot be redefined
+var reservedFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// builtinTypes are the Go types that can be used in field definitions
+var builtinTypes = map[string]bool{
+	"string": true, "bool": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "any": true, "interface{}": true,
+}
+
+// ParseFieldSpecs parses field definitions such as "price:float64:required"
+// and rejects duplicates
+func ParseFieldSpecs(specs []string) ([]scaffolder.FieldSpec, error) {
+	fields := make([]scaffolder.FieldSpec, 0, len(specs))
+	seen := make(map[string]bool)
+
+	for _, spec := range specs {
+		field, err := ParseFieldSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", spec, err)
+		}
+
+		// Options such as ,unique are not part of the column name
+		column, _, _ := strings.Cut(field.Tags["db"], ",")
+		if seen[column] {
+			return nil, fmt.Errorf("field %s is defined more than once", field.Name)
+		}
+		seen[column] = true
+
+		fields = append(fields, *field)
+	}
+
+	return fields, nil
+}
+
+// ParseFieldSpec parses a single field definition in the format
+// "name:type[:modifier...]" where modifiers are required and unique
+func ParseFieldSpec(spec string) (*scaffolder.FieldSpec, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("field specification must be in format 'name:type'")
+	}
+
+	field := &scaffolder.FieldSpec{
+		Name: strings.TrimSpace(parts[0]),
+		Type: strings.TrimSpace(parts[1]),
+		Tags: make(map[string]string),
+	}
+
+	// Validate field name
+	if field.Name == "" {
+		return nil, fmt.Errorf("field name cannot be empty")
+	}
+
+	if !isValidGoIdentifier(field.Name) {
+		return nil, fmt.Errorf("field name must be a valid Go identifier")
+	}
+
+	column := toSnakeCase(field.Name)
+	if reservedFields[column] {
+		return nil, fmt.Errorf("field %s is already defined on every model", field.Name)
+	}
+
+	// Validate field type
+	if field.Type == "" {
+		return nil, fmt.Errorf("field type cannot be empty")
+	}
+
+	if err := validateFieldType(field.Type); err != nil {
+		return nil, err
+	}
+
+	// Parse modifiers
+	for _, modifier := range parts[2:] {
+		modifier = strings.ToLower(strings.TrimSpace(modifier))
+		if !fieldModifiers[modifier] {
+			return nil, fmt.Errorf("unknown field modifier %q (expected %s)", modifier, strings.Join(sortedKeys(fieldModifiers), " or "))
+		}
+		switch modifier {
+		case "required":
+			if field.Type == "bool" {
+				return nil, fmt.Errorf("bool fields cannot be required because false is a valid value")
+			}
+			field.Required = true
+		case "unique":
+			field.Unique = true
+		}
+	}
+
+	field.Tags["json"] = column
+	field.Tags["db"] = column
+	if field.Unique {
+		field.Tags["db"] = column + ",unique"
+	}
+	if field.Required {
+		field.Tags["validate"] = "required"
+	}
+
+	return field, nil
+}
+
+// validateFieldType checks that a field type only uses builtin types and time.Time
+func validateFieldType(fieldType string) error {
+	expr, err := goparser.ParseExpr(fieldType)
+	if err != nil {
+		return fmt.Errorf("invalid field type %q", fieldType)
+	}
+
+	var invalid string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if n == nil || invalid != "" {
+			return false
+		}
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			if pkg, ok := x.X.(*ast.Ident); !ok || pkg.Name != "time" || x.Sel.Name != "Time" {
+				invalid = fieldType
+			}
+			return false
+		case *ast.Ident:
+			if !builtinTypes[x.Name] {
+				invalid = x.Name
+			}
+		case *ast.InterfaceType:
+			return false
+		case *ast.ArrayType, *ast.MapType, *ast.StarExpr:
+		default:
+			invalid = fieldType
+		}
+		return true
+	})
+
+	if invalid != "" {
+		return fmt.Errorf("unsupported field type %q (use Go builtin types, time.Time, or slices, maps and pointers of them)", invalid)
+	}
+	return nil
+}
+
+// toSnakeCase converts a Go identifier such as "firstName" to "first_name"
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
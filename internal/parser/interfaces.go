@@ -53,13 +53,13 @@ type ValidationResult struct {
 
 // ValidationError represents a validation error with rich context
 type ValidationError struct {
-	Field       string                 `json:"field"`
-	Value       interface{}            `json:"value"`
-	Message     string                 `json:"message"`
-	Code        string                 `json:"code"`
-	Severity    ValidationSeverity     `json:"severity"`
-	Suggestions []string               `json:"suggestions"`
-	Context     map[string]interface{} `json:"context"`
+	Field       string                 `json:"field,omitempty" yaml:"field,omitempty"`
+	Value       interface{}            `json:"value,omitempty" yaml:"value,omitempty"`
+	Message     string                 `json:"message" yaml:"message"`
+	Code        string                 `json:"code" yaml:"code"`
+	Severity    ValidationSeverity     `json:"severity" yaml:"severity"`
+	Suggestions []string               `json:"suggestions,omitempty" yaml:"suggestions,omitempty"`
+	Context     map[string]interface{} `json:"context,omitempty" yaml:"context,omitempty"`
 }
 
 // ValidationWarning represents a validation warning
@@ -122,6 +122,9 @@ const (
 	// Type validation codes
 	CodeTypeUnsupported ValidationCode = "TYPE_UNSUPPORTED"
 	CodeTypeInvalid     ValidationCode = "TYPE_INVALID"
+
+	// Command failures that are not about the input
+	CodeCommandFailed ValidationCode = "COMMAND_FAILED"
 )
 
 // ProjectAnalyzer provides project structure analysis
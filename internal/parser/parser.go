@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/shapestone/foundry/internal/scaffolder"
 )
@@ -101,7 +100,7 @@ func (p *parser) ParseModelCommand(ctx context.Context, args []string, flags map
 					Flags:       flags,
 					Message:     fmt.Sprintf("invalid field specification: %s", err.Error()),
 					Code:        string(CodeArgsInvalid),
-					Suggestions: []string{"Use format: name:type", "Example: --fields name:string,age:int"},
+					Suggestions: []string{"Use format: name:type[:required][:unique]", "Example: --fields name:string:required,age:int"},
 				}
 			}
 			fields = append(fields, *field)
@@ -390,44 +389,7 @@ func (p *parser) createParseErrorFromValidation(result *ValidationResult) error
 	return parseError
 }
 
+// parseFieldSpec parses a field specification such as "name:type:required"
 func (p *parser) parseFieldSpec(fieldSpec string) (*scaffolder.FieldSpec, error) {
-	// Parse field specification in format "name:type" or "name:type:tag1,tag2"
-	parts := strings.Split(fieldSpec, ":")
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("field specification must be in format 'name:type'")
-	}
-
-	field := &scaffolder.FieldSpec{
-		Name: strings.TrimSpace(parts[0]),
-		Type: strings.TrimSpace(parts[1]),
-		Tags: make(map[string]string),
-	}
-
-	// Validate field name
-	if field.Name == "" {
-		return nil, fmt.Errorf("field name cannot be empty")
-	}
-
-	if !isValidGoIdentifier(field.Name) {
-		return nil, fmt.Errorf("field name must be a valid Go identifier")
-	}
-
-	// Validate field type
-	if field.Type == "" {
-		return nil, fmt.Errorf("field type cannot be empty")
-	}
-
-	// Parse tags if provided
-	if len(parts) > 2 {
-		tagsPart := strings.TrimSpace(parts[2])
-		if tagsPart != "" {
-			// Simple tag parsing - could be enhanced later
-			field.Tags["json"] = field.Name
-			if strings.Contains(tagsPart, "required") {
-				field.Required = true
-			}
-		}
-	}
-
-	return field, nil
+	return ParseFieldSpec(fieldSpec)
 }
@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,7 +25,7 @@ func NewEnhancedValidator(projectAnalyzer ProjectAnalyzer) EnhancedValidator {
 func (v *enhancedValidator) ValidateComponentName(name string) error {
 	result := v.validateComponentNameWithContext(name, nil)
 	if !result.Valid {
-		return fmt.Errorf(result.Errors[0].Message)
+		return errors.New(result.Errors[0].Message)
 	}
 	return nil
 }
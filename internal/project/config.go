@@ -0,0 +1,41 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings of foundry.yaml that later commands rely on
+type Config struct {
+	Layout string `yaml:"layout"`
+	Stack  Stack  `yaml:"stack"`
+}
+
+// Stack records the stack chosen when the project was created
+type Stack struct {
+	Router string `yaml:"router"`
+	Logger string `yaml:"logger"`
+}
+
+// LoadConfig reads foundry.yaml, or .foundry.yaml, from projectRoot
+func LoadConfig(projectRoot string) (*Config, error) {
+	for _, name := range []string{"foundry.yaml", ".foundry.yaml"} {
+		data, err := os.ReadFile(filepath.Join(projectRoot, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		var config Config
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		return &config, nil
+	}
+	return nil, fmt.Errorf("foundry.yaml not found in %s", projectRoot)
+}
@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/astedit"
+)
+
+// errNoConstructor is returned when a package has no constructor of the
+// requested name
+var errNoConstructor = errors.New("constructor not found")
+
+// constructorResolver builds calls to constructors in the project. A
+// constructor's parameters are filled in by calling the New<Type> function
+// of their type, so a handler that takes a service is wired as
+// handlers.NewUserHandler(services.NewUserService()). Variadic parameters,
+// usually functional options, are left out.
+type constructorResolver struct {
+	root     string
+	module   string
+	pending  map[string][]byte
+	file     *astedit.File
+	imports  []string
+	visiting map[string]bool
+}
+
+// call returns the expression calling the function name of the package at
+// importPath, adding the imports it needs to the routes file
+func (r *constructorResolver) call(importPath, name string) (string, error) {
+	key := importPath + "." + name
+	if r.visiting[key] {
+		return "", fmt.Errorf("%s depends on itself", key)
+	}
+	r.visiting[key] = true
+	defer delete(r.visiting, key)
+
+	fn, src, err := r.lookup(importPath, name)
+	if err != nil {
+		return "", err
+	}
+
+	var args []string
+	for _, field := range fn.Type.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			continue
+		}
+		arg, err := r.argument(src, importPath, field.Type)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", astedit.PackageName(importPath), name, err)
+		}
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			args = append(args, arg)
+		}
+	}
+
+	pkg, added := r.file.EnsureImport(importPath, "app"+astedit.PackageName(importPath))
+	if added {
+		r.imports = append(r.imports, importPath)
+	}
+	return fmt.Sprintf("%s.%s(%s)", pkg, name, strings.Join(args, ", ")), nil
+}
+
+// argument returns the expression that builds a parameter of type typ,
+// declared in src of the package at importPath
+func (r *constructorResolver) argument(src *ast.File, importPath string, typ ast.Expr) (string, error) {
+	typeName := types.ExprString(typ)
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	var name string
+	switch t := typ.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("cannot build a %s argument", typeName)
+		}
+		name, importPath = t.Sel.Name, importOf(src, pkg.Name)
+	default:
+		return "", fmt.Errorf("cannot build a %s argument", typeName)
+	}
+
+	if importPath != r.module && !strings.HasPrefix(importPath, r.module+"/") {
+		return "", fmt.Errorf("cannot build a %s argument: it is not declared in module %s", typeName, r.module)
+	}
+
+	call, err := r.call(importPath, "New"+name)
+	if errors.Is(err, errNoConstructor) {
+		return "", fmt.Errorf("cannot build a %s argument: no New%s function in %s", typeName, name, r.dir(importPath))
+	}
+	return call, err
+}
+
+// lookup finds the top-level function name in the package at importPath and
+// returns it with the file that declares it
+func (r *constructorResolver) lookup(importPath, name string) (*ast.FuncDecl, *ast.File, error) {
+	dir := filepath.Clean(r.dir(importPath))
+
+	sources := map[string][]byte{}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		sources[path] = src
+	}
+	for path, src := range r.pending {
+		if filepath.Dir(filepath.Clean(path)) == dir {
+			sources[filepath.Clean(path)] = src
+		}
+	}
+
+	fset := token.NewFileSet()
+	for path, src := range sources {
+		file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return fn, file, nil
+			}
+		}
+	}
+	return nil, nil, errNoConstructor
+}
+
+// FindFunc reports whether the package at importPath, of the module in the
+// project at root, declares the top-level function name. It returns the
+// directory of the package it looked in.
+func FindFunc(root, module, importPath, name string) (bool, string, error) {
+	r := &constructorResolver{root: root, module: module}
+	_, _, err := r.lookup(importPath, name)
+	if errors.Is(err, errNoConstructor) {
+		return false, r.dir(importPath), nil
+	}
+	return err == nil, r.dir(importPath), err
+}
+
+// dir returns the directory holding the package at importPath
+func (r *constructorResolver) dir(importPath string) string {
+	return filepath.Join(r.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, r.module), "/")))
+}
+
+// importOf returns the import path that file refers to as name
+func importOf(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && spec.Name.Name == name {
+			return importPath
+		}
+		if spec.Name == nil && astedit.PackageName(importPath) == name {
+			return importPath
+		}
+	}
+	return ""
+}
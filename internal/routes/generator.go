@@ -1,11 +1,19 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/shapestone/foundry/internal/astedit"
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/journal"
 )
 
 // Update represents a file modification
@@ -16,146 +24,437 @@ type Update struct {
 	Changes  []string
 }
 
+// Diff returns the update as a change to preview
+func (u *Update) Diff() diff.Change {
+	return diff.Change{
+		Path:   filepath.ToSlash(filepath.Clean(u.Path)),
+		Before: u.Original,
+		After:  u.Modified,
+	}
+}
+
 // Generator handles route file updates for auto-wiring
 type Generator interface {
 	UpdateRoutes(handlerName string, moduleName string) (*Update, error)
 	ValidateGoFile(path string) error
 }
 
-// FileGenerator implements Generator for file system operations
-type FileGenerator struct{}
+// FileGenerator implements Generator for file system operations. Route
+// registrations are inserted by editing the syntax tree of routes.go, so the
+// target file can be formatted however the user likes.
+type FileGenerator struct {
+	routesPath  string
+	routeGroup  string
+	projectRoot string
+	pending     map[string][]byte
+	force       bool
+}
+
+// Option configures a FileGenerator
+type Option func(*FileGenerator)
+
+// WithRoutesFile sets the routes file to update
+func WithRoutesFile(path string) Option {
+	return func(g *FileGenerator) {
+		if path != "" {
+			g.routesPath = path
+		}
+	}
+}
+
+// WithRouteGroup sets the route group that handlers are mounted under
+func WithRouteGroup(prefix string) Option {
+	return func(g *FileGenerator) {
+		if prefix != "" {
+			g.routeGroup = prefix
+		}
+	}
+}
+
+// WithProjectRoot sets the project directory whose packages hold the
+// handler constructors
+func WithProjectRoot(root string) Option {
+	return func(g *FileGenerator) {
+		if root != "" {
+			g.projectRoot = root
+		}
+	}
+}
+
+// WithPendingFile makes a file that has not been written yet visible when
+// looking up constructors, as needed to preview the wiring of a new handler
+func WithPendingFile(path string, src []byte) Option {
+	return func(g *FileGenerator) {
+		if g.pending == nil {
+			g.pending = map[string][]byte{}
+		}
+		g.pending[path] = src
+	}
+}
+
+// WithForce replaces existing registrations instead of failing
+func WithForce(force bool) Option {
+	return func(g *FileGenerator) {
+		g.force = force
+	}
+}
 
 // NewFileGenerator creates a new file generator
-func NewFileGenerator() *FileGenerator {
-	return &FileGenerator{}
+func NewFileGenerator(opts ...Option) *FileGenerator {
+	g := &FileGenerator{
+		routesPath:  filepath.Join("internal", "routes", "routes.go"),
+		routeGroup:  "/api/v1",
+		projectRoot: ".",
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// RoutesPath returns the routes file the generator edits
+func (g *FileGenerator) RoutesPath() string {
+	return g.routesPath
 }
 
 // UpdateRoutes calculates the changes needed to add a handler to routes.go
 func (g *FileGenerator) UpdateRoutes(handlerName string, moduleName string) (*Update, error) {
-	routesPath := filepath.Join("internal", "routes", "routes.go")
+	return g.MountHandler(handlerName, "/"+strings.ToLower(handlerName)+"s", moduleName)
+}
+
+// MountHandler calculates the changes needed to mount a handler at routePath
+func (g *FileGenerator) MountHandler(handlerName, routePath, moduleName string) (*Update, error) {
+	if handlerName == "" {
+		return nil, fmt.Errorf("handler name is required")
+	}
 
-	// Read current file
-	original, err := os.ReadFile(routesPath)
+	file, err := astedit.ParseFile(g.routesPath)
 	if err != nil {
-		return nil, fmt.Errorf("reading routes.go: %w", err)
+		return nil, err
+	}
+
+	block, routerVar, err := g.findRouteBlock(file.AST)
+	if err != nil {
+		return nil, err
 	}
 
-	modified := string(original)
 	changes := []string{}
 
-	// Add import if needed
-	importLine := fmt.Sprintf(`"%s/internal/handlers"`, moduleName)
-	if !strings.Contains(modified, importLine) {
-		// Find import block and add handlers import
-		if strings.Contains(modified, "import (") {
-			modified = strings.Replace(
-				modified,
-				"import (",
-				fmt.Sprintf("import (\n\t%s", importLine),
-				1,
-			)
-		} else {
-			// Add import block if it doesn't exist
-			packageLine := "package routes"
-			modified = strings.Replace(
-				modified,
-				packageLine,
-				fmt.Sprintf("%s\n\nimport (\n\t%s\n)", packageLine, importLine),
-				1,
-			)
-		}
-		changes = append(changes, fmt.Sprintf("Add import: %s", importLine))
-	}
-
-	// Create handler registration code
-	handlerVar := strings.ToLower(handlerName) + "Handler"
-	handlerType := strings.Title(handlerName) + "Handler"
-	routePath := "/" + strings.ToLower(handlerName) + "s"
-
-	handlerCode := fmt.Sprintf(
-		"\n\t\t// %s routes\n\t\t%s := handlers.New%s()\n\t\tr.Mount(\"%s\", %s.Routes())",
-		strings.Title(handlerName),
-		handlerVar,
-		handlerType,
-		routePath,
-		handlerVar,
-	)
-
-	// Find the API v1 route block and insert handler
-	lines := strings.Split(modified, "\n")
-	inserted := false
-
-	for i, line := range lines {
-		// Look for the API v1 route block
-		if strings.Contains(line, `r.Route("/api/v1"`) {
-			// Find the comment "// Handler routes will be auto-generated here"
-			for j := i; j < len(lines); j++ {
-				if strings.Contains(lines[j], "// Handler routes will be auto-generated here") {
-					// Insert after the comment
-					lines[j] = lines[j] + handlerCode
-					changes = append(changes, fmt.Sprintf("Add %s handler registration", handlerName))
-					inserted = true
-					break
-				}
+	typeName := ExportedName(handlerName) + "Handler"
+	handlerVar := UnexportedName(handlerName) + "Handler"
+	constructor := "New" + typeName
+
+	// Build the constructor call from its real signature, so a handler that
+	// does not exist or cannot be constructed is never wired
+	importPath := moduleName + "/internal/handlers"
+	resolver := &constructorResolver{
+		root:     g.projectRoot,
+		module:   moduleName,
+		pending:  g.pending,
+		file:     file,
+		visiting: map[string]bool{},
+	}
+	call, err := resolver.call(importPath, constructor)
+	if errors.Is(err, errNoConstructor) {
+		return nil, fmt.Errorf("handler %s not found: no %s function in %s", handlerName, constructor, resolver.dir(importPath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot wire handler %s: %w", handlerName, err)
+	}
+	for _, added := range resolver.imports {
+		changes = append(changes, fmt.Sprintf("Add import: %q", added))
+	}
+	pkg := file.ImportName(importPath)
+
+	if existing := findStmts(block, handlerVar, pkg, constructor); len(existing) > 0 {
+		if !g.force {
+			return nil, fmt.Errorf("handler %s is already wired in %s (use --force to replace it)", handlerName, g.routesPath)
+		}
+		block.List = removeStmts(block.List, existing)
+		changes = append(changes, fmt.Sprintf("Remove existing %s handler registration", handlerName))
+	}
+
+	mount := fileRouter(file.AST).Mount(routerVar, routePath, handlerVar)
+	stmts, err := astedit.ParseStmts(fmt.Sprintf(
+		"%s := %s\n%s",
+		handlerVar, call, mount,
+	), block.Rbrace)
+	if err != nil {
+		return nil, err
+	}
+	block.List = append(block.List, stmts...)
+	changes = append(changes, fmt.Sprintf("Add %s handler registration: %s", handlerName, mount))
+
+	modified, err := file.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Update{
+		Path:     g.routesPath,
+		Original: file.Original,
+		Modified: modified,
+		Changes:  changes,
+	}, nil
+}
+
+// RemoveRoutes calculates the changes needed to remove a handler from routes.go
+func (g *FileGenerator) RemoveRoutes(handlerName string) (*Update, error) {
+	file, err := astedit.ParseFile(g.routesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _, err := g.findRouteBlock(file.AST)
+	if err != nil {
+		return nil, err
+	}
+
+	typeName := ExportedName(handlerName) + "Handler"
+	handlerVar := UnexportedName(handlerName) + "Handler"
+
+	pkg := "handlers"
+	for _, spec := range file.AST.Imports {
+		if strings.HasSuffix(spec.Path.Value, `/internal/handlers"`) && spec.Name != nil {
+			pkg = spec.Name.Name
+		}
+	}
+
+	existing := findStmts(block, handlerVar, pkg, "New"+typeName)
+	if len(existing) == 0 {
+		return nil, fmt.Errorf("handler %s is not wired in %s", handlerName, g.routesPath)
+	}
+	block.List = removeStmts(block.List, existing)
+
+	modified, err := file.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Update{
+		Path:     g.routesPath,
+		Original: file.Original,
+		Modified: modified,
+		Changes:  []string{fmt.Sprintf("Remove %s handler registration", handlerName)},
+	}, nil
+}
+
+// findRouteBlock locates the block that handler registrations belong in. It
+// prefers the function literal passed to r.Route(<group>, ...) or a variable
+// holding the route group, and falls back to a top-level route registration
+// function.
+func (g *FileGenerator) findRouteBlock(file *ast.File) (*ast.BlockStmt, string, error) {
+	var block *ast.BlockStmt
+	var routerVar string
+
+	group := strconv.Quote(g.routeGroup)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if block != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Route" {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || lit.Value != group {
+			return true
+		}
+		fn, ok := call.Args[1].(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		if name := firstParamName(fn.Type); name != "" {
+			block, routerVar = fn.Body, name
+		}
+		return block == nil
+	})
+	if block != nil {
+		return block, routerVar, nil
+	}
+
+	if block, routerVar = g.findGroupVar(file); block != nil {
+		return block, routerVar, nil
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Recv != nil {
+			continue
+		}
+		switch fn.Name.Name {
+		case "RegisterAPIRoutes", "RegisterRoutes", "SetupRoutes":
+			if name := firstParamName(fn.Type); name != "" {
+				return fn.Body, name, nil
 			}
-			break
-		}
-	}
-
-	if !inserted {
-		// Fallback: try to find the closing of the API v1 route block
-		for i, line := range lines {
-			if strings.Contains(line, `r.Route("/api/v1"`) {
-				braceCount := 0
-				for j := i; j < len(lines); j++ {
-					for _, char := range lines[j] {
-						if char == '{' {
-							braceCount++
-						} else if char == '}' {
-							braceCount--
-							if braceCount == 0 {
-								// Insert before the closing brace
-								lines[j] = handlerCode + "\n" + lines[j]
-								changes = append(changes, fmt.Sprintf("Add %s handler registration", handlerName))
-								inserted = true
-								break
-							}
-						}
+		}
+	}
+
+	return nil, "", fmt.Errorf("could not find a %s route group or RegisterAPIRoutes function in %s", g.routeGroup, g.routesPath)
+}
+
+// findGroupVar locates a variable holding the route group, as created by
+// api := r.Group("/api/v1") in gin and echo, or
+// api := r.PathPrefix("/api/v1").Subrouter() in gorilla/mux. With net/http
+// the group is the mux passed to http.StripPrefix("/api/v1", api).
+func (g *FileGenerator) findGroupVar(file *ast.File) (*ast.BlockStmt, string) {
+	var block *ast.BlockStmt
+	var routerVar string
+
+	group := strconv.Quote(g.routeGroup)
+	isGroup := func(expr ast.Expr) bool {
+		lit, ok := expr.(*ast.BasicLit)
+		return ok && lit.Kind == token.STRING && lit.Value == group
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if block != nil {
+			return false
+		}
+		b, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+		for _, stmt := range b.List {
+			if assign, ok := stmt.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 && len(assign.Rhs) == 1 {
+				ident, ok := assign.Lhs[0].(*ast.Ident)
+				call, isCall := assign.Rhs[0].(*ast.CallExpr)
+				if !ok || !isCall {
+					continue
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					continue
+				}
+				switch sel.Sel.Name {
+				case "Group":
+					if len(call.Args) > 0 && isGroup(call.Args[0]) {
+						block, routerVar = b, ident.Name
 					}
-					if inserted {
-						break
+				case "Subrouter":
+					if prefix, ok := sel.X.(*ast.CallExpr); ok && len(prefix.Args) == 1 && isGroup(prefix.Args[0]) {
+						if prefixSel, ok := prefix.Fun.(*ast.SelectorExpr); ok && prefixSel.Sel.Name == "PathPrefix" {
+							block, routerVar = b, ident.Name
+						}
 					}
 				}
+				if block != nil {
+					return false
+				}
+				continue
+			}
+
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) != 2 || !astedit.IsSelector(call.Fun, "http", "StripPrefix") || !isGroup(call.Args[0]) {
+					return block == nil
+				}
+				if ident, ok := call.Args[1].(*ast.Ident); ok {
+					block, routerVar = b, ident.Name
+				}
+				return false
+			})
+			if block != nil {
+				return false
+			}
+		}
+		return true
+	})
+
+	return block, routerVar
+}
+
+// fileRouter returns the router imported by a routes file
+func fileRouter(file *ast.File) Router {
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if router, ok := RouterImports[importPath]; ok {
+			return router
+		}
+	}
+	return RouterHTTP
+}
+
+// findStmts returns the statements in block that register the given handler
+func findStmts(block *ast.BlockStmt, handlerVar, pkg, constructor string) []ast.Stmt {
+	var found []ast.Stmt
+	for _, stmt := range block.List {
+		match := astedit.References(stmt, handlerVar)
+		if !match {
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok && astedit.IsSelector(call.Fun, pkg, constructor) {
+					match = true
+				}
+				return !match
+			})
+		}
+		if match {
+			found = append(found, stmt)
+		}
+	}
+	return found
+}
+
+// removeStmts returns list without the statements in remove
+func removeStmts(list, remove []ast.Stmt) []ast.Stmt {
+	out := list[:0]
+	for _, stmt := range list {
+		keep := true
+		for _, r := range remove {
+			if stmt == r {
+				keep = false
 				break
 			}
 		}
+		if keep {
+			out = append(out, stmt)
+		}
 	}
+	return out
+}
 
-	if !inserted {
-		return nil, fmt.Errorf("could not find appropriate location to insert handler routes")
+// firstParamName returns the name of the first parameter of a function type
+func firstParamName(fn *ast.FuncType) string {
+	if fn.Params == nil || len(fn.Params.List) == 0 || len(fn.Params.List[0].Names) == 0 {
+		return ""
 	}
+	return fn.Params.List[0].Names[0].Name
+}
 
-	modified = strings.Join(lines, "\n")
+// ExportedName converts a handler name such as "user" to "User"
+func ExportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
 
-	return &Update{
-		Path:     routesPath,
-		Original: original,
-		Modified: []byte(modified),
-		Changes:  changes,
-	}, nil
+// UnexportedName converts a handler name such as "User" to "user"
+func UnexportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
 }
 
-// ValidateGoFile validates Go syntax using gofmt
+// ValidateGoFile validates Go syntax by parsing the file
 func (g *FileGenerator) ValidateGoFile(path string) error {
-	cmd := exec.Command("gofmt", "-e", path)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("invalid Go syntax: %s", output)
+	if _, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors); err != nil {
+		return fmt.Errorf("invalid Go syntax: %w", err)
 	}
 	return nil
 }
 
 // ApplyUpdate applies a file update with rollback support
 func ApplyUpdate(update *Update, validator Generator) error {
+	if err := journal.Touch(update.Path); err != nil {
+		return err
+	}
+
 	// Create backup
 	backupPath := update.Path + ".backup"
 	if err := os.WriteFile(backupPath, update.Original, 0644); err != nil {
@@ -203,32 +502,12 @@ func UpdateHandlerRoutes(handlerName, moduleName string) error {
 
 // RemoveHandlerRoutes removes a handler from routes (for cleanup/undo operations)
 func RemoveHandlerRoutes(handlerName string) error {
-	routesPath := filepath.Join("internal", "routes", "routes.go")
+	generator := NewFileGenerator()
 
-	// Read current file
-	content, err := os.ReadFile(routesPath)
+	update, err := generator.RemoveRoutes(handlerName)
 	if err != nil {
-		return fmt.Errorf("reading routes.go: %w", err)
+		return fmt.Errorf("calculating route removal: %w", err)
 	}
 
-	modified := string(content)
-
-	// Remove handler registration lines
-	lines := strings.Split(modified, "\n")
-	var filteredLines []string
-
-	handlerVar := strings.ToLower(handlerName) + "Handler"
-
-	for _, line := range lines {
-		// Skip lines related to this handler
-		if strings.Contains(line, handlerVar) ||
-			strings.Contains(line, fmt.Sprintf("// %s routes", strings.Title(handlerName))) {
-			continue
-		}
-		filteredLines = append(filteredLines, line)
-	}
-
-	modified = strings.Join(filteredLines, "\n")
-
-	return os.WriteFile(routesPath, []byte(modified), 0644)
+	return ApplyUpdate(update, generator)
 }
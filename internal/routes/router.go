@@ -0,0 +1,180 @@
+package routes
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/project"
+)
+
+// Router identifies the HTTP router a project is built on
+type Router string
+
+const (
+	RouterChi     Router = "chi"
+	RouterGin     Router = "gin"
+	RouterEcho    Router = "echo"
+	RouterGorilla Router = "gorilla"
+	RouterHTTP    Router = "http" // net/http ServeMux with Go 1.22 method patterns
+)
+
+// RouterImports maps router import paths to the router they provide
+var RouterImports = map[string]Router{
+	"github.com/go-chi/chi/v5":    RouterChi,
+	"github.com/go-chi/chi":       RouterChi,
+	"github.com/gin-gonic/gin":    RouterGin,
+	"github.com/labstack/echo/v4": RouterEcho,
+	"github.com/gorilla/mux":      RouterGorilla,
+}
+
+// routerPattern matches {name} path parameters
+var routerPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ParseRouter returns the router with the given name, as chosen with
+// foundry new --router. Both "stdlib" and "http" name net/http.
+func ParseRouter(name string) (Router, bool) {
+	switch name {
+	case "stdlib", "http":
+		return RouterHTTP, true
+	case string(RouterChi), string(RouterGin), string(RouterEcho), string(RouterGorilla):
+		return Router(name), true
+	}
+	return "", false
+}
+
+// DetectRouter returns the router used by the project at projectRoot. The
+// stack recorded in foundry.yaml is checked first, then the imports of the
+// routes file and main.go and finally the requirements in go.mod. Projects
+// without any of the supported routers use net/http.
+func DetectRouter(projectRoot string) Router {
+	if config, err := project.LoadConfig(projectRoot); err == nil {
+		if router, ok := ParseRouter(config.Stack.Router); ok {
+			return router
+		}
+	}
+
+	candidates := []string{
+		filepath.Join(projectRoot, "internal", "routes", "routes.go"),
+		filepath.Join(projectRoot, "main.go"),
+	}
+	if mains, err := filepath.Glob(filepath.Join(projectRoot, "cmd", "*", "main.go")); err == nil {
+		candidates = append(candidates, mains...)
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range candidates {
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			if router, ok := RouterImports[importPath]; ok {
+				return router
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require"))
+			if len(fields) < 2 {
+				continue
+			}
+			if router, ok := RouterImports[fields[0]]; ok {
+				return router
+			}
+		}
+	}
+
+	return RouterHTTP
+}
+
+// ImportPath returns the import path of the router package, or an empty
+// string for net/http
+func (r Router) ImportPath() string {
+	switch r {
+	case RouterChi:
+		return "github.com/go-chi/chi/v5"
+	case RouterGin:
+		return "github.com/gin-gonic/gin"
+	case RouterEcho:
+		return "github.com/labstack/echo/v4"
+	case RouterGorilla:
+		return "github.com/gorilla/mux"
+	}
+	return ""
+}
+
+// Pattern converts a route path with {name} parameters to the router's syntax
+func (r Router) Pattern(path string) string {
+	switch r {
+	case RouterGin, RouterEcho:
+		return routerPattern.ReplaceAllString(path, ":$1")
+	}
+	return path
+}
+
+// PathValue returns the expression that reads path parameter key from the
+// request r inside a net/http handler. Handlers generated for gin and echo
+// are adapted so the parameters are available through r.PathValue.
+func (r Router) PathValue(key string) string {
+	switch r {
+	case RouterChi:
+		return fmt.Sprintf("chi.URLParam(r, %q)", key)
+	case RouterGorilla:
+		return fmt.Sprintf("mux.Vars(r)[%q]", key)
+	}
+	return fmt.Sprintf("r.PathValue(%q)", key)
+}
+
+// Route returns the statement that registers handler for method and path,
+// relative to the handler's mount point. It is written for the routing
+// function of a generated handler: Routes for chi, RegisterRoutes otherwise.
+func (r Router) Route(method, path, handler string) string {
+	method = strings.ToUpper(method)
+	// Route groups in gin, echo and gorilla match the group path itself
+	// with an empty route, "/" would require a trailing slash
+	if path == "/" && r != RouterChi {
+		path = ""
+	}
+
+	switch r {
+	case RouterChi:
+		verb := strings.ToLower(method)
+		return fmt.Sprintf("r.%s(%q, %s)", strings.ToUpper(verb[:1])+verb[1:], path, handler)
+	case RouterGin, RouterEcho:
+		return fmt.Sprintf("r.%s(%q, h.handle(%s))", method, r.Pattern(path), handler)
+	case RouterGorilla:
+		return fmt.Sprintf("r.HandleFunc(%q, %s).Methods(http.Method%s)", path, handler, methodConstant(method))
+	}
+	if path == "" {
+		return fmt.Sprintf("mux.HandleFunc(%q+prefix, %s)", method+" ", handler)
+	}
+	return fmt.Sprintf("mux.HandleFunc(%q+prefix+%q, %s)", method+" ", path, handler)
+}
+
+// Mount returns the statement that mounts a handler at path on routerVar
+func (r Router) Mount(routerVar, path, handlerVar string) string {
+	switch r {
+	case RouterChi:
+		return fmt.Sprintf("%s.Mount(%q, %s.Routes())", routerVar, path, handlerVar)
+	case RouterGin, RouterEcho:
+		return fmt.Sprintf("%s.RegisterRoutes(%s.Group(%q))", handlerVar, routerVar, path)
+	case RouterGorilla:
+		return fmt.Sprintf("%s.RegisterRoutes(%s.PathPrefix(%q).Subrouter())", handlerVar, routerVar, path)
+	}
+	return fmt.Sprintf("%s.RegisterRoutes(%s, %q)", handlerVar, routerVar, path)
+}
+
+// methodConstant returns the suffix of the net/http constant for method
+func methodConstant(method string) string {
+	lower := strings.ToLower(method)
+	return strings.ToUpper(lower[:1]) + lower[1:]
+}
@@ -2,12 +2,20 @@
 package scaffolder
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
 
-	"github.com/shapestone/foundry/internal/generator"
+	"github.com/shapestone/foundry"
+	"github.com/shapestone/foundry/internal/diff"
 	"github.com/shapestone/foundry/internal/interactive"
+	"github.com/shapestone/foundry/internal/journal"
 	"github.com/shapestone/foundry/internal/project"
 )
 
@@ -30,14 +38,23 @@ func (f *FileSystemAdapter) ReadFile(path string) ([]byte, error) {
 }
 
 func (f *FileSystemAdapter) WriteFile(path string, data []byte, perm uint32) error {
+	if err := journal.Touch(path); err != nil {
+		return err
+	}
 	return os.WriteFile(path, data, os.FileMode(perm))
 }
 
 func (f *FileSystemAdapter) MkdirAll(path string, perm uint32) error {
+	if err := journal.TouchDir(path); err != nil {
+		return err
+	}
 	return os.MkdirAll(path, os.FileMode(perm))
 }
 
 func (f *FileSystemAdapter) Remove(path string) error {
+	if err := journal.Touch(path); err != nil {
+		return err
+	}
 	return os.Remove(path)
 }
 
@@ -59,32 +76,49 @@ func (f *FileInfoAdapter) Size() int64  { return f.info.Size() }
 func (f *FileInfoAdapter) Mode() uint32 { return uint32(f.info.Mode()) }
 func (f *FileInfoAdapter) IsDir() bool  { return f.info.IsDir() }
 
-// TemplateRendererAdapter adapts the existing generator to our TemplateRenderer interface
+// TemplateRendererAdapter renders the templates embedded in the foundry binary
 type TemplateRendererAdapter struct {
-	generator *generator.FileGenerator
+	funcMap template.FuncMap
 }
 
 func NewTemplateRendererAdapter() *TemplateRendererAdapter {
 	return &TemplateRendererAdapter{
-		generator: generator.NewFileGenerator(),
+		funcMap: template.FuncMap{
+			"lower":     strings.ToLower,
+			"upper":     strings.ToUpper,
+			"hasPrefix": strings.HasPrefix,
+			"join":      strings.Join,
+			"quote":     strconv.Quote,
+		},
 	}
 }
 
 func (t *TemplateRendererAdapter) LoadTemplate(name string) (Template, error) {
-	// For now, we'll use the embedded templates from the foundry package
-	// This will be improved when we move templates to external files
-	return &TemplateAdapter{name: name}, nil
+	content, err := foundry.Templates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return nil, fmt.Errorf("template %s not found: %w", name, err)
+	}
+
+	parsed, err := template.New(name).Funcs(t.funcMap).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	return &TemplateAdapter{name: name, template: parsed}, nil
 }
 
 func (t *TemplateRendererAdapter) RenderTemplate(tmpl Template, data interface{}) (string, error) {
-	// This is a simplified implementation
-	// In practice, we'll need to integrate with your existing template system
-	return "// Generated handler placeholder\n", nil
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
-// TemplateAdapter represents a template
+// TemplateAdapter represents a parsed template
 type TemplateAdapter struct {
-	name string
+	name     string
+	template *template.Template
 }
 
 func (t *TemplateAdapter) Name() string {
@@ -92,9 +126,7 @@ func (t *TemplateAdapter) Name() string {
 }
 
 func (t *TemplateAdapter) Execute(wr io.Writer, data interface{}) error {
-	// Simplified implementation for now
-	_, err := wr.Write([]byte("// Generated handler placeholder\n"))
-	return err
+	return t.template.Execute(wr, data)
 }
 
 // ProjectAnalyzerAdapter adapts the existing project package
@@ -170,6 +202,10 @@ func (u *UserInteractionAdapter) ShowPreview(title string, changes []string, mes
 	return u.prompter.ShowPreview(title, changes, message)
 }
 
+func (u *UserInteractionAdapter) ShowChanges(title string, changes []diff.Change, message string) bool {
+	return u.prompter.ShowChanges(title, changes, message)
+}
+
 // Factory function to create a scaffolder with real dependencies
 func NewScaffolderWithAdapters() Scaffolder {
 	return New(
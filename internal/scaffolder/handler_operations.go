@@ -0,0 +1,338 @@
+// internal/scaffolder/handler_operations.go
+package scaffolder
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/routes"
+)
+
+// statusConstants maps common HTTP status codes to their net/http names
+var statusConstants = map[int]string{
+	http.StatusOK:                  "http.StatusOK",
+	http.StatusCreated:             "http.StatusCreated",
+	http.StatusAccepted:            "http.StatusAccepted",
+	http.StatusNoContent:           "http.StatusNoContent",
+	http.StatusMovedPermanently:    "http.StatusMovedPermanently",
+	http.StatusFound:               "http.StatusFound",
+	http.StatusNotModified:         "http.StatusNotModified",
+	http.StatusBadRequest:          "http.StatusBadRequest",
+	http.StatusUnauthorized:        "http.StatusUnauthorized",
+	http.StatusForbidden:           "http.StatusForbidden",
+	http.StatusNotFound:            "http.StatusNotFound",
+	http.StatusConflict:            "http.StatusConflict",
+	http.StatusInternalServerError: "http.StatusInternalServerError",
+}
+
+// fieldCheck is a validation rule rendered as `if Cond { return errors.New(Message) }`
+type fieldCheck struct {
+	Cond    string
+	Message string
+}
+
+// HasValidation reports whether the generated struct needs a Validate method
+func (t TypeSpec) HasValidation() bool {
+	for _, field := range t.Fields {
+		if len(fieldChecks(field, "v."+field.Name, jsonName(field), false)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// handlerType wraps a TypeSpec with the helpers handler templates need
+type handlerType struct {
+	TypeSpec
+	Fields []handlerField
+}
+
+// handlerField wraps a FieldSpec with the helpers handler templates need
+type handlerField struct {
+	FieldSpec
+}
+
+// Tag renders the struct tag of the field
+func (f handlerField) Tag() string {
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s:%q", key, f.Tags[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Checks returns the validation rules of the field for receiver recv
+func (f handlerField) Checks(recv string) []fieldCheck {
+	return fieldChecks(f.FieldSpec, recv+"."+f.Name, jsonName(f.FieldSpec), false)
+}
+
+// handlerParam wraps a ParamSpec with the helpers handler templates need
+type handlerParam struct {
+	ParamSpec
+	In string
+	// Source reads the raw path parameter from the request
+	Source string
+}
+
+// Checks returns the validation rules applied once the parameter is read
+func (p handlerParam) Checks(recv string) []fieldCheck {
+	return fieldChecks(p.FieldSpec, recv+"."+p.Name, p.In+" parameter "+p.Key, true)
+}
+
+// ParseFunc returns the strconv call that parses the raw parameter value
+func (p handlerParam) ParseFunc() string {
+	switch p.Type {
+	case "bool":
+		return "strconv.ParseBool(raw)"
+	case "int", "int64":
+		return "strconv.ParseInt(raw, 10, 64)"
+	case "int32":
+		return "strconv.ParseInt(raw, 10, 32)"
+	case "float32":
+		return "strconv.ParseFloat(raw, 32)"
+	case "float64":
+		return "strconv.ParseFloat(raw, 64)"
+	}
+	return ""
+}
+
+// Convert returns the expression that converts the parsed value to the field type
+func (p handlerParam) Convert() string {
+	switch p.Type {
+	case "bool", "int64", "float64":
+		return "v"
+	}
+	return p.Type + "(v)"
+}
+
+// Kind describes the expected format in parse errors
+func (p handlerParam) Kind() string {
+	switch p.Type {
+	case "bool":
+		return "a boolean"
+	case "float32", "float64":
+		return "a number"
+	}
+	return "an integer"
+}
+
+// handlerOperation wraps an OperationSpec with the helpers handler templates need
+type handlerOperation struct {
+	OperationSpec
+	PathParams  []handlerParam
+	QueryParams []handlerParam
+	// Route registers the operation with the handler's router
+	Route string
+}
+
+// StatusCode returns the Go expression for the success status
+func (o handlerOperation) StatusCode() string {
+	if name, ok := statusConstants[o.Status]; ok {
+		return name
+	}
+	return strconv.Itoa(o.Status)
+}
+
+// ResponseInit declares the response value returned by the generated stub
+func (o handlerOperation) ResponseInit() string {
+	if strings.HasPrefix(o.Response, "[]") || strings.HasPrefix(o.Response, "map[") {
+		return fmt.Sprintf("response := %s{}", o.Response)
+	}
+	return "var response " + o.Response
+}
+
+// Fields returns the parameters as fields of the parameters struct
+func (o handlerOperation) Fields() []handlerField {
+	var fields []handlerField
+	for _, param := range append(append([]handlerParam{}, o.PathParams...), o.QueryParams...) {
+		fields = append(fields, handlerField{param.FieldSpec})
+	}
+	return fields
+}
+
+// newHandlerOperations prepares operations for rendering with router
+func newHandlerOperations(specs []OperationSpec, router routes.Router) []handlerOperation {
+	operations := make([]handlerOperation, 0, len(specs))
+	for _, spec := range specs {
+		operation := handlerOperation{
+			OperationSpec: spec,
+			Route:         router.Route(spec.Method, spec.Path, "h."+spec.Name),
+		}
+		for _, param := range spec.PathParams {
+			operation.PathParams = append(operation.PathParams, handlerParam{
+				ParamSpec: param,
+				In:        "path",
+				Source:    router.PathValue(param.Key),
+			})
+		}
+		for _, param := range spec.QueryParams {
+			operation.QueryParams = append(operation.QueryParams, handlerParam{ParamSpec: param, In: "query"})
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// newHandlerTypes prepares request and response structs for rendering
+func newHandlerTypes(specs []TypeSpec) []handlerType {
+	types := make([]handlerType, 0, len(specs))
+	for _, spec := range specs {
+		t := handlerType{TypeSpec: spec}
+		for _, field := range spec.Fields {
+			t.Fields = append(t.Fields, handlerField{field})
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+// handlerImports returns the imports needed by an operation-based handler
+func handlerImports(operations []handlerOperation, types []handlerType, router routes.Router) []string {
+	needs := map[string]bool{"encoding/json": true, "net/http": true}
+
+	for _, t := range types {
+		if t.HasValidation() {
+			needs["errors"] = true
+		}
+		for _, field := range t.Fields {
+			if strings.Contains(field.Type, "time.") {
+				needs["time"] = true
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		for _, param := range append(append([]handlerParam{}, operation.PathParams...), operation.QueryParams...) {
+			if param.Required || len(param.Checks("p")) > 0 {
+				needs["errors"] = true
+			}
+			if param.ParseFunc() != "" {
+				needs["errors"] = true
+				needs["strconv"] = true
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(needs))
+	for path := range needs {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	// Third-party imports go in their own group
+	if importPath := router.ImportPath(); importPath != "" {
+		imports = append(imports, "", importPath)
+	}
+	return imports
+}
+
+// fieldChecks returns the validation rules for the field value expr. name is
+// used in error messages. Unless present is set, optional fields are only
+// checked when they hold a non-zero value.
+func fieldChecks(f FieldSpec, expr, name string, present bool) []fieldCheck {
+	var checks []fieldCheck
+
+	// Optional values are only checked when they are set
+	guard := func(cond string) string {
+		if present || f.Required {
+			return cond
+		}
+		if f.Type == "string" {
+			return fmt.Sprintf(`%s != "" && %s`, expr, cond)
+		}
+		return fmt.Sprintf("%s != 0 && %s", expr, cond)
+	}
+
+	if empty := emptyCheck(f.Type, expr); f.Required && !present && empty != "" {
+		checks = append(checks, fieldCheck{Cond: empty, Message: name + " is required"})
+	}
+
+	if len(f.Enum) > 0 && f.Type == "string" {
+		conds := make([]string, 0, len(f.Enum))
+		for _, value := range f.Enum {
+			conds = append(conds, fmt.Sprintf("%s != %q", expr, value))
+		}
+		checks = append(checks, fieldCheck{
+			Cond:    guard(strings.Join(conds, " && ")),
+			Message: fmt.Sprintf("%s must be one of %s", name, strings.Join(f.Enum, ", ")),
+		})
+	}
+
+	if f.Type == "string" {
+		if f.MinLength != nil && *f.MinLength > 0 {
+			checks = append(checks, fieldCheck{
+				Cond:    guard(fmt.Sprintf("len([]rune(%s)) < %d", expr, *f.MinLength)),
+				Message: fmt.Sprintf("%s length must be at least %d", name, *f.MinLength),
+			})
+		}
+		if f.MaxLength != nil {
+			checks = append(checks, fieldCheck{
+				Cond:    fmt.Sprintf("len([]rune(%s)) > %d", expr, *f.MaxLength),
+				Message: fmt.Sprintf("%s length must be at most %d", name, *f.MaxLength),
+			})
+		}
+	}
+
+	if isNumeric(f.Type) {
+		// An unset optional number is zero, so only guard bounds zero violates
+		if f.Minimum != "" {
+			cond := fmt.Sprintf("%s < %s", expr, f.Minimum)
+			if bound, _ := strconv.ParseFloat(f.Minimum, 64); bound > 0 {
+				cond = guard(cond)
+			}
+			checks = append(checks, fieldCheck{Cond: cond, Message: fmt.Sprintf("%s must be at least %s", name, f.Minimum)})
+		}
+		if f.Maximum != "" {
+			cond := fmt.Sprintf("%s > %s", expr, f.Maximum)
+			if bound, _ := strconv.ParseFloat(f.Maximum, 64); bound < 0 {
+				cond = guard(cond)
+			}
+			checks = append(checks, fieldCheck{Cond: cond, Message: fmt.Sprintf("%s must be at most %s", name, f.Maximum)})
+		}
+	}
+
+	return checks
+}
+
+// emptyCheck returns a condition that is true when expr holds no value, or an
+// empty string when the zero value of fieldType is a valid value
+func emptyCheck(fieldType, expr string) string {
+	switch {
+	case fieldType == "string":
+		return expr + ` == ""`
+	case fieldType == "time.Time":
+		return expr + ".IsZero()"
+	case strings.HasPrefix(fieldType, "[]"), strings.HasPrefix(fieldType, "map["),
+		strings.HasPrefix(fieldType, "*"), fieldType == "any", fieldType == "json.RawMessage":
+		return expr + " == nil"
+	}
+	return ""
+}
+
+// isNumeric reports whether fieldType is an integer or floating point type
+func isNumeric(fieldType string) bool {
+	switch fieldType {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return false
+}
+
+// jsonName returns the JSON key of a field
+func jsonName(f FieldSpec) string {
+	if name, _, _ := strings.Cut(f.Tags["json"], ","); name != "" {
+		return name
+	}
+	return f.Name
+}
@@ -4,8 +4,14 @@ package scaffolder
 import (
 	"context"
 	"fmt"
+	"go/format"
 	"path/filepath"
 	"strings"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/routes"
+	"github.com/shapestone/foundry/internal/staging"
 )
 
 // handlerScaffolder implements handler creation logic
@@ -53,7 +59,7 @@ func (s *handlerScaffolder) CreateHandler(ctx context.Context, spec *HandlerSpec
 	handlerPath := s.generateHandlerPath(spec)
 
 	// Check if handler already exists
-	if s.fileSystem.Exists(handlerPath) {
+	if s.fileSystem.Exists(handlerPath) && spec.Conflicts == nil {
 		return nil, fmt.Errorf("handler already exists: %s", handlerPath)
 	}
 
@@ -63,6 +69,8 @@ func (s *handlerScaffolder) CreateHandler(ctx context.Context, spec *HandlerSpec
 		return nil, fmt.Errorf("failed to render handler template: %w", err)
 	}
 
+	created := diff.Change{Path: filepath.ToSlash(handlerPath), After: []byte(handlerContent)}
+
 	// If dry run, show preview and return
 	if spec.DryRun {
 		changes := []string{
@@ -70,8 +78,12 @@ func (s *handlerScaffolder) CreateHandler(ctx context.Context, spec *HandlerSpec
 			fmt.Sprintf("+ Add handler: %s", spec.Name),
 		}
 
+		result.Diffs = append(result.Diffs, created)
 		if spec.AutoWire {
 			changes = append(changes, "+ Update routes.go")
+			if update, _, err := s.planWiring(spec, routes.WithPendingFile(handlerPath, []byte(handlerContent))); err == nil {
+				result.Diffs = append(result.Diffs, update.Diff())
+			}
 		}
 
 		result.Changes = changes
@@ -79,13 +91,34 @@ func (s *handlerScaffolder) CreateHandler(ctx context.Context, spec *HandlerSpec
 		return result, nil
 	}
 
-	// Create the handler file
-	if err := s.createHandlerFile(handlerPath, handlerContent); err != nil {
-		return nil, fmt.Errorf("failed to create handler file: %w", err)
+	// Apply the conflict policy to an existing handler file
+	write := true
+	if spec.Conflicts != nil {
+		var decision conflict.Decision
+		handlerContent, write, decision, err = s.resolveConflict(spec, handlerPath, handlerContent)
+		if err != nil {
+			return nil, err
+		}
+		result.Decisions = append(result.Decisions, decision)
+		created.After = []byte(handlerContent)
+		if decision.Action != conflict.Created {
+			created.Before, _ = s.fileSystem.ReadFile(handlerPath)
+		}
 	}
 
-	result.FilesCreated = append(result.FilesCreated, handlerPath)
-	result.Changes = append(result.Changes, fmt.Sprintf("Created handler: %s", handlerPath))
+	switch {
+	case !write:
+		result.Changes = append(result.Changes, fmt.Sprintf("Kept existing handler: %s", handlerPath))
+	default:
+		// Create the handler file
+		if err := s.createHandlerFile(handlerPath, handlerContent); err != nil {
+			return nil, fmt.Errorf("failed to create handler file: %w", err)
+		}
+
+		result.FilesCreated = append(result.FilesCreated, handlerPath)
+		result.Changes = append(result.Changes, fmt.Sprintf("Created handler: %s", handlerPath))
+		result.Diffs = append(result.Diffs, created)
+	}
 
 	// Handle auto-wiring if requested
 	if spec.AutoWire {
@@ -96,12 +129,13 @@ func (s *handlerScaffolder) CreateHandler(ctx context.Context, spec *HandlerSpec
 		} else {
 			result.FilesUpdated = append(result.FilesUpdated, wireResult.FilesUpdated...)
 			result.Changes = append(result.Changes, wireResult.Changes...)
+			result.Diffs = append(result.Diffs, wireResult.Diffs...)
 		}
 	}
 
 	result.Message = fmt.Sprintf("Handler '%s' created successfully", spec.Name)
 	result.Metadata["handler_path"] = handlerPath
-	result.Metadata["resource_path"] = strings.ToLower(spec.Name) + "s"
+	result.Metadata["resource_path"] = strings.TrimPrefix(handlerBasePath(spec), "/")
 
 	return result, nil
 }
@@ -160,15 +194,63 @@ func (s *handlerScaffolder) prepareHandlerData(spec *HandlerSpec) map[string]int
 	resourceNamePlural := pluralize(resourceName)
 	resourcePath := resourceName + "s"
 
-	return map[string]interface{}{
+	router := spec.Router
+	if router == "" {
+		router = routes.DetectRouter(spec.ProjectRoot)
+	}
+
+	data := map[string]interface{}{
 		"HandlerName":        handlerName,
 		"ResourceName":       resourceName,
 		"ResourceNamePlural": resourceNamePlural,
 		"ResourcePath":       resourcePath,
+		"BasePath":           handlerBasePath(spec),
 		"Module":             spec.Module,
 		"Type":               spec.Type,
 		"Metadata":           spec.Metadata,
+		"Router":             string(router),
+		"RouterImport":       router.ImportPath(),
+	}
+
+	if len(spec.Operations) > 0 {
+		operations := newHandlerOperations(spec.Operations, router)
+		types := newHandlerTypes(spec.Types)
+		data["Operations"] = operations
+		data["Types"] = types
+		data["Imports"] = handlerImports(operations, types, router)
+	} else {
+		data["Routes"] = crudRoutes(router)
+		data["IDParam"] = router.PathValue("id")
+	}
+
+	return data
+}
+
+// crudRoutes returns the route registrations of a CRUD handler
+func crudRoutes(router routes.Router) []string {
+	return []string{
+		router.Route("GET", "/", "h.List"),
+		router.Route("POST", "/", "h.Create"),
+		router.Route("GET", "/{id}", "h.Get"),
+		router.Route("PUT", "/{id}", "h.Update"),
+		router.Route("DELETE", "/{id}", "h.Delete"),
+	}
+}
+
+// handlerBasePath returns the path the handler is mounted at
+func handlerBasePath(spec *HandlerSpec) string {
+	if spec.BasePath != "" {
+		return spec.BasePath
+	}
+	return "/" + strings.ToLower(spec.Name) + "s"
+}
+
+// handlerTemplateName returns the template used for a handler type
+func handlerTemplateName(handlerType string) string {
+	if handlerType == "openapi" {
+		return "openapi-handler.go.tmpl"
 	}
+	return "handler.go.tmpl"
 }
 
 // generateHandlerPath generates the file path for the handler
@@ -179,7 +261,8 @@ func (s *handlerScaffolder) generateHandlerPath(spec *HandlerSpec) string {
 
 // renderHandlerTemplate renders the handler template with data
 func (s *handlerScaffolder) renderHandlerTemplate(data map[string]interface{}) (string, error) {
-	template, err := s.templateRenderer.LoadTemplate("handler.go.tmpl")
+	handlerType, _ := data["Type"].(string)
+	template, err := s.templateRenderer.LoadTemplate(handlerTemplateName(handlerType))
 	if err != nil {
 		return "", fmt.Errorf("failed to load handler template: %w", err)
 	}
@@ -189,7 +272,12 @@ func (s *handlerScaffolder) renderHandlerTemplate(data map[string]interface{}) (
 		return "", fmt.Errorf("failed to render handler template: %w", err)
 	}
 
-	return content, nil
+	formatted, err := format.Source([]byte(content))
+	if err != nil {
+		return "", fmt.Errorf("generated handler is not valid Go: %w", err)
+	}
+
+	return string(formatted), nil
 }
 
 // createHandlerFile creates the handler file with the given content
@@ -208,17 +296,62 @@ func (s *handlerScaffolder) createHandlerFile(path, content string) error {
 	return nil
 }
 
-// wireHandler handles auto-wiring of the handler
+// resolveConflict applies the spec's conflict policy to the handler file,
+// returning the content to write and whether to write it
+func (s *handlerScaffolder) resolveConflict(spec *HandlerSpec, handlerPath, content string) (string, bool, conflict.Decision, error) {
+	rel, err := filepath.Rel(spec.ProjectRoot, handlerPath)
+	if err != nil {
+		return "", false, conflict.Decision{}, err
+	}
+
+	area := staging.New(spec.ProjectRoot)
+	if err := area.WriteFile(rel, []byte(content), 0644); err != nil {
+		return "", false, conflict.Decision{}, err
+	}
+	decisions, err := spec.Conflicts.Resolve(area, []string{rel})
+	if err != nil {
+		return "", false, conflict.Decision{}, err
+	}
+
+	resolved, _, write := area.ReadFile(rel)
+	return string(resolved), write, decisions[0], nil
+}
+
+// wireHandler mounts the handler in the project's routes file
 func (s *handlerScaffolder) wireHandler(ctx context.Context, spec *HandlerSpec) (*Result, error) {
-	// TODO: Implement wiring logic here
-	// For now, return a simple success result
+	update, generator, err := s.planWiring(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := routes.ApplyUpdate(update, generator); err != nil {
+		return nil, err
+	}
+
 	return &Result{
-		Success: true,
-		Message: "Auto-wiring not yet implemented",
-		Changes: []string{"Would update routes.go"},
+		FilesUpdated: []string{update.Path},
+		Changes:      update.Changes,
+		Success:      true,
+		Message:      fmt.Sprintf("Handler '%s' wired successfully", spec.Name),
+		Diffs:        []diff.Change{update.Diff()},
 	}, nil
 }
 
+// planWiring calculates the changes that mount the handler in the project's
+// routes file without applying them
+func (s *handlerScaffolder) planWiring(spec *HandlerSpec, opts ...routes.Option) (*routes.Update, *routes.FileGenerator, error) {
+	generator := routes.NewFileGenerator(append([]routes.Option{
+		routes.WithRoutesFile(filepath.Join(spec.ProjectRoot, "internal", "routes", "routes.go")),
+		routes.WithProjectRoot(spec.ProjectRoot),
+	}, opts...)...)
+
+	update, err := generator.MountHandler(spec.Name, handlerBasePath(spec), spec.Module)
+	if err != nil {
+		return nil, nil, err
+	}
+	return update, generator, nil
+}
+
 // Helper functions
 
 // toGoIdentifier converts a string to a valid Go identifier
@@ -4,6 +4,10 @@ package scaffolder
 import (
 	"context"
 	"io"
+
+	"github.com/shapestone/foundry/internal/conflict"
+	"github.com/shapestone/foundry/internal/diff"
+	"github.com/shapestone/foundry/internal/routes"
 )
 
 // Scaffolder is the main interface for code generation operations
@@ -24,6 +28,49 @@ type HandlerSpec struct {
 	ProjectRoot string            `json:"project_root"`
 	Module      string            `json:"module"`
 	Metadata    map[string]string `json:"metadata"`
+
+	// BasePath is the path the handler is mounted at. It defaults to the
+	// pluralized handler name.
+	BasePath string `json:"base_path,omitempty"`
+	// Router is the router the handler registers its routes with. It is
+	// detected from the project when empty.
+	Router routes.Router `json:"router,omitempty"`
+	// Operations and Types describe handlers generated from an API
+	// description such as an OpenAPI document
+	Operations []OperationSpec `json:"operations,omitempty"`
+	Types      []TypeSpec      `json:"types,omitempty"`
+
+	// Conflicts decides what happens when the handler file already
+	// exists. Without it an existing handler is an error.
+	Conflicts *conflict.Resolver `json:"-"`
+}
+
+// OperationSpec describes a single HTTP operation served by a handler
+type OperationSpec struct {
+	Name            string      `json:"name"`   // Go method name, e.g. "ListUsers"
+	Method          string      `json:"method"` // HTTP method, e.g. "GET"
+	Path            string      `json:"path"`   // Route relative to the handler base path
+	Summary         string      `json:"summary"`
+	Params          string      `json:"params"`           // Parameters struct type, if any
+	Request         string      `json:"request"`          // Request body type, if any
+	ValidateRequest bool        `json:"validate_request"` // Call Validate on the request body
+	Response        string      `json:"response"`         // Response body type, if any
+	Status          int         `json:"status"`
+	PathParams      []ParamSpec `json:"path_params"`
+	QueryParams     []ParamSpec `json:"query_params"`
+}
+
+// ParamSpec describes a path or query parameter bound to a struct field
+type ParamSpec struct {
+	FieldSpec
+	Key string `json:"key"` // Parameter name in the URL
+}
+
+// TypeSpec describes a struct generated for request or response bodies
+type TypeSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Fields      []FieldSpec `json:"fields"`
 }
 
 // ModelSpec defines the specification for creating a model
@@ -43,6 +90,16 @@ type FieldSpec struct {
 	Type     string            `json:"type"`
 	Tags     map[string]string `json:"tags"`
 	Required bool              `json:"required"`
+	Unique   bool              `json:"unique"`
+
+	// Optional validation rules. Bounds are Go literals so they can be
+	// compared with the field type directly.
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Minimum     string   `json:"minimum,omitempty"`
+	Maximum     string   `json:"maximum,omitempty"`
+	MinLength   *int     `json:"min_length,omitempty"`
+	MaxLength   *int     `json:"max_length,omitempty"`
 }
 
 // MiddlewareSpec defines the specification for creating middleware
@@ -83,6 +140,13 @@ type Result struct {
 	Success      bool              `json:"success"`
 	Message      string            `json:"message"`
 	Metadata     map[string]string `json:"metadata"`
+
+	// Diffs holds the files that were, or in a dry run would be, created
+	// or modified
+	Diffs []diff.Change `json:"-"`
+	// Decisions records what was done with each generated file that was
+	// checked for conflicts
+	Decisions []conflict.Decision `json:"decisions,omitempty"`
 }
 
 // FileOperation represents a file system operation
@@ -146,6 +210,7 @@ type ProjectAnalyzer interface {
 type UserInteraction interface {
 	Confirm(message string) bool
 	ShowPreview(title string, changes []string, message string) bool
+	ShowChanges(title string, changes []diff.Change, message string) bool
 }
 
 // ValidationError represents a validation error
@@ -0,0 +1,262 @@
+// Package staging collects generated files outside the project and writes
+// them into it in one step, so that a generator that fails halfway leaves
+// the project as it was.
+package staging
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shapestone/foundry/internal/journal"
+)
+
+// Area holds the directories and files of one generation until they are
+// committed to the target directory
+type Area struct {
+	target    string
+	dirs      map[string]bool
+	files     map[string]stagedFile
+	unchecked map[string]bool
+}
+
+// stagedFile is the content and mode of a staged file
+type stagedFile struct {
+	content []byte
+	mode    os.FileMode
+}
+
+// New creates an empty staging area for the target directory
+func New(target string) *Area {
+	return &Area{
+		target:    target,
+		dirs:      make(map[string]bool),
+		files:     make(map[string]stagedFile),
+		unchecked: make(map[string]bool),
+	}
+}
+
+// Target returns the directory the area commits to
+func (a *Area) Target() string {
+	return a.target
+}
+
+// MkdirAll stages a directory, given relative to the target
+func (a *Area) MkdirAll(path string) error {
+	clean, err := a.relative(path)
+	if err != nil {
+		return err
+	}
+	a.dirs[clean] = true
+	return nil
+}
+
+// WriteFile stages a file, given relative to the target. Staging a path
+// again replaces the earlier content.
+func (a *Area) WriteFile(path string, content []byte, mode os.FileMode) error {
+	clean, err := a.relative(path)
+	if err != nil {
+		return err
+	}
+	a.files[clean] = stagedFile{content: content, mode: mode}
+	return nil
+}
+
+// ReadFile returns the content and mode of a staged file
+func (a *Area) ReadFile(path string) ([]byte, os.FileMode, bool) {
+	file, ok := a.files[filepath.Clean(path)]
+	return file.content, file.mode, ok
+}
+
+// Remove drops a staged file, so the file in the target is left as it is
+func (a *Area) Remove(path string) {
+	clean := filepath.Clean(path)
+	delete(a.files, clean)
+	delete(a.unchecked, clean)
+}
+
+// Unchecked leaves a staged file out of the Go syntax check, for files that
+// are not expected to parse, such as files with conflict markers
+func (a *Area) Unchecked(path string) {
+	a.unchecked[filepath.Clean(path)] = true
+}
+
+// Files returns the staged files in order
+func (a *Area) Files() []string {
+	files := make([]string, 0, len(a.files))
+	for path := range a.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Validate checks the staged files before they are committed: Go files
+// must parse, and no staged path may be both a file and a directory
+func (a *Area) Validate() error {
+	for _, path := range a.Files() {
+		for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+			if _, ok := a.files[dir]; ok {
+				return fmt.Errorf("%s is staged as a file and as a directory", dir)
+			}
+		}
+		if a.dirs[path] {
+			return fmt.Errorf("%s is staged as a file and as a directory", path)
+		}
+
+		if filepath.Ext(path) == ".go" && !a.unchecked[path] {
+			if _, err := parser.ParseFile(token.NewFileSet(), path, a.files[path].content, parser.AllErrors); err != nil {
+				return fmt.Errorf("generated file %s is not valid Go: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Commit validates the staged files and writes them to the target. If any
+// write fails, the files and directories written so far are removed and the
+// files that were overwritten get their old content back.
+func (a *Area) Commit() (err error) {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	// Tell the journal what is about to change before anything does
+	for dir := range a.dirs {
+		if err := journal.TouchDir(filepath.Join(a.target, dir)); err != nil {
+			return err
+		}
+	}
+	for _, path := range a.Files() {
+		if err := journal.Touch(filepath.Join(a.target, path)); err != nil {
+			return err
+		}
+	}
+
+	var (
+		createdDirs  []string
+		createdFiles []string
+		replaced     = make(map[string]stagedFile)
+	)
+	defer func() {
+		if err == nil {
+			return
+		}
+		var rollbackErrs []error
+		for path, original := range replaced {
+			if werr := writeAtomic(path, original.content, original.mode); werr != nil {
+				rollbackErrs = append(rollbackErrs, werr)
+			}
+		}
+		for i := len(createdFiles) - 1; i >= 0; i-- {
+			os.Remove(createdFiles[i])
+		}
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			os.Remove(createdDirs[i])
+		}
+		if len(rollbackErrs) > 0 {
+			err = fmt.Errorf("%w (rollback failed: %v)", err, errors.Join(rollbackErrs...))
+		}
+	}()
+
+	// Directories first, including the parents of files, shallowest first
+	dirs := []string{"."}
+	for dir := range a.dirs {
+		dirs = append(dirs, dir)
+	}
+	for path := range a.files {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		created, err := mkdirAll(filepath.Join(a.target, dir))
+		createdDirs = append(createdDirs, created...)
+		if err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	for _, path := range a.Files() {
+		full := filepath.Join(a.target, path)
+		if info, err := os.Stat(full); err == nil {
+			if info.IsDir() {
+				return fmt.Errorf("failed to write %s: a directory is in the way", path)
+			}
+			content, err := os.ReadFile(full)
+			if err != nil {
+				return fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+			replaced[full] = stagedFile{content: content, mode: info.Mode().Perm()}
+		} else {
+			createdFiles = append(createdFiles, full)
+		}
+
+		file := a.files[path]
+		if err := writeAtomic(full, file.content, file.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// relative cleans a path given relative to the target, rejecting paths
+// that leave it
+func (a *Area) relative(path string) (string, error) {
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside %s", path, a.target)
+	}
+	return clean, nil
+}
+
+// mkdirAll creates dir and its missing parents, returning the directories
+// it created, shallowest first
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append([]string{d}, missing...)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	var created []string
+	for _, d := range missing {
+		if err := os.Mkdir(d, 0755); err != nil && !os.IsExist(err) {
+			return created, err
+		}
+		created = append(created, d)
+	}
+	return created, nil
+}
+
+// writeAtomic writes a file through a temporary file in the same directory,
+// so the file has either its old or its new content
+func writeAtomic(path string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -10,14 +10,39 @@ import (
 
 // DefaultRenderer implements template rendering with common functionality
 type DefaultRenderer struct {
-	funcMap template.FuncMap
+	funcMap    template.FuncMap
+	sampleData TemplateData
+}
+
+// RendererOption configures a DefaultRenderer
+type RendererOption func(*DefaultRenderer)
+
+// WithFuncs sets the template functions in place of the defaults, for
+// templates that are rendered with other functions elsewhere
+func WithFuncs(funcs template.FuncMap) RendererOption {
+	return func(r *DefaultRenderer) {
+		r.funcMap = funcs
+	}
+}
+
+// WithSampleData sets the data ValidateTemplate renders templates with.
+// Without it templates are rendered with empty data.
+func WithSampleData(data TemplateData) RendererOption {
+	return func(r *DefaultRenderer) {
+		r.sampleData = data
+	}
 }
 
 // NewDefaultRenderer creates a new default template renderer
-func NewDefaultRenderer() TemplateRenderer {
-	return &DefaultRenderer{
-		funcMap: createDefaultFuncMap(),
+func NewDefaultRenderer(opts ...RendererOption) TemplateRenderer {
+	r := &DefaultRenderer{
+		funcMap:    createDefaultFuncMap(),
+		sampleData: make(TemplateData),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Render renders a template with the provided data
@@ -63,11 +88,9 @@ func (r *DefaultRenderer) ValidateTemplate(tmpl *Template) error {
 		tmpl.Parsed = parsed
 	}
 
-	// Test render with empty data to validate syntax
+	// Test render with the sample data to validate syntax
 	var buf bytes.Buffer
-	testData := make(TemplateData)
-
-	if err := tmpl.Parsed.Execute(&buf, testData); err != nil {
+	if err := tmpl.Parsed.Execute(&buf, r.sampleData); err != nil {
 		return NewTemplateError("validate", tmpl.Name, tmpl.Layout, err)
 	}
 
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"github.com/shapestone/foundry/internal/diff"
 	"github.com/shapestone/foundry/internal/interactive"
 	"github.com/shapestone/foundry/internal/project"
 	"github.com/shapestone/foundry/internal/routes"
@@ -13,7 +14,7 @@ type FileUpdate = routes.Update
 
 // UpdateRoutesFile safely updates the routes file with preview and rollback
 func UpdateRoutesFile(handlerName string, dryRun bool) error {
-	updater := routes.NewFileUpdater()
+	updater := routes.NewFileGenerator()
 	prompter := interactive.NewConsolePrompter()
 	moduleName := project.GetCurrentModule()
 
@@ -25,7 +26,7 @@ func UpdateRoutesFile(handlerName string, dryRun bool) error {
 
 	// Show preview
 	message := fmt.Sprintf("This will add the %s handler to your routes", handlerName)
-	if !prompter.ShowPreview(fmt.Sprintf("Preview changes to %s:", update.Path), update.Changes, message) {
+	if !prompter.ShowChanges(fmt.Sprintf("Preview changes to %s:", update.Path), []diff.Change{update.Diff()}, message) {
 		return fmt.Errorf("update cancelled by user")
 	}
 
@@ -39,31 +40,31 @@ func UpdateRoutesFile(handlerName string, dryRun bool) error {
 }
 
 // calculateRoutesUpdate calculates the routes update
-// Deprecated: Use routes.FileUpdater.UpdateRoutes instead
+// Deprecated: Use routes.FileGenerator.UpdateRoutes instead
 func calculateRoutesUpdate(content, handlerName string) *FileUpdate {
 	// This function is now internal to the routes package
 	// Keeping for backward compatibility if needed
-	panic("calculateRoutesUpdate is deprecated, use routes.FileUpdater.UpdateRoutes")
+	panic("calculateRoutesUpdate is deprecated, use routes.FileGenerator.UpdateRoutes")
 }
 
 // showUpdatePreview shows update preview
-// Deprecated: Use interactive.ConsolePrompter.ShowPreview instead
+// Deprecated: Use interactive.ConsolePrompter.ShowChanges instead
 func showUpdatePreview(update *FileUpdate, handlerName string) bool {
 	prompter := interactive.NewConsolePrompter()
 	message := fmt.Sprintf("This will add the %s handler to your routes", handlerName)
-	return prompter.ShowPreview(fmt.Sprintf("Preview changes to %s:", update.Path), update.Changes, message)
+	return prompter.ShowChanges(fmt.Sprintf("Preview changes to %s:", update.Path), []diff.Change{update.Diff()}, message)
 }
 
 // applyFileUpdate applies file update
 // Deprecated: Use routes.ApplyUpdate instead
 func applyFileUpdate(update *FileUpdate) error {
-	updater := routes.NewFileUpdater()
+	updater := routes.NewFileGenerator()
 	return routes.ApplyUpdate(update, updater)
 }
 
 // validateGoFile validates Go file syntax
-// Deprecated: Use routes.FileUpdater.ValidateGoFile instead
+// Deprecated: Use routes.FileGenerator.ValidateGoFile instead
 func validateGoFile(path string) error {
-	updater := routes.NewFileUpdater()
+	updater := routes.NewFileGenerator()
 	return updater.ValidateGoFile(path)
 }
@@ -2,5 +2,5 @@ package foundry
 
 import "embed"
 
-//go:embed templates
+//go:embed all:templates
 var Templates embed.FS
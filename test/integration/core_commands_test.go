@@ -2,6 +2,13 @@
 package integration
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -126,6 +133,92 @@ func TestFoundryNew(t *testing.T) {
 	}
 }
 
+// TestFoundryNewWithStack tests choosing the router and logger of a new project
+func TestFoundryNewWithStack(t *testing.T) {
+	h := NewTestHelper(t)
+
+	output, err := h.RunFoundry("new", "api", "--router=gin", "--logger=zap", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+
+	h.AssertFileContains("api/main.go", "routes.RegisterAPIRoutes(router.Group(\"/api/v1\"))")
+	h.AssertFileContains("api/main.go", "Handler:      middleware.RequestLogger(log)(router),")
+	h.AssertFileContains("api/internal/routes/routes.go", "func RegisterAPIRoutes(r *gin.RouterGroup) {")
+	h.AssertFileContains("api/internal/logger/logger.go", "func New(level string) *zap.Logger {")
+	h.AssertFileContains("api/internal/middleware/request_logger.go", "zap.Int(\"status\", rec.status),")
+	h.AssertFileContains("api/go.mod", "github.com/gin-gonic/gin")
+	h.AssertFileContains("api/foundry.yaml", "  router: gin\n  logger: zap")
+
+	// Later commands use the recorded router
+	h.CreateFile("api/api.yaml", `openapi: 3.0.3
+info: {title: Shop, version: "1.0"}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200": {description: ok}
+`)
+	output, err = h.RunFoundryInDir(filepath.Join(h.GetTempDir(), "api"), "add", "handler", "--from-openapi", "api.yaml", "--auto-wire")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "for the gin router")
+	h.AssertFileContains("api/internal/routes/routes.go", `usersHandler.RegisterRoutes(r.Group("/users"))`)
+
+	output, err = h.RunFoundry("new", "web", "--router=fiber", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "invalid router 'fiber'")
+	h.AssertFileNotExists("web")
+}
+
+// TestFoundryNewExtendedLayout tests creating a project from a layout that
+// extends a built-in layout
+func TestFoundryNewExtendedLayout(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("templates/layouts/acme/layout.manifest.yaml", `name: acme
+version: 0.1.0
+extends: standard@1.x
+structure:
+  directories:
+    - path: "internal/platform"
+  files:
+    - template: "project/README.md.tmpl"
+      target: "README.md"
+    - template: "project/CONTRIBUTING.md.tmpl"
+      target: "CONTRIBUTING.md"
+options:
+  router:
+    default: chi
+    choices: [chi, gin]
+variables:
+  - name: http_port
+    default: "9000"
+`)
+	h.CreateFile("templates/layouts/acme/project/README.md.tmpl", "# {{.ProjectName}} at Acme\n")
+	h.CreateFile("templates/layouts/acme/project/CONTRIBUTING.md.tmpl", "Ask the platform team first.\n")
+
+	output, err := h.RunFoundry("new", "app", "--layout=acme", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+	h.AssertFileContains("app/README.md", "# app at Acme")
+	h.AssertFileContains("app/CONTRIBUTING.md", "Ask the platform team first.")
+	h.AssertFileContains("app/main.go", "router := chi.NewRouter()")
+	h.AssertFileContains("app/main.go", `port := getEnv("PORT", "9000")`)
+	h.AssertFileExists("app/internal/platform")
+	h.AssertFileExists("app/Makefile")
+
+	// Parents must match the version constraint and must not loop
+	h.CreateFile("templates/layouts/future/layout.manifest.yaml", "name: future\nversion: 1.0.0\nextends: standard@2.x\n")
+	output, err = h.RunFoundry("new", "svc", "--layout=future", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "extends standard@2.x, but 'standard' is version 1.0.0")
+
+	h.CreateFile("templates/layouts/loop-a/layout.manifest.yaml", "name: loop-a\nversion: 1.0.0\nextends: loop-b\n")
+	h.CreateFile("templates/layouts/loop-b/layout.manifest.yaml", "name: loop-b\nversion: 1.0.0\nextends: loop-a\n")
+	output, err = h.RunFoundry("new", "svc", "--layout=loop-a", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "layout inheritance cycle: loop-a -> loop-b -> loop-a")
+}
+
 // TestFoundryAddHandler tests adding handlers
 func TestFoundryAddHandler(t *testing.T) {
 	h := NewTestHelper(t)
@@ -190,6 +283,720 @@ func TestFoundryAddModel(t *testing.T) {
 	h.AssertFileContains("internal/models/user.go", "func NewUser()")
 }
 
+// TestFoundryAddModelWithFields tests adding a model with field definitions
+func TestFoundryAddModelWithFields(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("go.mod", "module example.com/shop\n\ngo 1.21\n")
+
+	output, err := h.RunFoundry("add", "model", "product",
+		"name:string", "price:float64:required", "sku:string:unique", "tags:[]string")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Model created successfully")
+	h.AssertOutputContains(output, "Price field (float64), required")
+
+	h.AssertFileContains("internal/models/product.go", "Price     float64   `json:\"price\" db:\"price\" validate:\"required\"`")
+	h.AssertFileContains("internal/models/product.go", "SKU       string    `json:\"sku\" db:\"sku,unique\"`")
+	h.AssertFileContains("internal/models/product.go", "Tags      []string  `json:\"tags\" db:\"tags\"`")
+	h.AssertFileContains("internal/models/product.go", "if m.Price == 0 {")
+	h.AssertFileContains("internal/models/product.go", "\"sku\":        m.SKU,")
+	h.AssertFileContains("internal/models/product.go", "if v, ok := data[\"tags\"].([]string); ok {")
+
+	// Invalid definitions are rejected before anything is written
+	output, err = h.RunFoundry("add", "model", "order", "total:decimal")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "unsupported field type")
+	h.AssertFileNotExists("internal/models/order.go")
+
+	// A column is defined once, whatever its modifiers
+	output, err = h.RunFoundry("add", "model", "order", "sku:string", "sku:string:unique")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "field sku is defined more than once")
+	h.AssertFileNotExists("internal/models/order.go")
+}
+
+// TestFoundryNewConditionalFiles tests leaving out layout files whose when
+// condition is false
+func TestFoundryNewConditionalFiles(t *testing.T) {
+	h := NewTestHelper(t)
+
+	output, err := h.RunFoundry("new", "api", "--without=docker", "--dry-run")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "+ main.go")
+	h.AssertOutputContains(output, "- docker-compose.yml (when: {{ .Features.docker }})")
+	h.AssertFileNotExists("api")
+
+	output, err = h.RunFoundry("new", "api", "--without=docker", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+	h.AssertFileExists("api/main.go")
+	h.AssertFileNotExists("api/Dockerfile")
+	h.AssertFileNotExists("api/docker-compose.yml")
+
+	output, err = h.RunFoundry("new", "web", "--without=kubernetes", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "layout 'standard' has no kubernetes feature")
+}
+
+// TestFoundryNewWithVars tests setting typed layout variables with --var
+func TestFoundryNewWithVars(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("templates/layouts/svc/layout.manifest.yaml", `name: svc
+version: 1.0.0
+extends: standard
+structure:
+  files:
+    - template: "project/service.txt.tmpl"
+      target: "service.txt"
+variables:
+  - name: service_name
+    type: regex
+    pattern: "^[a-z][a-z0-9_]*$"
+    default: user
+  - name: grpc_port
+    type: port
+    default: "9090"
+  - name: tracing
+    type: bool
+    default: "false"
+  - name: broker
+    type: enum
+    choices: [nats, kafka]
+    default: nats
+`)
+	h.CreateFile("templates/layouts/svc/project/service.txt.tmpl",
+		"{{.CustomVariables.service_name}} grpc={{.CustomVariables.grpc_port}} http={{.CustomVariables.http_port}} tracing={{.CustomVariables.tracing}}\n")
+
+	output, err := h.RunFoundry("new", "orders", "--layout=svc", "--no-git",
+		"--var", "grpc_port=9191", "--var", "service_name=orders", "--var", "tracing=yes")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "invalid tracing 'yes': must be true or false")
+	h.AssertFileNotExists("orders")
+
+	output, err = h.RunFoundry("new", "orders", "--layout=svc", "--no-git",
+		"--var", "grpc_port=9191", "--var", "service_name=orders", "--var", "tracing=1")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+	h.AssertFileContains("orders/service.txt", "orders grpc=9191 http=8080 tracing=true")
+
+	for _, tc := range []struct{ arg, message string }{
+		{"grpc_port=99999", "invalid grpc_port '99999': must be a port between 1 and 65535"},
+		{"http_port=web", "invalid http_port 'web': must be a port between 1 and 65535"},
+		{"service_name=Orders", "invalid service_name 'Orders': must match ^[a-z][a-z0-9_]*$"},
+		{"broker=redis", "invalid broker 'redis' (choose from nats, kafka)"},
+		{"region=eu", "layout 'svc' has no region variable"},
+		{"grpc_port", "invalid --var 'grpc_port' (use name=value)"},
+	} {
+		output, err = h.RunFoundry("new", "bad", "--layout=svc", "--no-git", "--var", tc.arg)
+		h.AssertError(err, "")
+		h.AssertOutputContains(output, tc.message)
+	}
+
+	// The built-in microservice layout
+	output, err = h.RunFoundry("new", "shop", "--layout=microservice", "--no-git",
+		"--var", "service_name=orders", "--var", "grpc_port=9191")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+	h.AssertFileContains("shop/shared/config/config.go", `envconfig:"GRPC_PORT" default:"9191"`)
+	h.AssertFileContains("shop/shared/config/config.go", `envconfig:"SERVICE_NAME" default:"orders"`)
+	h.AssertFileContains("shop/deployments/kubernetes/orders-ingress.yaml", "number: 9191")
+	h.AssertFileExists("shop/services/orders/main.go")
+	h.AssertFileExists("shop/api/proto/orders.proto")
+
+	// Values are checked before the templates load
+	output, err = h.RunFoundry("new", "bad", "--layout=microservice", "--no-git", "--var", "grpc_port=abc")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "validation failed: invalid grpc_port 'abc': must be a port between 1 and 65535")
+}
+
+// TestFoundryNewInteractive tests creating a project by answering the wizard
+func TestFoundryNewInteractive(t *testing.T) {
+	h := NewTestHelper(t)
+
+	// Name, layout, module, logger, router, variables (one bad port), features, confirm
+	answers := strings.Join([]string{
+		"Bad Name", "shop", "standard", "github.com/acme/shop",
+		"zap", "3",
+		"abc", "9000", "", "", "", "",
+		"http-server,router-choice,makefile",
+		"y",
+	}, "\n") + "\n"
+	output, err := h.RunFoundryWithInput(answers, "new", "--interactive", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "invalid project name: Bad Name")
+	h.AssertOutputContains(output, "invalid http_port 'abc': must be a port between 1 and 65535")
+	h.AssertOutputContains(output, "shop (standard layout)")
+	h.AssertOutputContains(output, "│   │   └── routes.go")
+	h.AssertOutputContains(output, "- docker-compose.yml (skipped: {{ .Features.docker }})")
+	h.AssertOutputContains(output, "created successfully")
+	h.AssertFileContains("shop/go.mod", "module github.com/acme/shop")
+	h.AssertFileContains("shop/main.go", "router := gin.New()")
+	h.AssertFileContains("shop/main.go", `port := getEnv("PORT", "9000")`)
+	h.AssertFileContains("shop/internal/logger/logger.go", "zap")
+	h.AssertFileNotExists("shop/Dockerfile")
+
+	// Flags are not asked again, and declining creates nothing
+	output, err = h.RunFoundryWithInput("\n\n\n\nn\n", "new", "api", "--interactive", "--layout=standard", "--router=chi", "--logger=slog", "--var", "http_port=8081")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "project creation cancelled by user")
+	if strings.Contains(output, "Project name") || strings.Contains(output, "HTTP router") {
+		t.Errorf("wizard asked for settings given as flags:\n%s", output)
+	}
+	h.AssertFileNotExists("api")
+
+	// Without a terminal the wizard only runs when asked for
+	output, err = h.RunFoundryWithInput("", "new")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "accepts 1 arg(s), received 0")
+}
+
+// TestFoundryNewAnswers tests recording project settings in an answers file
+// and generating the same project from it
+func TestFoundryNewAnswers(t *testing.T) {
+	h := NewTestHelper(t)
+
+	output, err := h.RunFoundry("new", "shop", "--router=chi", "--without=docker", "--var", "http_port=9000",
+		"--author=Ada", "--save-answers", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Saved answers to")
+	h.AssertFileContains("shop/foundry.answers.yaml", "layout: standard\nproject_name: shop\nmodule: shop\nauthor: Ada")
+	h.AssertFileContains("shop/foundry.answers.yaml", "router: chi")
+	h.AssertFileContains("shop/foundry.answers.yaml", "http_port: \"9000\"")
+	h.AssertFileContains("shop/foundry.answers.yaml", "docker: false")
+
+	// The same project again, from the answers alone
+	regen := filepath.Join(h.GetTempDir(), "regen")
+	h.CreateFile("regen/answers.yaml", h.ReadFile("shop/foundry.answers.yaml"))
+	output, err = h.RunFoundryInDir(regen, "new", "--answers", "answers.yaml", "--no-git")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "created successfully")
+	for _, file := range []string{"main.go", "go.mod", "README.md", "foundry.yaml", "internal/routes/routes.go"} {
+		if h.ReadFile("shop/"+file) != h.ReadFile("regen/shop/"+file) {
+			t.Errorf("%s differs from the original project", file)
+		}
+	}
+	h.AssertFileNotExists("regen/shop/Dockerfile")
+
+	// Flags win over the answers
+	output, err = h.RunFoundryInDir(regen, "new", "api", "--answers", "answers.yaml", "--router=gin", "--no-git")
+	h.AssertNoError(err)
+	h.AssertFileContains("regen/api/main.go", "router := gin.New()")
+	h.AssertFileContains("regen/api/go.mod", "module shop")
+
+	h.CreateFile("bad.yaml", "layout: standard\nproject_name: x\nmodule: x\ncolour: red\n")
+	output, err = h.RunFoundry("new", "--answers", "bad.yaml")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "answers file bad.yaml has unknown key 'colour' on line 4")
+
+	h.CreateFile("partial.yaml", "layout: standard\nproject_name: x\n")
+	output, err = h.RunFoundry("new", "--answers", "partial.yaml")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "answers file partial.yaml is missing 'module'")
+}
+
+// TestFoundryGenerationRollback tests that failed generation leaves the
+// target directory as it was
+func TestFoundryGenerationRollback(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("templates/layouts/broken/layout.manifest.yaml", `name: broken
+version: 1.0.0
+extends: standard
+structure:
+  files:
+    - template: "project/zz_broken.txt.tmpl"
+      target: "zz_broken.txt"
+`)
+	h.CreateFile("templates/layouts/broken/project/zz_broken.txt.tmpl", "{{template \"missing\"}}\n")
+	h.CreateFile("templates/layouts/invalid/layout.manifest.yaml", `name: invalid
+version: 1.0.0
+extends: standard
+structure:
+  files:
+    - template: "project/broken.go.tmpl"
+      target: "internal/broken/broken.go"
+`)
+	h.CreateFile("templates/layouts/invalid/project/broken.go.tmpl", "package broken\n\nfunc {\n")
+
+	// A template that fails to execute writes nothing
+	output, err := h.RunFoundry("new", "app", "--layout=broken", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "failed to execute template for zz_broken.txt")
+	h.AssertFileNotExists("app")
+
+	// Neither does a generated Go file that does not parse
+	output, err = h.RunFoundry("new", "app", "--layout=invalid", "--no-git")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "generated file internal/broken/broken.go is not valid Go")
+	h.AssertFileNotExists("app")
+
+	// --force keeps the existing project when generation fails
+	h.CreateFile("app/main.go", "package main // mine\n")
+	output, err = h.RunFoundry("new", "app", "--layout=broken", "--force", "--no-git")
+	h.AssertError(err, "")
+	h.AssertFileContains("app/main.go", "package main // mine")
+	h.AssertFileNotExists("app/go.mod")
+	h.AssertFileNotExists("app.foundry-backup")
+
+	output, err = h.RunFoundryInDir(filepath.Join(h.GetTempDir(), "app"), "init", "--layout=invalid", "--force", "--no-git")
+	h.AssertError(err, "")
+	h.AssertFileContains("app/main.go", "package main // mine")
+	h.AssertFileNotExists("app/go.mod")
+	h.AssertFileNotExists("app/internal")
+
+	// add db writes all of its files or none
+	_, err = h.RunFoundry("new", "api", "--no-git")
+	h.AssertNoError(err)
+	h.CreateFile("api/internal/database", "not a directory\n")
+	env := h.ReadFile("api/.env.example")
+	output, err = h.RunFoundryInDir(filepath.Join(h.GetTempDir(), "api"), "add", "db", "postgres", "--with-migrations")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "failed to write database files")
+	if h.ReadFile("api/.env.example") != env {
+		t.Error(".env.example changed although add db failed")
+	}
+	h.AssertFileNotExists("api/migrations")
+}
+
+// TestFoundryLockFile tests that new and add record generated files in
+// .foundry/lock.yaml
+func TestFoundryLockFile(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	_, err := h.RunFoundry("new", "api", "--var", "http_port=9090", "--no-git")
+	h.AssertNoError(err)
+
+	h.AssertFileExists("api/.foundry/lock.yaml")
+	h.AssertFileContains("api/.foundry/lock.yaml", "name: standard")
+	h.AssertFileContains("api/.foundry/lock.yaml", "type: embedded")
+	h.AssertFileContains("api/.foundry/lock.yaml", "checksum: ")
+	h.AssertFileContains("api/.foundry/lock.yaml", `http_port: "9090"`)
+	h.AssertFileContains("api/.foundry/lock.yaml", "template: project/main.go.tmpl")
+
+	sum := sha256.Sum256([]byte(h.ReadFile("api/go.mod")))
+	h.AssertFileContains("api/.foundry/lock.yaml", "sha256: "+hex.EncodeToString(sum[:]))
+
+	// add records the files it generates
+	output, err := h.RunFoundryInDir(filepath.Join(h.GetTempDir(), "api"), "add", "handler", "users")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "usersHandler := handlers.NewUsersHandler()")
+	h.AssertFileContains("api/.foundry/lock.yaml", "internal/handlers/users.go:")
+	h.AssertFileContains("api/.foundry/lock.yaml", "generator: add handler")
+
+	// A dry run leaves the lock file alone
+	lock := h.ReadFile("api/.foundry/lock.yaml")
+	_, err = h.RunFoundryInDir(filepath.Join(h.GetTempDir(), "api"), "add", "handler", "orders", "--dry-run")
+	h.AssertNoError(err)
+	if h.ReadFile("api/.foundry/lock.yaml") != lock {
+		t.Error("lock file changed by a dry run")
+	}
+}
+
+// TestFoundryUpgrade tests merging a newer layout version into a project
+func TestFoundryUpgrade(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	manifest := `name: svc
+version: %s
+structure:
+  files:
+    - template: "project/notes.txt.tmpl"
+      target: "notes.txt"
+    - template: "project/conf.txt.tmpl"
+      target: "conf.txt"
+`
+	h.CreateFile("templates/layouts/svc/layout.manifest.yaml", fmt.Sprintf(manifest, "1.0.0"))
+	h.CreateFile("templates/layouts/svc/project/notes.txt.tmpl", "one\ntwo\nthree\nfour\nfive\n")
+	h.CreateFile("templates/layouts/svc/project/conf.txt.tmpl", "port=1\n")
+
+	_, err := h.RunFoundry("new", "app", "--layout=svc", "--no-git")
+	h.AssertNoError(err)
+	h.AssertFileContains("app/.foundry/base/notes.txt", "one")
+	h.AssertFileContains("app/.foundry/.gitignore", "journal/")
+
+	// Edit the project, then release version 1.1.0 of the layout next to it
+	app := filepath.Join(h.GetTempDir(), "app")
+	h.CreateFile("app/notes.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+	h.CreateFile("app/conf.txt", "port=9\n")
+	h.CreateFile("app/templates/layouts/svc/layout.manifest.yaml", fmt.Sprintf(manifest, "1.1.0")+`    - template: "project/new.txt.tmpl"
+      target: "new.txt"
+`)
+	h.CreateFile("app/templates/layouts/svc/project/notes.txt.tmpl", "one\ntwo\nthree\nfour\nFIVE\n")
+	h.CreateFile("app/templates/layouts/svc/project/conf.txt.tmpl", "port=2\n")
+	h.CreateFile("app/templates/layouts/svc/project/new.txt.tmpl", "new\n")
+
+	output, err := h.RunFoundryInDir(app, "upgrade", "--dry-run")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "svc layout 1.0.0 → 1.1.0")
+	h.AssertOutputContains(output, "~ notes.txt (merged)")
+	h.AssertOutputContains(output, "! conf.txt (1 conflict(s))")
+	h.AssertOutputContains(output, "+ new.txt")
+	h.AssertFileNotExists("app/new.txt")
+
+	output, err = h.RunFoundryInDir(app, "upgrade")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "upgrade left conflicts in 1 file(s)")
+	if got := h.ReadFile("app/notes.txt"); got != "ONE\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("notes.txt was not merged:\n%s", got)
+	}
+	h.AssertFileContains("app/conf.txt", "<<<<<<< current\nport=9\n=======\nport=2\n>>>>>>> svc 1.1.0")
+	h.AssertFileContains("app/new.txt", "new")
+	h.AssertFileContains("app/.foundry/lock.yaml", "version: 1.1.0")
+
+	// The new output is the base of the next upgrade
+	h.CreateFile("app/conf.txt", "port=2\n")
+	output, err = h.RunFoundryInDir(app, "upgrade")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Project is up to date")
+
+	// Versions that are not available are refused
+	output, err = h.RunFoundryInDir(app, "upgrade", "--to", "9.9.9")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "version 9.9.9 is not available")
+}
+
+// TestFoundryDiff tests comparing a project with its layout
+func TestFoundryDiff(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	_, err := h.RunFoundry("new", "api", "--no-git")
+	h.AssertNoError(err)
+	api := filepath.Join(h.GetTempDir(), "api")
+	_, err = h.RunFoundryInDir(api, "add", "handler", "users")
+	h.AssertNoError(err)
+
+	output, err := h.RunFoundryInDir(api, "diff")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "0 modified, 0 deleted, 0 missing")
+
+	// Edit a template file and a component, delete another file
+	h.CreateFile("api/main.go", strings.Replace(h.ReadFile("api/main.go"), "package main", "package main // edited", 1))
+	h.CreateFile("api/internal/handlers/users.go", h.ReadFile("api/internal/handlers/users.go")+"// mine\n")
+	_, err = h.RunFoundryInDir(api, "add", "model", "product")
+	h.AssertNoError(err)
+	lock := h.ReadFile("api/.foundry/lock.yaml")
+	if err := os.Remove(filepath.Join(api, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err = h.RunFoundryInDir(api, "diff")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "--- a/main.go\n+++ b/main.go")
+	h.AssertOutputContains(output, "-package main\n+package main // edited")
+	h.AssertOutputContains(output, "+// mine")
+	h.AssertOutputContains(output, "--- a/README.md\n+++ /dev/null")
+	h.AssertOutputContains(output, "~ main.go (modified, from template project/main.go.tmpl)")
+	h.AssertOutputContains(output, "~ internal/handlers/users.go (modified, from component handler users)")
+	h.AssertOutputContains(output, "- README.md (deleted")
+	h.AssertOutputContains(output, "2 modified, 1 deleted, 0 missing")
+	if h.ReadFile("api/.foundry/lock.yaml") != lock {
+		t.Error("diff changed the lock file")
+	}
+
+	// Paths limit the comparison; --summary leaves out the diffs
+	output, err = h.RunFoundryInDir(api, "diff", "--summary", "internal")
+	h.AssertNoError(err)
+	if strings.Contains(output, "+++") || strings.Contains(output, "~ main.go") {
+		t.Errorf("diff --summary internal printed more than the summary of internal:\n%s", output)
+	}
+	h.AssertOutputContains(output, "1 modified, 0 deleted, 0 missing")
+}
+
+// TestFoundryAddHandlerFromOpenAPI tests generating handlers from an OpenAPI document
+func TestFoundryAddHandlerFromOpenAPI(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("go.mod", "module example.com/api\n\ngo 1.21\n")
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(r chi.Router) {
+}
+`)
+	h.CreateFile("api.yaml", `openapi: 3.0.3
+info: {title: Shop, version: "1.0"}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, minimum: 1}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/User'}}
+    post:
+      operationId: createUser
+      tags: [users]
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+      responses:
+        "201": {description: created}
+  /users/{userId}:
+    get:
+      operationId: getUser
+      tags: [users]
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: integer, format: int64}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+  /orders:
+    get:
+      tags: [orders]
+      responses:
+        "200": {description: ok}
+components:
+  schemas:
+    User:
+      type: object
+      required: [email]
+      properties:
+        id: {type: integer, format: int64}
+        email: {type: string, minLength: 1, maxLength: 254}
+`)
+
+	output, err := h.RunFoundry("add", "handler", "--from-openapi", "api.yaml", "--tag", "users", "--auto-wire")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Handlers generated successfully")
+	h.AssertOutputContains(output, "/api/v1/users/{userId} -> GetUser")
+	h.AssertFileNotExists("internal/handlers/orders.go")
+
+	handler := "internal/handlers/users.go"
+	h.AssertFileContains(handler, "type User struct")
+	h.AssertFileContains(handler, "Email string `json:\"email\"`")
+	h.AssertFileContains(handler, "func (v *User) Validate() error")
+	h.AssertFileContains(handler, `errors.New("email length must be at least 1")`)
+	h.AssertFileContains(handler, `errors.New("email length must be at most 254")`)
+	h.AssertFileContains(handler, "type GetUserParams struct")
+	h.AssertFileContains(handler, `if raw := chi.URLParam(r, "userId"); raw != "" {`)
+	h.AssertFileContains(handler, "if p.Limit < 1 {")
+	h.AssertFileContains(handler, `r.Get("/{userId}", h.GetUser)`)
+	h.AssertFileContains(handler, "if err := body.Validate(); err != nil {")
+	h.AssertFileContains(handler, "h.respond(w, http.StatusOK, response)")
+	h.AssertFileContains("internal/routes/routes.go", `r.Mount("/users", usersHandler.Routes())`)
+
+	// Types declared by earlier runs are reused, not redeclared
+	_, err = h.RunFoundry("add", "handler", "--from-openapi", "api.yaml", "--tag", "orders")
+	h.AssertNoError(err)
+	h.AssertFileContains("internal/handlers/orders.go", `r.Get("/", h.GetOrders)`)
+
+	output, err = h.RunFoundry("add", "handler", "--from-openapi", "api.yaml", "--tag", "missing")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, `no operations are tagged "missing"`)
+}
+
+// TestFoundryAddHandlerRouters tests generating and wiring handlers for the
+// router a project uses
+func TestFoundryAddHandlerRouters(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("go.mod", "module example.com/api\n\ngo 1.22\n")
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import "github.com/gin-gonic/gin"
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(r *gin.Engine) {
+	api := r.Group("/api/v1")
+	_ = api
+}
+`)
+	spec := `openapi: 3.0.3
+info: {title: Shop, version: "1.0"}
+paths:
+  /users/{userId}:
+    get:
+      operationId: getUser
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+`
+	h.CreateFile("api.yaml", spec)
+
+	output, err := h.RunFoundry("add", "handler", "--from-openapi", "api.yaml", "--auto-wire")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "for the gin router")
+
+	handler := "internal/handlers/users.go"
+	h.AssertFileContains(handler, `"github.com/gin-gonic/gin"`)
+	h.AssertFileContains(handler, "func (h *UsersHandler) RegisterRoutes(r gin.IRouter) {")
+	h.AssertFileContains(handler, `r.GET("/:userId", h.handle(h.GetUser))`)
+	h.AssertFileContains(handler, `if raw := r.PathValue("userId"); raw != "" {`)
+	h.AssertFileContains(handler, "c.Request.SetPathValue(param.Key, param.Value)")
+	h.AssertFileContains("internal/routes/routes.go", `usersHandler.RegisterRoutes(api.Group("/users"))`)
+
+	// Projects on net/http use the method patterns of ServeMux
+	h = NewTestHelper(t)
+	h.CreateFile("go.mod", "module example.com/api\n\ngo 1.22\n")
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import "net/http"
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(mux *http.ServeMux) {
+}
+`)
+	h.CreateFile("api.yaml", spec)
+
+	output, err = h.RunFoundry("add", "handler", "--from-openapi", "api.yaml", "--auto-wire")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "for the http router")
+	h.AssertFileContains(handler, "func (h *UsersHandler) RegisterRoutes(mux *http.ServeMux, prefix string) {")
+	h.AssertFileContains(handler, `mux.HandleFunc("GET "+prefix+"/{userId}", h.GetUser)`)
+	h.AssertFileContains("internal/routes/routes.go", `usersHandler.RegisterRoutes(mux, "/users")`)
+}
+
+// TestFoundryOpenAPIExport tests describing a project's routes as an OpenAPI document
+func TestFoundryOpenAPIExport(t *testing.T) {
+	h := NewTestHelper(t)
+	h.CreateFile("go.mod", "module example.com/shop\n\ngo 1.21\n")
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import (
+	"example.com/shop/internal/handlers"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(r chi.Router) {
+	productHandler := handlers.NewProductHandler()
+	r.Mount("/products", productHandler.Routes())
+}
+`)
+	h.CreateFile("internal/models/product.go", `package models
+
+import "time"
+
+// Product is an item in the catalogue
+type Product struct {
+	ID        int64     `+"`json:\"id\"`"+`
+	Name      string    `+"`json:\"name\"`"+`
+	Price     float64   `+"`json:\"price,omitempty\"`"+`
+	CreatedAt time.Time `+"`json:\"created_at\"`"+`
+}
+`)
+	h.CreateFile("internal/handlers/product.go", `package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"example.com/shop/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+type ProductHandler struct{}
+
+func NewProductHandler() *ProductHandler {
+	return &ProductHandler{}
+}
+
+func (h *ProductHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Get("/{id}", h.Get)
+	return r
+}
+
+// List returns every product
+func (h *ProductHandler) List(w http.ResponseWriter, r *http.Request) {
+	products := []models.Product{}
+	json.NewEncoder(w).Encode(products)
+}
+
+// Create adds a product
+func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var product models.Product
+	json.NewDecoder(r.Body).Decode(&product)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+}
+`)
+
+	output, err := h.RunFoundry("openapi", "export", "-f", "openapi.yaml", "--title", "Shop")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Exported 2 path(s) to openapi.yaml")
+
+	spec := "openapi.yaml"
+	h.AssertFileContains(spec, "openapi: 3.1.0")
+	h.AssertFileContains(spec, "title: Shop")
+	h.AssertFileContains(spec, "url: /api/v1")
+	h.AssertFileContains(spec, "/products/{id}:")
+	h.AssertFileContains(spec, "operationId: listProduct")
+	h.AssertFileContains(spec, "summary: Create adds a product")
+	h.AssertFileContains(spec, `"201":`)
+	h.AssertFileContains(spec, "$ref: '#/components/schemas/Product'")
+	h.AssertFileContains(spec, "format: date-time")
+	h.AssertFileContains(spec, "- created_at")
+
+	// Without --output the document is written to stdout
+	output, err = h.RunFoundry("openapi", "export")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "paths:")
+}
+
+// TestFoundryOpenAPIExportRouters tests that handlers generated for each
+// router are exported with the paths they are mounted at
+func TestFoundryOpenAPIExportRouters(t *testing.T) {
+	for _, router := range []string{"chi", "gin", "echo", "gorilla", "stdlib"} {
+		t.Run(router, func(t *testing.T) {
+			h := NewTestHelper(t)
+			_, err := h.RunFoundry("new", "shop", "--router", router, "--module", "example.com/shop")
+			h.AssertNoError(err)
+
+			// The generated handler is constructed with a ProductService
+			h.CreateFile("shop/internal/services/product.go", `package services
+
+type ProductService interface{}
+
+func NewProductService() ProductService { return nil }
+`)
+
+			project := filepath.Join(h.GetTempDir(), "shop")
+			output, err := h.RunFoundryInDir(project, "add", "handler", "product", "--auto-wire")
+			h.AssertNoError(err)
+			h.AssertFileContains("shop/internal/routes/routes.go", "handlers.NewProductHandler(services.NewProductService())")
+
+			output, err = h.RunFoundryInDir(project, "openapi", "export", "-f", "openapi.yaml")
+			h.AssertNoError(err)
+			h.AssertOutputContains(output, "Exported 2 path(s)")
+
+			spec := h.ReadFile("shop/openapi.yaml")
+			for _, want := range []string{
+				"  /products:\n    get:",
+				"  /products/{id}:\n    get:",
+				"operationId: createProduct",
+				"operationId: deleteProduct",
+				"in: path",
+				"type: integer",
+				`"201":`,
+			} {
+				if !strings.Contains(spec, want) {
+					t.Errorf("exported document for %s does not contain %q:\n%s", router, want, spec)
+				}
+			}
+		})
+	}
+}
+
 // TestFoundryAddDatabase tests adding database support
 func TestFoundryAddDatabase(t *testing.T) {
 	h := NewTestHelper(t)
@@ -212,3 +1019,874 @@ func TestFoundryAddDatabase(t *testing.T) {
 	h.AssertFileContains("internal/database/database.go", "NewConnection")
 	h.AssertFileContains(".env.example", "DB_HOST")
 }
+
+// middlewareStub declares the auth middleware wired by the wire tests
+const middlewareStub = `package middleware
+
+import "net/http"
+
+func AuthMiddleware(next http.Handler) http.Handler { return next }
+`
+
+// TestFoundryWire tests wiring handlers and middleware into an existing project
+func TestFoundryWire(t *testing.T) {
+	h := NewTestHelper(t)
+
+	h.CreateFile("go.mod", "module example.com/wired\n\ngo 1.21\n")
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Route("/api/v1",
+		func(api chi.Router) {
+			// Handler routes will be auto-generated here
+		})
+}
+`)
+	h.CreateFile("main.go", `package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func main() {
+	r := chi.NewRouter() // router
+	http.ListenAndServe(":8080", r)
+}
+`)
+	h.CreateFile("internal/handlers/user.go", `package handlers
+
+type UserHandler struct{}
+
+func NewUserHandler() *UserHandler { return &UserHandler{} }
+`)
+
+	// Dry run must not touch the file
+	output, err := h.RunFoundry("wire", "handler", "user", "--dry-run")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Dry run complete")
+	h.AssertOutputContains(output, `api.Mount("/users", userHandler.Routes())`)
+
+	output, err = h.RunFoundry("wire", "handler", "user")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Handler user wired successfully")
+	h.AssertFileContains("internal/routes/routes.go", `"example.com/wired/internal/handlers"`)
+	h.AssertFileContains("internal/routes/routes.go", "userHandler := handlers.NewUserHandler()")
+	h.AssertFileContains("internal/routes/routes.go", `api.Mount("/users", userHandler.Routes())`)
+
+	// Wiring twice is refused unless forced
+	output, err = h.RunFoundry("wire", "handler", "user")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "already wired")
+	_, err = h.RunFoundry("wire", "handler", "user", "--force")
+	h.AssertNoError(err)
+
+	// Constructor parameters are built from the constructors of their types
+	h.CreateFile("internal/services/order.go", `package services
+
+type OrderService interface{}
+
+func NewOrderService(opts ...string) OrderService { return nil }
+`)
+	h.CreateFile("internal/handlers/order.go", `package handlers
+
+import appservices "example.com/wired/internal/services"
+
+type OrderHandler struct{}
+
+func NewOrderHandler(orders appservices.OrderService) *OrderHandler { return &OrderHandler{} }
+`)
+	_, err = h.RunFoundry("wire", "handler", "order")
+	h.AssertNoError(err)
+	h.AssertFileContains("internal/routes/routes.go", `"example.com/wired/internal/services"`)
+	h.AssertFileContains("internal/routes/routes.go", "orderHandler := handlers.NewOrderHandler(services.NewOrderService())")
+
+	// Handlers that do not exist or cannot be constructed are not wired
+	routesFile := h.ReadFile("internal/routes/routes.go")
+	output, err = h.RunFoundry("wire", "handler", "ghost")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "handler ghost not found: no NewGhostHandler function in internal/handlers")
+
+	h.CreateFile("internal/handlers/audit.go", `package handlers
+
+import "database/sql"
+
+type AuditHandler struct{}
+
+func NewAuditHandler(db *sql.DB) *AuditHandler { return &AuditHandler{} }
+`)
+	output, err = h.RunFoundry("wire", "handler", "audit")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "cannot build a *sql.DB argument")
+	if h.ReadFile("internal/routes/routes.go") != routesFile {
+		t.Error("routes.go changed by a failed wiring")
+	}
+
+	// Middleware the project does not declare is not wired
+	mainFile := h.ReadFile("main.go")
+	for _, missing := range []string{"bogus", "cors"} {
+		output, err = h.RunFoundry("wire", "middleware", missing)
+		h.AssertError(err, "")
+		h.AssertOutputContains(output, "middleware "+missing+" not found")
+	}
+	if h.ReadFile("main.go") != mainFile {
+		t.Error("main.go changed by a failed wiring")
+	}
+
+	h.CreateFile("internal/middleware/auth.go", middlewareStub)
+	output, err = h.RunFoundry("wire", "middleware", "auth")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Middleware auth wired successfully")
+	h.AssertFileContains("main.go", `"example.com/wired/internal/middleware"`)
+	h.AssertFileContains("main.go", "r.Use(middleware.AuthMiddleware)")
+	h.AssertFileContains("main.go", "r := chi.NewRouter() // router")
+}
+
+// TestFoundryWireSingleWordModule tests that imports of a module whose path
+// has no dot are grouped with the other non-standard imports
+func TestFoundryWireSingleWordModule(t *testing.T) {
+	h := NewTestHelper(t)
+
+	h.CreateFile("go.mod", "module api\n\ngo 1.21\n")
+	h.CreateFile("internal/handlers/user.go", `package handlers
+
+type UserHandler struct{}
+
+func NewUserHandler() *UserHandler { return &UserHandler{} }
+`)
+	h.CreateFile("internal/routes/routes.go", `package routes
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterAPIRoutes registers all API v1 routes
+func RegisterAPIRoutes(r chi.Router) {
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+	r.Route("/api/v1", func(api chi.Router) {
+	})
+}
+`)
+
+	_, err := h.RunFoundry("wire", "handler", "user")
+	h.AssertNoError(err)
+	h.AssertFileContains("internal/routes/routes.go", "import (\n\t\"net/http\"\n\n\t\"api/internal/handlers\"\n\t\"github.com/go-chi/chi/v5\"\n)")
+}
+
+// TestFoundryWirePreview tests the unified diffs printed when wiring
+func TestFoundryWirePreview(t *testing.T) {
+	h := NewTestHelper(t)
+
+	h.CreateFile("go.mod", "module example.com/wired\n\ngo 1.21\n")
+	h.CreateFile("main.go", `package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func main() {
+	r := chi.NewRouter()
+	http.ListenAndServe(":8080", r)
+}
+`)
+
+	h.CreateFile("internal/middleware/auth.go", middlewareStub)
+
+	output, err := h.RunFoundry("wire", "middleware", "auth", "--dry-run")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "--- a/main.go\n+++ b/main.go\n@@ ")
+	h.AssertOutputContains(output, "+\t\"example.com/wired/internal/middleware\"")
+	h.AssertOutputContains(output, " \tr := chi.NewRouter()\n+\tr.Use(middleware.AuthMiddleware)\n \thttp.ListenAndServe")
+	if strings.Contains(output, "\033[") {
+		t.Errorf("diff printed to a pipe is colored:\n%q", output)
+	}
+
+	// --diff-context sets the unchanged lines around each change
+	output, err = h.RunFoundry("wire", "middleware", "auth", "--dry-run", "--diff-context", "0")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "+\tr.Use(middleware.AuthMiddleware)\n")
+	if strings.Contains(output, " \tr := chi.NewRouter()") {
+		t.Errorf("diff with --diff-context 0 has context lines:\n%s", output)
+	}
+
+	// The diff shown is the change that is written
+	output, err = h.RunFoundry("wire", "middleware", "auth", "--no-color")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "+\tr.Use(middleware.AuthMiddleware)")
+	h.AssertFileContains("main.go", "r.Use(middleware.AuthMiddleware)")
+}
+
+// TestFoundryOnConflict tests the --on-conflict policies for generated files
+// that already exist
+func TestFoundryOnConflict(t *testing.T) {
+	h := NewTestHelper(t)
+
+	h.CreateFile("README.md", "# kept by hand\n")
+
+	// A non-empty directory needs a policy
+	_, err := h.RunFoundry("init", "conflicts", "--interactive=false", "--no-git")
+	h.AssertError(err, "")
+
+	output, err := h.RunFoundry("init", "conflicts", "--interactive=false", "--no-git", "--on-conflict=skip")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "= README.md (skipped)")
+	h.AssertFileContains("README.md", "# kept by hand")
+	h.AssertFileExists("go.mod")
+
+	_, err = h.RunFoundry("add", "model", "product")
+	h.AssertNoError(err)
+	content := h.ReadFile("internal/models/product.go")
+	h.CreateFile("internal/models/product.go", "// Edited by hand\n"+content)
+
+	// fail is the default and leaves the file alone
+	output, err = h.RunFoundry("add", "model", "product")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "--on-conflict")
+	h.AssertFileContains("internal/models/product.go", "// Edited by hand")
+
+	_, err = h.RunFoundry("add", "model", "product", "--on-conflict=unknown")
+	h.AssertError(err, "")
+
+	// merge keeps the edit, since the generated output is recorded
+	output, err = h.RunFoundry("add", "model", "product", "--on-conflict=merge")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "~ internal/models/product.go (merged)")
+	h.AssertFileContains("internal/models/product.go", "// Edited by hand")
+
+	// prompt shows the diff and reads the answer
+	output, err = h.RunFoundryWithInput("overwrite\n", "add", "model", "product", "--on-conflict=prompt", "--no-color")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "-// Edited by hand")
+	h.AssertOutputContains(output, "~ internal/models/product.go (overwritten)")
+	if h.ReadFile("internal/models/product.go") != content {
+		t.Errorf("product.go was not overwritten with the generated model")
+	}
+}
+
+// TestFoundryUndo tests undoing operations recorded in the journal
+func TestFoundryUndo(t *testing.T) {
+	h := NewTestHelper(t)
+
+	_, err := h.RunFoundry("undo")
+	h.AssertError(err, "")
+
+	_, err = h.RunFoundry("init", "journaled", "--interactive=false", "--no-git")
+	h.AssertNoError(err)
+	lock := h.ReadFile(".foundry/lock.yaml")
+
+	_, err = h.RunFoundry("add", "model", "product")
+	h.AssertNoError(err)
+	h.AssertFileExists("internal/models/product.go")
+
+	output, err := h.RunFoundry("history", "--files")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "#2")
+	h.AssertOutputContains(output, "foundry add model product")
+	h.AssertOutputContains(output, "created  internal/models/product.go")
+	h.AssertOutputContains(output, "foundry init journaled")
+
+	// Files edited since the operation are not reverted without --force
+	h.CreateFile("internal/models/product.go", "package models\n")
+	output, err = h.RunFoundry("undo")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "--force")
+	h.AssertFileExists("internal/models/product.go")
+
+	output, err = h.RunFoundry("undo", "--force")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Undid #2: foundry add model product")
+	h.AssertFileNotExists("internal/models/product.go")
+	if h.ReadFile(".foundry/lock.yaml") != lock {
+		t.Errorf("undo did not restore the lock file")
+	}
+
+	// Files a command overwrites are recorded however large they are
+	large := "package models\n\n" + strings.Repeat("// padding to make the model larger than a mebibyte\n", 25000)
+	h.CreateFile("internal/models/product.go", large)
+	_, err = h.RunFoundry("add", "model", "product", "--on-conflict=overwrite")
+	h.AssertNoError(err)
+	if h.ReadFile("internal/models/product.go") == large {
+		t.Fatalf("add model did not overwrite the model")
+	}
+	_, err = h.RunFoundry("undo")
+	h.AssertNoError(err)
+	if h.ReadFile("internal/models/product.go") != large {
+		t.Errorf("undo did not restore the large model")
+	}
+	h.AssertNoError(os.Remove(filepath.Join(h.GetTempDir(), "internal/models/product.go")))
+
+	// Undoing the init removes the project files again
+	_, err = h.RunFoundry("undo")
+	h.AssertNoError(err)
+	h.AssertFileNotExists("go.mod")
+	h.AssertFileNotExists("foundry.yaml")
+
+	output, err = h.RunFoundry("history")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "No operations recorded")
+}
+
+// TestFoundryOutputJSON tests the structured documents printed with --output
+func TestFoundryOutputJSON(t *testing.T) {
+	h := NewTestHelper(t)
+
+	type report struct {
+		Command      string   `json:"command"`
+		Success      bool     `json:"success"`
+		FilesCreated []string `json:"files_created"`
+		FilesUpdated []string `json:"files_updated"`
+		Decisions    []struct {
+			Path   string `json:"path"`
+			Action string `json:"action"`
+		} `json:"decisions"`
+		Errors []struct {
+			Code        string   `json:"code"`
+			Suggestions []string `json:"suggestions"`
+		} `json:"errors"`
+		Data json.RawMessage `json:"data"`
+	}
+	decode := func(output string) report {
+		t.Helper()
+		var r report
+		if err := json.Unmarshal([]byte(output), &r); err != nil {
+			t.Fatalf("stdout is not one JSON document: %v\n%s", err, output)
+		}
+		return r
+	}
+	contains := func(list []string, want string) bool {
+		for _, item := range list {
+			if item == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	output, err := h.RunFoundryStdout("init", "reported", "--no-git", "--output", "json")
+	h.AssertNoError(err)
+	r := decode(output)
+	if !r.Success || !contains(r.FilesCreated, "go.mod") {
+		t.Errorf("init report = %+v, want success with go.mod created", r)
+	}
+	for _, file := range append(r.FilesCreated, r.FilesUpdated...) {
+		if strings.HasPrefix(file, ".foundry/") {
+			t.Errorf("init report lists the foundry metadata %s as output", file)
+		}
+	}
+
+	output, err = h.RunFoundryStdout("add", "model", "product", "--output", "json")
+	h.AssertNoError(err)
+	r = decode(output)
+	if !contains(r.FilesCreated, "internal/models/product.go") || contains(r.FilesUpdated, ".foundry/lock.yaml") {
+		t.Errorf("add model report = %+v", r)
+	}
+
+	// What was done with existing files is reported
+	h.CreateFile("internal/models/product.go", "package models\n")
+	output, err = h.RunFoundryStdout("add", "model", "product", "--on-conflict=overwrite", "--output", "json")
+	h.AssertNoError(err)
+	r = decode(output)
+	if len(r.Decisions) != 1 || r.Decisions[0].Path != "internal/models/product.go" || r.Decisions[0].Action != "overwritten" {
+		t.Errorf("overwriting add model report = %+v", r)
+	}
+
+	// Failures are documents too, with codes and suggestions
+	output, err = h.RunFoundryStdout("add", "model", "product", "--output", "json")
+	h.AssertError(err, "")
+	r = decode(output)
+	if r.Success || len(r.Errors) != 1 || r.Errors[0].Code != "NAME_EXISTS" || !contains(r.Errors[0].Suggestions, "--on-conflict=merge") {
+		t.Errorf("conflicting add model report = %+v", r)
+	}
+
+	output, err = h.RunFoundryStdout("add", "model", "--output", "json")
+	h.AssertError(err, "")
+	if r = decode(output); len(r.Errors) != 1 || r.Errors[0].Code != "ARGS_INVALID" {
+		t.Errorf("add model without a name report = %+v", r)
+	}
+
+	// The journal and drift commands report too
+	output, err = h.RunFoundryStdout("history", "--output", "json")
+	h.AssertNoError(err)
+	var operations []struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal(decode(output).Data, &operations); err != nil || len(operations) == 0 ||
+		operations[0].Command != "foundry add model product --on-conflict=overwrite" {
+		t.Errorf("history data = %s (%v)", decode(output).Data, err)
+	}
+
+	output, err = h.RunFoundryStdout("undo", "--output", "json")
+	h.AssertNoError(err)
+	if r = decode(output); !r.Success || !contains(r.FilesUpdated, "internal/models/product.go") {
+		t.Errorf("undo report = %+v", r)
+	}
+
+	output, err = h.RunFoundryStdout("diff", "--output", "json")
+	h.AssertNoError(err)
+	var drift []struct {
+		Path   string `json:"path"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(decode(output).Data, &drift); err != nil || len(drift) == 0 || drift[0].Status == "" {
+		t.Errorf("diff data = %s (%v)", decode(output).Data, err)
+	}
+
+	// openapi export writes its document to --file, not to a file named
+	// after the output format
+	output, err = h.RunFoundryStdout("openapi", "export", "--output", "json")
+	h.AssertError(err, "")
+	if r = decode(output); r.Success || len(r.Errors) != 1 {
+		t.Errorf("openapi export without --file report = %+v", r)
+	}
+	output, err = h.RunFoundryStdout("openapi", "export", "--output", "json", "--file", "openapi.yaml")
+	h.AssertNoError(err)
+	if r = decode(output); !contains(r.FilesCreated, "openapi.yaml") {
+		t.Errorf("openapi export report = %+v", r)
+	}
+	h.AssertFileExists("openapi.yaml")
+	h.AssertFileNotExists("json")
+
+	output, err = h.RunFoundryStdout("layout", "list", "--output", "json")
+	h.AssertNoError(err)
+	var layouts []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(decode(output).Data, &layouts); err != nil || len(layouts) == 0 {
+		t.Errorf("layout list data = %s (%v)", decode(output).Data, err)
+	}
+
+	output, err = h.RunFoundryStdout("layout", "info", "standard", "--output", "yaml")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "command: foundry layout info standard")
+	h.AssertOutputContains(output, "data:\n")
+
+	_, err = h.RunFoundry("add", "model", "order", "--output", "xml")
+	h.AssertError(err, "")
+}
+
+// TestFoundryLogging tests the verbosity, log format and plain output flags
+func TestFoundryLogging(t *testing.T) {
+	h := NewTestHelper(t)
+
+	output, err := h.RunFoundry("init", "logged", "--no-git", "-vv")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "[debug] loaded layout layout=standard")
+	h.AssertOutputContains(output, "[trace] rendered file path=go.mod")
+
+	output, err = h.RunFoundry("add", "model", "product", "--quiet")
+	h.AssertNoError(err)
+	if strings.Contains(output, "[debug]") {
+		t.Errorf("--quiet logged debug details:\n%s", output)
+	}
+	if strings.TrimSpace(output) != "" {
+		t.Errorf("--quiet printed messages:\n%s", output)
+	}
+
+	// Listings are still printed with --quiet
+	output, err = h.RunFoundryStdout("history", "--quiet")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "foundry add model product")
+
+	// JSON logs are one object per line, next to the messages on stdout
+	output, err = h.RunFoundry("add", "model", "order", "--log-format=json", "-v")
+	h.AssertNoError(err)
+	var record struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &record) == nil && record.Msg == "loaded layout" {
+			found = record.Level == "DEBUG"
+		}
+	}
+	if !found {
+		t.Errorf("no DEBUG record for the loaded layout in JSON logs:\n%s", output)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "{") {
+			t.Errorf("JSON logs printed a message that is not a record: %q", line)
+		}
+	}
+
+	output, err = h.RunFoundry("add", "model", "invoice", "--no-emoji")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Model created successfully!")
+	if strings.ContainsAny(output, "✅📁🔨") {
+		t.Errorf("--no-emoji printed emoji:\n%s", output)
+	}
+
+	_, err = h.RunFoundry("add", "model", "customer", "-v", "--quiet")
+	h.AssertError(err, "")
+
+	_, err = h.RunFoundry("version", "--log-format=xml")
+	h.AssertError(err, "")
+}
+
+// TestFoundryLayoutValidate tests linting a layout directory
+func TestFoundryLayoutValidate(t *testing.T) {
+	h := NewTestHelper(t)
+
+	manifest := `name: "custom"
+version: "1.0.0"
+variables:
+  - name: "port"
+    default: "8080"
+structure:
+  files:
+    - template: "project/main.go.tmpl"
+      target: "main.go"
+components:
+  handler:
+    template: "components/handler.go.tmpl"
+    target_dir: "internal/handlers"
+`
+	h.CreateFile("good/layout.manifest.yaml", manifest)
+	h.CreateFile("good/project/main.go.tmpl", "package main\n\n// {{.ProjectName}} listens on {{.CustomVariables.port}}\nfunc main() {}\n")
+	h.CreateFile("good/components/handler.go.tmpl", "package {{.PackageName}}\n\ntype {{.ComponentName | pascal}}Handler struct{}\n")
+
+	output, err := h.RunFoundry("layout", "validate", "good")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Layout is valid")
+
+	h.CreateFile("bad/layout.manifest.yaml", strings.Replace(manifest, "components/handler.go.tmpl", "components/service.go.tmpl", 1))
+	h.CreateFile("bad/project/main.go.tmpl", "package main\n\n// {{.ProjectNam}} on {{.CustomVariables.host}}\n{{if .ProjectName}}\n")
+	h.CreateFile("bad/project/notes.txt.tmpl", "notes\n")
+
+	output, err = h.RunFoundry("layout", "validate", "bad")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "bad/layout.manifest.yaml:12: error: template components/service.go.tmpl of component handler not found")
+	h.AssertOutputContains(output, "bad/project/main.go.tmpl:5: error: template: project/main.go.tmpl:5: unexpected EOF")
+	h.AssertOutputContains(output, "bad/project/notes.txt.tmpl: warning: template is not used")
+
+	h.CreateFile("bad/project/main.go.tmpl", "package main\n\n// {{.ProjectNam}} on {{.CustomVariables.host}}\n")
+	output, err = h.RunFoundry("layout", "validate", "bad")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "bad/project/main.go.tmpl:3: error: unknown field .ProjectNam")
+	h.AssertOutputContains(output, "bad/project/main.go.tmpl:3: error: unknown variable .CustomVariables.host")
+
+	// Warnings alone pass unless --strict
+	h.CreateFile("good/project/notes.txt.tmpl", "notes\n")
+	_, err = h.RunFoundry("layout", "validate", "good")
+	h.AssertNoError(err)
+	_, err = h.RunFoundry("layout", "validate", "good", "--strict")
+	h.AssertError(err, "")
+
+	stdout, err := h.RunFoundryStdout("layout", "validate", "bad", "--output", "json")
+	h.AssertError(err, "")
+	var report struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+			Severity string `json:"severity"`
+			Code     string `json:"code"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, stdout)
+	}
+	if report.Success || len(report.Data) != 4 {
+		t.Fatalf("expected a failed report with 4 problems, got %+v", report)
+	}
+	if problem := report.Data[0]; problem.File != "bad/layout.manifest.yaml" || problem.Line != 12 || problem.Code != "TEMPLATE_MISSING" {
+		t.Errorf("unexpected first problem: %+v", problem)
+	}
+}
+
+// TestFoundryLayoutTest tests the golden snapshots of a layout
+func TestFoundryLayoutTest(t *testing.T) {
+	h := NewTestHelper(t)
+
+	h.CreateFile("custom/layout.manifest.yaml", `name: "custom"
+version: "1.0.0"
+structure:
+  files:
+    - template: "project/main.go.tmpl"
+      target: "cmd/{{.ProjectName}}/main.go"
+    - template: "project/README.md.tmpl"
+      target: "README.md"
+`)
+	h.CreateFile("custom/project/main.go.tmpl", "package main\n\n// {{.ProjectName}} was started in {{.Year}}\nfunc main() {}\n")
+	h.CreateFile("custom/project/README.md.tmpl", "# {{.ProjectName}}\n")
+	h.CreateFile("custom/testdata/basic/foundry.answers.yaml", "layout: custom\nproject_name: demo\nmodule: github.com/acme/demo\nyear: 2024\n")
+
+	// Without golden files every generated file is unexpected
+	_, err := h.RunFoundry("layout", "test", "custom")
+	h.AssertError(err, "")
+
+	output, err := h.RunFoundry("layout", "test", "custom", "--update")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "golden project updated")
+	h.AssertFileContains("custom/testdata/basic/golden/cmd/demo/main.go", "// demo was started in 2024")
+	h.AssertFileContains("custom/testdata/basic/golden/README.md", "# demo")
+
+	output, err = h.RunFoundry("layout", "test", "custom")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "1 case(s) passed")
+
+	h.CreateFile("custom/project/README.md.tmpl", "# {{.ProjectName}} service\n")
+	output, err = h.RunFoundry("layout", "test", "custom", "--no-color")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "~ README.md: differs from the golden file")
+	h.AssertOutputContains(output, "-# demo\n+# demo service")
+
+	// Invalid Go fails the run and is never written as golden
+	h.CreateFile("custom/project/main.go.tmpl", "package main\n\nfunc main() {\n")
+	output, err = h.RunFoundry("layout", "test", "custom", "--update")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "! cmd/demo/main.go:")
+	h.AssertFileContains("custom/testdata/basic/golden/cmd/demo/main.go", "func main() {}")
+	h.AssertFileContains("custom/testdata/basic/golden/README.md", "# demo\n")
+
+	stdout, err := h.RunFoundryStdout("layout", "test", "custom", "--output", "json")
+	h.AssertError(err, "")
+	var report struct {
+		Data []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Mismatches []struct {
+				Path string `json:"path"`
+				Kind string `json:"kind"`
+			} `json:"mismatches"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, stdout)
+	}
+	if len(report.Data) != 1 || report.Data[0].Status != "failed" || len(report.Data[0].Mismatches) != 3 {
+		t.Fatalf("expected one failed case with 3 mismatches, got %+v", report.Data)
+	}
+}
+
+// TestFoundryLayoutAuthoring tests creating layouts with layout init and
+// layout extract
+func TestFoundryLayoutAuthoring(t *testing.T) {
+	h := NewTestHelper(t)
+
+	output, err := h.RunFoundry("layout", "init", "skeleton")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Created layout skeleton in skeleton")
+	h.AssertFileContains("skeleton/layout.manifest.yaml", `name: "skeleton"`)
+	h.AssertFileContains("skeleton/testdata/basic/foundry.answers.yaml", "layout: skeleton")
+
+	output, err = h.RunFoundry("layout", "validate", "skeleton")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Layout is valid")
+	_, err = h.RunFoundry("layout", "test", "skeleton", "--update")
+	h.AssertNoError(err)
+
+	_, err = h.RunFoundry("layout", "init", "skeleton")
+	h.AssertError(err, "")
+
+	h.CreateFile("svc/go.mod", "module github.com/acme/orders\n\ngo 1.22\n")
+	h.CreateFile("svc/cmd/orders-server/main.go", "package main\n\nimport \"github.com/acme/orders/internal/handlers\"\n\n// orders-server serves the orders API\nfunc main() { handlers.NewUserHandler() }\n")
+	h.CreateFile("svc/internal/handlers/user.go", "package handlers\n\n// UserHandler handles users\ntype UserHandler struct{}\n\n// NewUserHandler creates a UserHandler\nfunc NewUserHandler() *UserHandler { return &UserHandler{} }\n")
+	h.CreateFile("svc/web/index.html", "<h1>{{.Title}}</h1>\n")
+
+	output, err = h.RunFoundry("layout", "extract", "svc", "--name", "company-std")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "project name orders → {{.ProjectName}}")
+	h.AssertOutputContains(output, "component handler ← internal/handlers/user.go")
+
+	h.AssertFileContains("company-std/project/cmd/orders-server/main.go.tmpl", `import "{{.ModuleName}}/internal/handlers"`)
+	h.AssertFileContains("company-std/project/cmd/orders-server/main.go.tmpl", "// {{.CustomVariables.binary_name}} serves the {{.ProjectName}} API")
+	h.AssertFileContains("company-std/project/web/index.html.tmpl", "<h1>{{`{{`}}.Title{{`}}`}}</h1>")
+	h.AssertFileContains("company-std/components/handler.go.tmpl", "type {{.ComponentName | pascal}}Handler struct{}")
+	h.AssertFileContains("company-std/layout.manifest.yaml", "target: cmd/{{.CustomVariables.binary_name}}/main.go")
+	h.AssertFileContains("company-std/layout.manifest.yaml", "target_dir: internal/handlers")
+
+	_, err = h.RunFoundry("layout", "validate", "company-std")
+	h.AssertNoError(err)
+
+	// The extracted layout generates the project it was extracted from
+	_, err = h.RunFoundry("layout", "test", "company-std", "--update")
+	h.AssertNoError(err)
+	for _, path := range []string{"cmd/orders-server/main.go", "internal/handlers/user.go", "web/index.html", "go.mod"} {
+		want := h.ReadFile("svc/" + path)
+		if got := h.ReadFile("company-std/testdata/basic/golden/" + path); got != want {
+			t.Errorf("%s generated by the extracted layout differs:\n%s\nwant:\n%s", path, got, want)
+		}
+	}
+
+	_, err = h.RunFoundry("layout", "extract", "svc")
+	h.AssertError(err, "")
+
+	// A single-word module path is only replaced in import paths
+	h.CreateFile("api/go.mod", "module api\n\ngo 1.22\n")
+	h.CreateFile("api/go.sum", "github.com/go-chi/chi/v5 v5.0.12 h1:9euLV5sTrTNTRUU9POmDUvfxyj6LAABLUcEWO+JJb4s=\n")
+	h.CreateFile("api/internal/routes/routes.go", "package routes\n\nimport \"api/internal/handlers\"\n\ntype router interface{ Route(string, func()) }\n\n// Register mounts the api routes\nfunc Register(r router) {\n\tr.Route(\"/api/v1\", func() { handlers.NewUserHandler() })\n}\n")
+	h.CreateFile("api/internal/handlers/user.go", "package handlers\n\n// UserHandler handles users\ntype UserHandler struct{}\n\n// NewUserHandler creates a UserHandler\nfunc NewUserHandler() *UserHandler { return &UserHandler{} }\n")
+
+	_, err = h.RunFoundry("layout", "extract", "api", "--name", "api-std")
+	h.AssertNoError(err)
+	h.AssertFileContains("api-std/project/go.mod.tmpl", "module {{.ModuleName}}\n")
+	h.AssertFileContains("api-std/project/internal/routes/routes.go.tmpl", `import "{{.ModuleName}}/internal/handlers"`)
+	h.AssertFileContains("api-std/project/internal/routes/routes.go.tmpl", `r.Route("/api/v1", func()`)
+	h.AssertFileContains("api-std/project/internal/routes/routes.go.tmpl", "// Register mounts the {{.ProjectName}} routes")
+	h.AssertFileNotExists("api-std/project/go.sum.tmpl")
+
+	_, err = h.RunFoundry("layout", "test", "api-std", "--update")
+	h.AssertNoError(err)
+	for _, path := range []string{"internal/routes/routes.go", "go.mod"} {
+		want := h.ReadFile("api/" + path)
+		if got := h.ReadFile("api-std/testdata/basic/golden/" + path); got != want {
+			t.Errorf("%s generated by the extracted layout differs:\n%s\nwant:\n%s", path, got, want)
+		}
+	}
+}
+
+// TestFoundryLayoutPublish tests packing layouts and publishing them to a
+// registry directory
+func TestFoundryLayoutPublish(t *testing.T) {
+	h := NewTestHelper(t)
+
+	_, err := h.RunFoundry("layout", "init", "company-std")
+	h.AssertNoError(err)
+
+	output, err := h.RunFoundry("layout", "pack", "company-std", "--dir", "dist")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Packed company-std 0.1.0")
+	h.AssertFileExists("dist/company-std-0.1.0.tar.gz")
+	first := h.ReadFile("dist/company-std-0.1.0.tar.gz")
+	h.AssertFileContains("dist/company-std-0.1.0.tar.gz.sha256", "  company-std-0.1.0.tar.gz")
+
+	// Packing again gives the same archive
+	_, err = h.RunFoundry("layout", "pack", "company-std", "--dir", "again")
+	h.AssertNoError(err)
+	if h.ReadFile("again/company-std-0.1.0.tar.gz") != first {
+		t.Error("packing the same layout twice gave different archives")
+	}
+
+	output, err = h.RunFoundry("layout", "publish", "company-std", "--registry", "registry")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Published company-std 0.1.0")
+	h.AssertFileExists("registry/company-std/company-std-0.1.0.tar.gz")
+
+	var index struct {
+		Version string `json:"version"`
+		Layouts []struct {
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			Archive  string `json:"archive"`
+			Checksum string `json:"checksum"`
+		} `json:"layouts"`
+	}
+	if err := json.Unmarshal([]byte(h.ReadFile("registry/index.json")), &index); err != nil {
+		t.Fatalf("invalid registry index: %v", err)
+	}
+	if len(index.Layouts) != 1 || index.Layouts[0].Archive != "company-std/company-std-0.1.0.tar.gz" {
+		t.Fatalf("unexpected registry index: %+v", index)
+	}
+	checksum := h.ReadFile("dist/company-std-0.1.0.tar.gz.sha256")
+	if !strings.HasPrefix(checksum, index.Layouts[0].Checksum) {
+		t.Errorf("index checksum %s does not match the packed archive: %s", index.Layouts[0].Checksum, checksum)
+	}
+
+	// file:// registries are directories too, and the same contents are
+	// published once
+	output, err = h.RunFoundry("layout", "publish", "company-std", "--registry", "file://"+filepath.Join(h.GetTempDir(), "registry"))
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "already published")
+
+	h.CreateFile("company-std/README.md", "# Company layout\n")
+	_, err = h.RunFoundry("layout", "publish", "company-std", "--registry", "registry")
+	h.AssertError(err, "")
+	_, err = h.RunFoundry("layout", "publish", "company-std", "--registry", "registry", "--force")
+	h.AssertNoError(err)
+
+	h.CreateFile("company-std/project/main.go.tmpl", "package main\n\n{{.Missing}}\n")
+	output, err = h.RunFoundry("layout", "pack", "company-std")
+	h.AssertError(err, "")
+	h.AssertOutputContains(output, "foundry layout validate")
+}
+
+// TestFoundryLayoutSearch tests searching registries and adding layouts by
+// name, with a registry that is a directory and one that cannot be reached
+func TestFoundryLayoutSearch(t *testing.T) {
+	h := NewTestHelper(t)
+	home := h.GetTempDir()
+	t.Setenv("HOME", home)
+
+	for name, tags := range map[string]string{"company-grpc": "[grpc, service]", "company-web": "[web]"} {
+		_, err := h.RunFoundry("layout", "init", name)
+		h.AssertNoError(err)
+		manifest := strings.Replace(h.ReadFile(name+"/layout.manifest.yaml"), "tags: []", "tags: "+tags, 1)
+		manifest = strings.Replace(manifest, "The company-web project layout",
+			"Páginas y formularios para aplicaciones web: ámbito y más", 1)
+		h.CreateFile(name+"/layout.manifest.yaml", manifest)
+		_, err = h.RunFoundry("layout", "publish", name, "--registry", "registry")
+		h.AssertNoError(err)
+	}
+
+	h.CreateFile(".foundry/layouts.yaml", fmt.Sprintf(`version: "1.0"
+registries:
+  company:
+    url: file://%s
+    trusted: true
+  offline:
+    url: http://127.0.0.1:1/layouts
+cache:
+  directory: %s
+security:
+  verify_checksums: true
+`, filepath.Join(home, "registry"), filepath.Join(home, "cache")))
+
+	output, err := h.RunFoundry("layout", "search", "company")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "No layouts found")
+
+	// A registry that cannot be reached does not stop the others
+	output, err = h.RunFoundry("layout", "update")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "company: 2 layout version(s)")
+	h.AssertOutputContains(output, "offline")
+
+	output, err = h.RunFoundry("layout", "search", "--tag", "GRPC")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "company-grpc")
+	if strings.Contains(output, "company-web") {
+		t.Errorf("search by tag found a layout without it:\n%s", output)
+	}
+
+	// Long descriptions are cut between characters, not bytes
+	output, err = h.RunFoundry("layout", "search", "web")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Páginas y formularios para aplicaciones web: ám...")
+
+	stdout, err := h.RunFoundryStdout("layout", "search", "web", "--output", "json")
+	h.AssertNoError(err)
+	var report struct {
+		Data []struct {
+			Registry string   `json:"registry"`
+			Name     string   `json:"name"`
+			Tags     []string `json:"tags"`
+			Archive  string   `json:"archive"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON report: %v\n%s", err, stdout)
+	}
+	if len(report.Data) != 1 || report.Data[0].Name != "company-web" || report.Data[0].Registry != "company" {
+		t.Fatalf("unexpected search results: %+v", report.Data)
+	}
+
+	output, err = h.RunFoundry("layout", "add", "company-grpc@0.x")
+	h.AssertNoError(err)
+	h.AssertOutputContains(output, "Added layout company-grpc 0.1.0 from registry company")
+
+	_, err = h.RunFoundry("new", "demo", "--layout", "company-grpc", "--module", "example.com/demo")
+	h.AssertNoError(err)
+	h.AssertFileContains("demo/go.mod", "module example.com/demo")
+
+	_, err = h.RunFoundry("layout", "add", "company-grpc@2.x")
+	h.AssertError(err, "")
+}
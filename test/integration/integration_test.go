@@ -73,6 +73,29 @@ func (h *TestHelper) RunFoundryInDir(dir string, args ...string) (string, error)
 	return string(output), err
 }
 
+// RunFoundryWithInput executes foundry command with the given stdin
+func (h *TestHelper) RunFoundryWithInput(input string, args ...string) (string, error) {
+	h.t.Helper()
+
+	cmd := exec.Command(h.foundryPath, args...)
+	cmd.Dir = h.tempDir
+	cmd.Stdin = strings.NewReader(input)
+
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// RunFoundryStdout executes foundry command and returns only its stdout
+func (h *TestHelper) RunFoundryStdout(args ...string) (string, error) {
+	h.t.Helper()
+
+	cmd := exec.Command(h.foundryPath, args...)
+	cmd.Dir = h.tempDir
+
+	output, err := cmd.Output()
+	return string(output), err
+}
+
 // AssertFileExists checks if a file exists
 func (h *TestHelper) AssertFileExists(path string) {
 	h.t.Helper()
@@ -99,6 +122,17 @@ func (h *TestHelper) AssertFileContains(path, content string) {
 	}
 }
 
+// ReadFile returns the content of a file in the temp directory
+func (h *TestHelper) ReadFile(path string) string {
+	h.t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(h.tempDir, path))
+	if err != nil {
+		h.t.Fatalf("Failed to read file %s: %v", path, err)
+	}
+	return string(content)
+}
+
 // AssertFileNotExists checks if a file does not exist
 func (h *TestHelper) AssertFileNotExists(path string) {
 	h.t.Helper()
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -12,6 +13,7 @@ func TestNotImplementedFeatures(t *testing.T) {
 	tests := []struct {
 		name             string
 		args             []string
+		setup            func(h *TestHelper)
 		expectedError    string
 		shouldContain    []string
 		shouldNotContain []string
@@ -31,15 +33,27 @@ func TestNotImplementedFeatures(t *testing.T) {
 			},
 		},
 		{
-			name:          "layout update",
-			args:          []string{"layout", "update"},
-			expectedError: "not implemented",
+			// Registries are refreshed now; with none that can be read the
+			// update fails instead of reporting success
+			name: "layout update",
+			args: []string{"layout", "update"},
+			setup: func(h *TestHelper) {
+				h.CreateFile(".foundry/layouts.yaml", `version: "1.0"
+registries:
+  offline:
+    url: http://127.0.0.1:1/layouts
+cache:
+  directory: `+filepath.Join(h.GetTempDir(), "cache")+`
+`)
+			},
+			expectedError: "exit status 1",
 			shouldContain: []string{
-				"❌ Layout registry updates are not yet implemented",
-				"This feature will refresh remote layout sources",
+				"failed to update registry",
+				"none of the 1 registries could be read",
 			},
 			shouldNotContain: []string{
-				"updated successfully",
+				"not yet implemented",
+				"Updated layout registries",
 				"✅",
 			},
 		},
@@ -61,31 +75,15 @@ func TestNotImplementedFeatures(t *testing.T) {
 				"layout removal not yet implemented",
 			},
 		},
-		{
-			name:          "wire handler",
-			args:          []string{"wire", "handler", "test"},
-			expectedError: "", // Wire shows warning but doesn't error
-			shouldContain: []string{
-				"⚠️  Wire command is not yet fully implemented",
-				"This feature will automatically:",
-				"Update imports",
-				"Register handlers with routers",
-			},
-		},
-		{
-			name:          "wire middleware",
-			args:          []string{"wire", "middleware", "auth"},
-			expectedError: "", // Wire shows warning but doesn't error
-			shouldContain: []string{
-				"⚠️  Wire command is not yet fully implemented",
-				"Update dependency injection configurations",
-			},
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := NewTestHelper(t)
+			t.Setenv("HOME", h.GetTempDir())
+			if tt.setup != nil {
+				tt.setup(h)
+			}
 
 			output, err := h.RunFoundry(tt.args...)
 